@@ -1,9 +1,13 @@
 package benchmark
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,12 +17,16 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+type FrostDKGConfig struct {
+	n         int64
+	threshold int64
+}
+
+// parameters can be swept without editing code by setting environment variables
+// FROST_BENCH_N=200 FROST_BENCH_THRESHOLD=140 go test -benchmem -run=^$ -bench ^BenchmarkFrostDKG$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
 // go test -benchmem -run=^$ -bench ^BenchmarkFrostDKG$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
 func BenchmarkFrostDKG(b *testing.B) {
-	test_suite := []struct {
-		n         int64
-		threshold int64
-	}{
+	test_suite := []FrostDKGConfig{
 		{
 			n:         100,
 			threshold: 70,
@@ -45,6 +53,11 @@ func BenchmarkFrostDKG(b *testing.B) {
 		},
 	}
 
+	test_suite, err := FrostDKGConfigFromEnv(test_suite)
+	if err != nil {
+		b.Skipf("invalid benchmark configuration: %v", err)
+	}
+
 	for _, test := range test_suite {
 		test_name := fmt.Sprintf("frost-dkg-%d/%d", test.threshold, test.n)
 		b.Run(test_name, func(b *testing.B) {
@@ -53,6 +66,512 @@ func BenchmarkFrostDKG(b *testing.B) {
 	}
 }
 
+// read FROST_BENCH_N, FROST_BENCH_THRESHOLD and FROST_BENCH_N_KEYS
+// returns the defaults when none is set
+//
+// threshold defaults to 70% of n, as in the hardcoded configurations
+// in FROST, each participant holds exactly one key, thus n_keys must equal n
+func FrostDKGConfigFromEnv(defaults []FrostDKGConfig) ([]FrostDKGConfig, error) {
+	n_env, n_set := os.LookupEnv("FROST_BENCH_N")
+	threshold_env, threshold_set := os.LookupEnv("FROST_BENCH_THRESHOLD")
+	n_keys_env, n_keys_set := os.LookupEnv("FROST_BENCH_N_KEYS")
+	if !n_set && !threshold_set && !n_keys_set {
+		return defaults, nil
+	}
+	if !n_set {
+		return nil, fmt.Errorf("FROST_BENCH_N is required")
+	}
+
+	n, err := strconv.ParseInt(n_env, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("FROST_BENCH_N: %w", err)
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("FROST_BENCH_N: %d, must be at least 2", n)
+	}
+
+	threshold := n * 7 / 10
+	if threshold_set {
+		threshold, err = strconv.ParseInt(threshold_env, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("FROST_BENCH_THRESHOLD: %w", err)
+		}
+	}
+	// a quorum of threshold + 1 participants must exist
+	if threshold < 1 || threshold >= n {
+		return nil, fmt.Errorf("FROST_BENCH_THRESHOLD: %d, must be in [1, %d]", threshold, n-1)
+	}
+
+	if n_keys_set {
+		n_keys, err := strconv.ParseInt(n_keys_env, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("FROST_BENCH_N_KEYS: %w", err)
+		}
+		if n_keys != n {
+			return nil, fmt.Errorf("FROST_BENCH_N_KEYS: %d, must equal FROST_BENCH_N %d", n_keys, n)
+		}
+	}
+
+	return []FrostDKGConfig{{n: n, threshold: threshold}}, nil
+}
+
+// only the aggregation of partial signatures is measured
+// go test -benchmem -run=^$ -bench ^BenchmarkFrostAggregate$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkFrostAggregate(b *testing.B) {
+	test_suite := []struct {
+		n         int64
+		threshold int64
+	}{
+		{
+			n:         100,
+			threshold: 70,
+		},
+		{
+			n:         1000,
+			threshold: 700,
+		},
+	}
+
+	for _, test := range test_suite {
+		// setup is done once outside of the measured sub benchmark
+		suite := testhelper.TestSuite{}
+		suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+		message_hash := sha256.Sum256([]byte("frost aggregate benchmark"))
+		aggregator, partial_sigs := setupFrostPartialSignatures(&suite, test.n, test.threshold, message_hash)
+
+		// sanity check before measuring
+		sig := aggregator.AggregatePartialSignatures(0, partial_sigs)
+		assert.True(b, sig.Verify(message_hash[:], aggregator.Frost.GroupPublicKey), "signature verification failed")
+
+		test_name := fmt.Sprintf("frost-aggregate-%d/%d", test.threshold, test.n)
+		b.Run(test_name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				aggregator.AggregatePartialSignatures(0, partial_sigs)
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(b.Elapsed().Microseconds())/1000/float64(b.N), "ms/aggregate")
+		})
+	}
+}
+
+// serial verification of secret proofs, as in the DKG benchmark, against batch verification
+// the batch is a random linear combination checked with a single multi scalar multiplication
+// go test -benchmem -run=^$ -bench ^BenchmarkFrostVerifySecretProofs$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkFrostVerifySecretProofs(b *testing.B) {
+	suite := testhelper.TestSuite{}
+	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+	for _, n := range []int64{100, 1000} {
+		// the proofs only cover A_i0, the threshold does not change the verification work
+		threshold := int64(1)
+		participants := make([]*testhelper.FrostParticipant, n)
+		proofs := make(map[int64]*testhelper.SecretProof)
+		commitments := make(map[int64]*btcec.JacobianPoint)
+		for i := int64(0); i < n; i++ {
+			participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+			proofs[i+1] = &testhelper.SecretProof{
+				Signature: participants[i].CalculateSecretProofs([32]byte{}),
+			}
+			commitments[i+1] = new(btcec.JacobianPoint)
+			participants[i].PolynomialCommitments[i+1][0].AsJacobian(commitments[i+1])
+		}
+
+		b.Run(fmt.Sprintf("serial-%d", n), func(b *testing.B) {
+			b.ResetTimer()
+			for k := 0; k < b.N; k++ {
+				for i := int64(0); i < n; i++ {
+					participants[0].VerifySecretProofs([32]byte{}, proofs[i+1].Signature, i+1, participants[i].PolynomialCommitments[i+1][0])
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("batch-%d", n), func(b *testing.B) {
+			b.ResetTimer()
+			for k := 0; k < b.N; k++ {
+				ok, _ := participants[0].VerifyBatchSecretProofs(proofs, commitments)
+				assert.True(b, ok)
+			}
+		})
+	}
+}
+
+// Lagrange coefficients of all signers, inverting each denominator against a single batch inversion
+// go test -benchmem -run=^$ -bench ^BenchmarkLagrangeCoefficients$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkLagrangeCoefficients(b *testing.B) {
+	suite := testhelper.TestSuite{}
+	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+	n := int64(700)
+	set := make([]int64, n)
+	for i := int64(0); i < n; i++ {
+		set[i] = i + 1
+	}
+
+	b.Run(fmt.Sprintf("individual-%d", n), func(b *testing.B) {
+		b.ResetTimer()
+		for k := 0; k < b.N; k++ {
+			for _, posi := range set {
+				suite.CalculateLagrangeCoeff(posi, set)
+			}
+		}
+	})
+
+	b.Run(fmt.Sprintf("batch-%d", n), func(b *testing.B) {
+		b.ResetTimer()
+		for k := 0; k < b.N; k++ {
+			suite.CalculateLagrangeCoeffs(set)
+		}
+	})
+}
+
+// nonce preprocessing of a single signer, sizing how many signatures it can prepare for
+// the count can be set with FROST_BENCH_NONCES instead of the sweep
+// FROST_BENCH_NONCES=5000 go test -benchmem -run=^$ -bench ^BenchmarkFrostPreprocess$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+// go test -benchmem -run=^$ -bench ^BenchmarkFrostPreprocess$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkFrostPreprocess(b *testing.B) {
+	nonce_counts := []int64{10, 100, 1000, 10000}
+	if count_env, ok := os.LookupEnv("FROST_BENCH_NONCES"); ok {
+		count, err := strconv.ParseInt(count_env, 10, 64)
+		if err != nil || count < 1 {
+			b.Skipf("invalid benchmark configuration: FROST_BENCH_NONCES: %s", count_env)
+		}
+		nonce_counts = []int64{count}
+	}
+
+	suite := testhelper.TestSuite{}
+	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+	participant := testhelper.NewFrostParticipant(&suite, log.Default(), 3, 1, 1, nil)
+
+	for _, count := range nonce_counts {
+		b.Run(fmt.Sprintf("frost-preprocess-%d", count), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				participant.GenerateSigningNonces(count)
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(b.Elapsed().Microseconds())/1000/float64(int64(b.N)*count), "ms/nonce")
+		})
+	}
+}
+
+// many signing sessions run at once on the same signers, each session with its own signing index and aggregator
+// signers only share their session lock between sessions, a drop of signatures/sec with more goroutines is contention on it
+// go test -race -benchmem -run=^$ -bench ^BenchmarkFrostSigningParallel$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkFrostSigningParallel(b *testing.B) {
+	suite := testhelper.TestSuite{}
+	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+	n := int64(10)
+	threshold := int64(6)
+	participants := testhelper.SetupAndExchangeCommitments(&suite, log.Default(), n, threshold)
+	// s_j = \sum_{i} f_i(j), shares are not verified as in the DKG benchmark
+	signing_shares := make(map[int64]*btcec.ModNScalar)
+	for i := int64(0); i < n; i++ {
+		participants[i].CalculateSecretShares()
+	}
+	for j := int64(1); j <= n; j++ {
+		signing_shares[j] = new(btcec.ModNScalar)
+		for i := int64(0); i < n; i++ {
+			signing_shares[j].Add(participants[i].GetSecretShares(j))
+		}
+		participants[j-1].CalculateGroupPublicKey()
+	}
+	group_key := participants[0].GroupPublicKey
+
+	honest := make([]int64, threshold+1)
+	for i := range honest {
+		honest[i] = int64(i + 1)
+	}
+	message_hash := sha256.Sum256([]byte("parallel signing"))
+
+	for _, goroutines := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("goroutines-per-cpu-%d", goroutines), func(b *testing.B) {
+			// one preprocessed nonce pair per signature and signer
+			public_nonces := make([]map[int64][2]*btcec.PublicKey, b.N)
+			for k := range public_nonces {
+				public_nonces[k] = make(map[int64][2]*btcec.PublicKey)
+			}
+			for _, posi := range honest {
+				for k, nonces := range participants[posi-1].GenerateSigningNonces(int64(b.N)) {
+					public_nonces[k][posi] = nonces
+				}
+			}
+
+			var next atomic.Int64
+			b.SetParallelism(goroutines)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					signing_index := next.Add(1) - 1
+					partial_sigs := make(map[int64]*schnorr.Signature, len(honest))
+					for _, posi := range honest {
+						participant := participants[posi-1]
+						if err := participant.BeginSigningSession(signing_index); err != nil {
+							b.Error(err)
+							return
+						}
+						if _, err := participant.CalculatePublicNonceCommitments(signing_index, honest, message_hash, public_nonces[signing_index]); err != nil {
+							b.Error(err)
+							return
+						}
+						partial_sigs[posi] = participant.PartialSign(posi, signing_index, honest, message_hash, public_nonces[signing_index], signing_shares[posi])
+						participant.EndSigningSession(signing_index)
+					}
+
+					sig := testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(signing_index, partial_sigs)
+					if !sig.Verify(message_hash[:], group_key) {
+						b.Errorf("invalid signature for signing index %d", signing_index)
+					}
+				}
+			})
+			b.StopTimer()
+
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "signatures/sec")
+		})
+	}
+}
+
+// distribution of all dealers commitments to every participant, one call per dealer against a single batch call
+// participants are fresh for each iteration, as in the DKG setup
+// the batch worker pool is sized by GOMAXPROCS, -cpu compares a single core against several
+// go test -benchmem -run=^$ -bench ^BenchmarkUpdatePolynomialCommitments$ -cpu 1,4 github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkUpdatePolynomialCommitments(b *testing.B) {
+	suite := testhelper.TestSuite{}
+	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+	n := int64(1000)
+	threshold := int64(700)
+	// a single dealer polynomial is enough, only the distribution is measured
+	dealer := testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, 1, nil)
+	all_commitments := make(map[int64][]*btcec.PublicKey, n)
+	for i := int64(0); i < n; i++ {
+		all_commitments[i+1] = dealer.PolynomialCommitments[1]
+	}
+	new_participants := func() []*testhelper.FrostParticipant {
+		participants := make([]*testhelper.FrostParticipant, n)
+		for i := int64(0); i < n; i++ {
+			participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 0, i+1, nil)
+			participants[i].Threshold = threshold
+			delete(participants[i].PolynomialCommitments, i+1)
+		}
+		return participants
+	}
+
+	b.Run(fmt.Sprintf("per-call-%d", n), func(b *testing.B) {
+		for k := 0; k < b.N; k++ {
+			b.StopTimer()
+			participants := new_participants()
+			b.StartTimer()
+			for j := int64(0); j < n; j++ {
+				for i := int64(0); i < n; i++ {
+					participants[j].UpdatePolynomialCommitments(i+1, all_commitments[i+1])
+				}
+			}
+		}
+	})
+
+	b.Run(fmt.Sprintf("batch-%d", n), func(b *testing.B) {
+		for k := 0; k < b.N; k++ {
+			b.StopTimer()
+			participants := new_participants()
+			b.StartTimer()
+			for j := int64(0); j < n; j++ {
+				participants[j].UpdateAllPolynomialCommitments(all_commitments)
+			}
+		}
+	})
+}
+
+// \sum_{i} k_i * P_i over n = 1000 terms, separate scalar multiplications against the Pippenger bucket method
+// go test -benchmem -run=^$ -bench ^BenchmarkMultiScalarMul$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkMultiScalarMul(b *testing.B) {
+	suite := testhelper.TestSuite{}
+	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+	n := 1000
+	scalars := make([]*btcec.ModNScalar, n)
+	points := make([]*btcec.PublicKey, n)
+	for i := 0; i < n; i++ {
+		seed := suite.Generate32BSeed()
+		scalars[i] = new(btcec.ModNScalar)
+		scalars[i].SetBytes(&seed)
+		seed = suite.Generate32BSeed()
+		key, _ := btcec.PrivKeyFromBytes(seed[:])
+		points[i] = key.PubKey()
+	}
+
+	b.Run(fmt.Sprintf("naive-%d", n), func(b *testing.B) {
+		for k := 0; k < b.N; k++ {
+			sum := new(btcec.JacobianPoint)
+			for i := 0; i < n; i++ {
+				point := new(btcec.JacobianPoint)
+				points[i].AsJacobian(point)
+				btcec.ScalarMultNonConst(scalars[i], point, point)
+				btcec.AddNonConst(sum, point, sum)
+			}
+		}
+	})
+
+	b.Run(fmt.Sprintf("pippenger-%d", n), func(b *testing.B) {
+		for k := 0; k < b.N; k++ {
+			_, err := testhelper.MultiScalarMul(scalars, points)
+			assert.NoError(b, err)
+		}
+	})
+}
+
+// shares of 100 dealers of a 700 / 1000 setup verified by a single participant, with each batch verify strategy
+// go test -benchmem -run=^$ -bench ^BenchmarkVerifyBatchPublicSecretShares$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkVerifyBatchPublicSecretShares(b *testing.B) {
+	suite := testhelper.TestSuite{}
+	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+	n := int64(1000)
+	threshold := int64(700)
+	dealers := int64(100)
+	// dealers share a single polynomial, the verification work is the same as with distinct ones
+	dealer := testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, 1, nil)
+	dealer.CalculateSecretShares()
+	receiver := testhelper.NewFrostParticipant(&suite, log.Default(), n, 0, 2, nil)
+	receiver.Threshold = threshold
+	delete(receiver.PolynomialCommitments, 2)
+	receiver.DerivePowerMapForSet(map[int64]bool{2: true})
+	all_commitments := make(map[int64][]*btcec.PublicKey, dealers)
+	secret_shares := make(map[int64]*btcec.ModNScalar, dealers)
+	for i := int64(1); i <= dealers; i++ {
+		all_commitments[i] = dealer.PolynomialCommitments[1]
+		secret_shares[i] = dealer.GetSecretShares(2)
+	}
+	receiver.UpdateAllPolynomialCommitments(all_commitments)
+
+	strategies := []struct {
+		name     string
+		strategy testhelper.BatchVerifyStrategy
+	}{
+		{"per-dealer", testhelper.BatchVerifyPerDealer},
+		{"msm", testhelper.BatchVerifyMSM},
+	}
+	for _, strategy := range strategies {
+		b.Run(strategy.name, func(b *testing.B) {
+			receiver.SetBatchVerifyStrategy(strategy.strategy)
+
+			b.ResetTimer()
+			for k := 0; k < b.N; k++ {
+				_, err := receiver.VerifyBatchPublicSecretShares(secret_shares, 2)
+				assert.NoError(b, err)
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(b.Elapsed().Microseconds())/1000/float64(b.N), "ms/verify-batch-public-secret-shares")
+		})
+	}
+}
+
+// filing and adjudicating one complaint against an honest dealer, the accused reveals f_j(i) and the coordinator checks it
+// against the t + 1 commitments of the dealer, a dealer that does not answer is disqualified without any curve operation
+// go test -benchmem -run=^$ -bench ^BenchmarkComplaintResolution$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func BenchmarkComplaintResolution(b *testing.B) {
+	test_suite := []FrostDKGConfig{
+		{
+			n:         100,
+			threshold: 70,
+		},
+		{
+			n:         500,
+			threshold: 350,
+		},
+		{
+			n:         1000,
+			threshold: 700,
+		},
+		{
+			n:         2000,
+			threshold: 1400,
+		},
+	}
+
+	for _, test := range test_suite {
+		b.Run(fmt.Sprintf("complaint-resolution-%d/%d", test.threshold, test.n), func(b *testing.B) {
+			suite := testhelper.TestSuite{}
+			suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
+
+			dealer := testhelper.NewFrostParticipant(&suite, log.Default(), test.n, test.threshold, 1, nil)
+			dealer.CalculateSecretShares()
+			accuser := testhelper.NewFrostParticipant(&suite, log.Default(), test.n, test.threshold, test.n, nil)
+			adjudicator := testhelper.NewFrostParticipant(&suite, log.Default(), test.n, test.threshold, 2, nil)
+			adjudicator.UpdatePolynomialCommitments(1, dealer.PolynomialCommitments[1])
+			coordinator := testhelper.NewFrostCoordinator(&suite, adjudicator)
+
+			b.ResetTimer()
+			for k := 0; k < b.N; k++ {
+				complaint := accuser.RaiseComplaint(1)
+				coordinator.SubmitJustification(dealer.Justify(complaint))
+				assert.False(b, coordinator.ResolveComplaint(complaint))
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(b.Elapsed().Microseconds())/1000/float64(b.N), "ms/complaint-resolution")
+		})
+	}
+}
+
+// go test -v -run ^TestFrostDKGConfigFromEnv$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func TestFrostDKGConfigFromEnv(t *testing.T) {
+	defaults := []FrostDKGConfig{{n: 100, threshold: 70}}
+
+	configs, err := FrostDKGConfigFromEnv(defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, defaults, configs)
+
+	// invalid values
+	t.Setenv("FROST_BENCH_N", "6")
+	t.Setenv("FROST_BENCH_THRESHOLD", "6")
+	_, err = FrostDKGConfigFromEnv(defaults)
+	assert.Error(t, err)
+	t.Setenv("FROST_BENCH_THRESHOLD", "3")
+	t.Setenv("FROST_BENCH_N_KEYS", "7")
+	_, err = FrostDKGConfigFromEnv(defaults)
+	assert.Error(t, err)
+
+	t.Setenv("FROST_BENCH_N_KEYS", "6")
+	configs, err = FrostDKGConfigFromEnv(defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, []FrostDKGConfig{{n: 6, threshold: 3}}, configs)
+
+	// run the configured benchmark briefly
+	config := configs[0]
+	result := testing.Benchmark(func(b *testing.B) {
+		RunFrostDKG("frost-dkg-env", config.n, config.threshold, b)
+	})
+	assert.Equal(t, float64(6), result.Extra["participants"])
+}
+
+// go test -v -run ^TestFrostDKGSimulatedNetwork$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
+func TestFrostDKGSimulatedNetwork(t *testing.T) {
+	n := int64(5)
+	delay := 100 * time.Microsecond
+	network := testhelper.NewSimNetwork(delay)
+
+	result := testing.Benchmark(func(b *testing.B) {
+		RunFrostDKGWithNetwork("frost-dkg-network", n, 3, network, b)
+	})
+
+	// secret proofs and secret shares, each n * (n - 1) deliveries
+	assert.Equal(t, 2*n*(n-1), network.Messages())
+	assert.Equal(t, delay*time.Duration(2*n*(n-1)), network.NetworkTime())
+	assert.Equal(t, float64(network.NetworkTime().Microseconds())/1000, result.Extra["ms/network"])
+	assert.InDelta(t, result.Extra["ms/wall"], result.Extra["ms/network"]+result.Extra["ms/compute"], 0.01)
+	assert.GreaterOrEqual(t, result.Extra["ms/wall"], result.Extra["ms/network"])
+	// sections excluded from the benchmark timer are excluded from the wall clock as well
+	assert.InDelta(t, float64(result.T.Microseconds())/1000, result.Extra["ms/wall"], 1)
+}
+
 // go test -timeout 1h -run ^TestBenchmarkWstsDKG$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
 func TestBenchmarkWstsDKG(t *testing.T) {
 	test_suite := []*WstsBenchmark{
@@ -101,36 +620,35 @@ func TestBenchmarkWstsDKG(t *testing.T) {
 
 // go test -benchmem -run=^$ -bench ^BenchmarkFrostDKG$ github.com/nghuyenthevinh2000/bitcoin-playground/benchmark
 func RunFrostDKG(name string, n, threshold int64, b *testing.B) {
+	RunFrostDKGWithNetwork(name, n, threshold, nil, b)
+}
+
+// the network delay is injected for each point - to - point delivery of secret proofs and secret shares
+// reports wall clock, network time and compute time = wall clock - network time
+// the wall clock excludes copying the Q and W maps to the simulated participants, as the benchmark timer does
+// a nil network injects no delay
+func RunFrostDKGWithNetwork(name string, n, threshold int64, network *testhelper.SimNetwork, b *testing.B) {
+	network.Reset()
+
 	suite := testhelper.TestSuite{}
 	suite.SetupBenchmarkStaticSimNetSuite(b, log.Default())
 
-	// frost
-	participants := make([]*testhelper.FrostParticipant, n)
+	// frost participants with the commitments of every dealer
 	logger := log.Default()
-	for i := int64(0); i < n; i++ {
-		participants[i] = testhelper.NewFrostParticipant(&suite, logger, n, threshold, i+1, nil)
-	}
-
-	// update polynomial commitments
-	for i := int64(0); i < n; i++ {
-		for j := int64(0); j < n; j++ {
-			if i == j {
-				continue
-			}
-			participant := participants[j]
-			participant.UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
-		}
-	}
+	participants := testhelper.SetupAndExchangeCommitments(&suite, logger, n, threshold)
 
 	// generate challenges
 	b.ResetTimer()
 	b.StartTimer()
 	time_now := time.Now()
+	time_all := time_now
 	for i := int64(0); i < n; i++ {
 		participant := participants[i]
 		challenge := participant.CalculateSecretProofs([32]byte{})
 		participant.VerifySecretProofs([32]byte{}, challenge, i+1, participant.PolynomialCommitments[participant.Position][0])
 	}
+	// broadcast secret proofs
+	network.Deliver(n * (n - 1))
 	// suite.LogBenchmarkThreadSafeReport("ms/secret-proofs", float64(time.Since(time_now).Milliseconds()), true)
 
 	// calculate secret shares
@@ -158,6 +676,7 @@ func RunFrostDKG(name string, n, threshold int64, b *testing.B) {
 			secret_shares_map[j+1][i+1] = secret
 		}
 	}
+	network.Deliver(n * (n - 1))
 
 	// derive power map
 	for i := int64(0); i < n; i++ {
@@ -213,10 +732,15 @@ func RunFrostDKG(name string, n, threshold int64, b *testing.B) {
 	// In a distributed settings, each participant will independently calculate this value and derive the same value.
 	// So, it is OK to copy the value from the first participant to all other participants.
 	// If someone wants to verify, they can uncomment the same functionallity in the loop below.
-	time_now = time.Now()
-	participants[0].DeriveExternalQMap()
-	participants[0].DeriveExternalWMap()
+	suite.TimedSection("derive-external-q-w-map", func() {
+		participants[0].DeriveExternalQMap()
+		participants[0].DeriveExternalWMap()
+	})
 
+	// copying the maps only simulates the other participants, it is not measured
+	// neither by the benchmark timer nor by ms/wall and ms/compute, thus its duration is subtracted below
+	b.StopTimer()
+	time_map_copy := time.Now()
 	for i := int64(1); i < n; i++ {
 		wg.Add(1)
 		go func(i int64) {
@@ -226,7 +750,8 @@ func RunFrostDKG(name string, n, threshold int64, b *testing.B) {
 		}(i)
 	}
 	wg.Wait()
-	suite.LogBenchmarkThreadSafeReport("ms/derive-external-q-w-map", float64(time.Since(time_now).Milliseconds()), true)
+	time_map_copy_duration := time.Since(time_map_copy)
+	b.StartTimer()
 
 	time_now = time.Now()
 	for i := int64(0); i < n; i++ {
@@ -259,6 +784,11 @@ func RunFrostDKG(name string, n, threshold int64, b *testing.B) {
 	// suite.LogBenchmarkThreadSafeReport("ms/calculate-group-public-key", float64(time.Since(time_now).Milliseconds()), true)
 
 	b.StopTimer()
+	b.ReportMetric(float64(n), "participants")
+	wall_time := time.Since(time_all) - time_map_copy_duration
+	b.ReportMetric(float64(wall_time.Microseconds())/1000, "ms/wall")
+	b.ReportMetric(float64(network.NetworkTime().Microseconds())/1000, "ms/network")
+	b.ReportMetric(float64((wall_time-network.NetworkTime()).Microseconds())/1000, "ms/compute")
 
 	// verify correct calculation of public signing shares
 	for i := int64(0); i < n; i++ {
@@ -292,20 +822,11 @@ func (wsts *WstsBenchmark) RunWstsDKG(name string, t *testing.T) {
 	// wsts participant
 	wsts.participants = make([]*testhelper.WstsParticipant, wsts.n_p)
 	logger := log.Default()
+	// n_p dealers, each holding a share for every of the n_keys keys
+	frosts := testhelper.SetupAndExchangeCommitments(&wsts.suite, logger, wsts.n_p, wsts.threshold)
 	for i := int64(0); i < wsts.n_p; i++ {
-		frost := testhelper.NewFrostParticipant(&wsts.suite, logger, wsts.n_keys, wsts.threshold, i+1, nil)
-		wsts.participants[i] = testhelper.NewWSTSParticipant(&wsts.suite, wsts.n_p, frost)
-	}
-
-	// update polynomial commitments
-	for i := int64(0); i < wsts.n_p; i++ {
-		for j := int64(0); j < wsts.n_p; j++ {
-			if i == j {
-				continue
-			}
-			participant := wsts.participants[j]
-			participant.Frost.UpdatePolynomialCommitments(i+1, wsts.participants[i].Frost.PolynomialCommitments[i+1])
-		}
+		assert.NoError(t, frosts[i].UpdateParticipantCount(wsts.n_keys))
+		wsts.participants[i] = testhelper.NewWSTSParticipant(&wsts.suite, wsts.n_p, frosts[i])
 	}
 
 	// update key ranges
@@ -358,8 +879,10 @@ func (wsts *WstsBenchmark) RunWstsDKG(name string, t *testing.T) {
 		}
 	}
 
-	// derive power map
+	// derive power map, the powers are computed once and shared by all participants
+	power_table := testhelper.NewPowerTable(wsts.n_keys, wsts.threshold)
 	for i := int64(0); i < wsts.n_p; i++ {
+		assert.NoError(t, wsts.participants[i].Frost.AttachPowerTable(power_table))
 		wg.Add(1)
 		go func(i int64) {
 			defer wg.Done()
@@ -411,7 +934,11 @@ func (wsts *WstsBenchmark) RunWstsDKG(name string, t *testing.T) {
 	time_map_calculate := time.Now()
 	wsts.participants[0].Frost.DeriveExternalQMap()
 	wsts.participants[0].Frost.DeriveExternalWMap()
+	time_map_calculate_duration := time.Since(time_map_calculate).Milliseconds()
+	wsts.suite.LogBenchmarkThreadSafeReport("ms/derive-external-q-w-map", float64(time_map_calculate_duration), true)
 
+	// copying the maps only simulates the other participants, it is not measured
+	time_map_copy := time.Now()
 	for i := int64(1); i < wsts.n_p; i++ {
 		wg.Add(1)
 		go func(i int64) {
@@ -421,8 +948,7 @@ func (wsts *WstsBenchmark) RunWstsDKG(name string, t *testing.T) {
 		}(i)
 	}
 	wg.Wait()
-	time_map_calculate_duration := time.Since(time_map_calculate).Milliseconds()
-	wsts.suite.LogBenchmarkThreadSafeReport("ms/derive-external-q-w-map", float64(time_map_calculate_duration), true)
+	time_map_copy_duration := time.Since(time_map_copy).Milliseconds()
 
 	for i := int64(0); i < wsts.n_p; i++ {
 		wg.Add(1)
@@ -452,6 +978,16 @@ func (wsts *WstsBenchmark) RunWstsDKG(name string, t *testing.T) {
 	wg.Wait()
 	// suite.LogBenchmarkThreadSafeReport("ms/calculate-group-public-key", float64(time.Since(time_now).Milliseconds()), true)
 
+	// verify ss and map calculation is done one time only to save CPU time since these two operations are expensive on one machine
+	time_all_duration := time.Since(time_all).Milliseconds()
+	time_all_duration -= time_verify_ss_duration
+	time_all_duration -= time_map_calculate_duration
+	time_all_duration -= time_map_copy_duration
+	time_each_duration := time_all_duration / int64(wsts.n_p)
+	time_each_duration += time_verify_ss_duration
+	time_each_duration += time_map_calculate_duration
+	wsts.suite.LogBenchmarkThreadSafeReport("ms/wsts-dkg", float64(time_each_duration), false)
+
 	// verify correct calculation of public signing shares
 	for i := int64(0); i < wsts.n_p; i++ {
 		participant := wsts.participants[i]
@@ -468,15 +1004,6 @@ func (wsts *WstsBenchmark) RunWstsDKG(name string, t *testing.T) {
 		}
 	}
 
-	// verify ss and map calculation is done one time only to save CPU time since these two operations are expensive on one machine
-	time_all_duration := time.Since(time_all).Milliseconds()
-	time_all_duration -= time_verify_ss_duration
-	time_all_duration -= time_map_calculate_duration
-	time_each_duration := time_all_duration / int64(wsts.n_p)
-	time_each_duration += time_verify_ss_duration
-	time_each_duration += time_map_calculate_duration
-	wsts.suite.LogBenchmarkThreadSafeReport("ms/wsts-dkg", float64(time_each_duration), false)
-
 	// dump logs
 	wsts.suite.FlushBenchmarkThreadSafeReport()
 }
@@ -487,6 +1014,16 @@ func (wsts *WstsBenchmark) RunWstsSigning(name string, t *testing.T) {
 	// honest_set[0] key_share is honest_keys[0]
 	honest_set := wsts.suite.RandomHonestSet(wsts.n_p, wsts.n_keys, wsts.key_shares)
 
+	// public signing shares of the keys of each signer are looked up before the measured stages
+	// every participant derived the same values during DKG
+	public_signing_shares := make(map[int64]map[int64]*btcec.PublicKey)
+	for _, posi := range honest_set {
+		public_signing_shares[posi] = make(map[int64]*btcec.PublicKey)
+		for key := range wsts.participants[0].Keys[posi] {
+			public_signing_shares[posi][key] = wsts.participants[0].Frost.GetPublicSigningShares(key)
+		}
+	}
+
 	time_all := time.Now()
 
 	// Stage 1: Nonce generation
@@ -498,7 +1035,8 @@ func (wsts *WstsBenchmark) RunWstsSigning(name string, t *testing.T) {
 
 	for _, participant_index := range honest_set {
 		participant := wsts.participants[participant_index-1]
-		participant.Frost.CalculatePublicNonceCommitments(signing_index, honest_set, [32]byte{}, public_nonces)
+		_, err := participant.Frost.CalculatePublicNonceCommitments(signing_index, honest_set, [32]byte{}, public_nonces)
+		assert.NoError(t, err)
 	}
 
 	// Stage 2: Partial signature generation (Benchmark ends here for individual participants)
@@ -521,13 +1059,8 @@ func (wsts *WstsBenchmark) RunWstsSigning(name string, t *testing.T) {
 					continue
 				}
 
-				public_signing_share := make(map[int64]*btcec.PublicKey)
-				for key := range participant.Keys[posi] {
-					public_signing_share[key] = participant.Frost.GetPublicSigningShares(key)
-				}
-
 				// Verify partial signatures
-				ok := participant.WeightedPartialVerification(p_sig, signing_index, posi, [32]byte{}, honest_set, public_signing_share)
+				ok := participant.WeightedPartialVerification(p_sig, signing_index, posi, [32]byte{}, honest_set, public_signing_shares[posi])
 				assert.True(wsts.suite.T, ok, fmt.Sprintf("participant %d: failed to verify partial signature of %d", participant.Frost.Position, posi))
 			}
 		}(participant_index)
@@ -538,3 +1071,73 @@ func (wsts *WstsBenchmark) RunWstsSigning(name string, t *testing.T) {
 	time_each_duration := time_all_duration / int64(len(honest_set))
 	wsts.suite.LogBenchmarkThreadSafeReport(fmt.Sprintf("ms/wsts-signing-%d", len(honest_set)), float64(time_each_duration), false)
 }
+
+// setup a frost group of n participants, then collect partial signatures from threshold + 1 signers
+// public signing shares are not needed for aggregation, thus they are skipped
+func setupFrostPartialSignatures(suite *testhelper.TestSuite, n, threshold int64, message_hash [32]byte) (*testhelper.FrostAggregator, map[int64]*schnorr.Signature) {
+	participants := make([]*testhelper.FrostParticipant, n)
+	logger := log.Default()
+	var wg sync.WaitGroup
+	for i := int64(0); i < n; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			participants[i] = testhelper.NewFrostParticipant(suite, logger, n, threshold, i+1, nil)
+			participants[i].CalculateSecretShares()
+		}(i)
+	}
+	wg.Wait()
+
+	// the group public key only needs the constant term commitments
+	leader := participants[0]
+	for i := int64(1); i < n; i++ {
+		leader.UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+	}
+	leader.CalculateGroupPublicKey()
+
+	// secret polynomial has degree threshold, thus threshold + 1 signers are needed
+	honest := make([]int64, threshold+1)
+	for i := range honest {
+		honest[i] = int64(i + 1)
+	}
+
+	// signing shares s_i = \sum_{j=1}^{n} f_j(i)
+	signing_shares := make(map[int64]*btcec.ModNScalar)
+	for _, posi := range honest {
+		signing_shares[posi] = new(btcec.ModNScalar)
+		for j := int64(0); j < n; j++ {
+			signing_shares[posi].Add(participants[j].GetSecretShares(posi))
+		}
+	}
+
+	// nonce generation
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		nonces := participants[posi-1].GenerateSigningNonces(1)
+		public_nonces[posi] = nonces[0]
+	}
+	_, err := leader.CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+	assert.NoError(suite.T, err)
+
+	// each signer would derive the same values, copying from the leader to save CPU time
+	for _, posi := range honest {
+		participants[posi-1].GroupPublicKey = leader.GroupPublicKey
+		participants[posi-1].AggrNonceCommitment[0] = leader.AggrNonceCommitment[0]
+	}
+
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	var mu sync.Mutex
+	for _, posi := range honest {
+		wg.Add(1)
+		go func(posi int64) {
+			defer wg.Done()
+			sig := participants[posi-1].PartialSign(posi, 0, honest, message_hash, public_nonces, signing_shares[posi])
+			mu.Lock()
+			partial_sigs[posi] = sig
+			mu.Unlock()
+		}(posi)
+	}
+	wg.Wait()
+
+	return testhelper.NewFrostAggregator(suite, leader), partial_sigs
+}
@@ -0,0 +1,21 @@
+package frost
+
+import (
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// thin verifier clients never touch the DKG
+// a FROST signature is a BIP340 Schnorr signature under the x - only group public key
+func Verify(groupKeyXOnly [32]byte, msg [32]byte, sig []byte) bool {
+	group_key, err := schnorr.ParsePubKey(groupKeyXOnly[:])
+	if err != nil {
+		return false
+	}
+
+	signature, err := schnorr.ParseSignature(sig)
+	if err != nil {
+		return false
+	}
+
+	return signature.Verify(msg[:], group_key)
+}
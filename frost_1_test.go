@@ -38,7 +38,7 @@ func TestFrostSignature1(t *testing.T) {
 	for i := int64(0); i < n; i++ {
 		participant := participants[i]
 		challenge := participant.CalculateSecretProofs([32]byte{})
-		participant.VerifySecretProofs([32]byte{}, challenge, i+1, participant.PolynomialCommitments[participant.Position][0])
+		assert.NoError(t, participant.VerifySecretProofs([32]byte{}, challenge, i+1, participant.PolynomialCommitments[participant.Position][0]))
 	}
 
 	// calculate secret shares
@@ -61,7 +61,8 @@ func TestFrostSignature1(t *testing.T) {
 		participant := participants[i]
 
 		// try out batch verification of secret shares
-		participant.VerifyBatchPublicSecretShares(secret_shares_map[participant.Position], uint32(participant.Position))
+		_, err := participant.VerifyBatchPublicSecretShares(secret_shares_map[participant.Position], uint32(participant.Position))
+		assert.NoError(t, err)
 	}
 
 	// calculate public signing shares
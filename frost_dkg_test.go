@@ -0,0 +1,2452 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/nghuyenthevinh2000/bitcoin-playground/testhelper"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// go test -v -run ^TestFrostGroupKeyCommitment$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostGroupKeyCommitment(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(4)
+	threshold := int64(2)
+	logger := log.Default()
+
+	// dealer 3 and dealer 4 share the same secret
+	// thus, qualified sets {1, 2, 3} and {1, 2, 4} derive the same group key
+	secrets := suite.GeneratePolynomial(2)
+	secrets = append(secrets, new(btcec.ModNScalar).Set(secrets[2]))
+	dealers := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		dealers[i] = testhelper.NewFrostParticipant(&suite, logger, n, threshold, i+1, new(btcec.ModNScalar).Set(secrets[i]))
+	}
+
+	participant_a := testhelper.NewFrostParticipant(&suite, logger, n, threshold, 1, new(btcec.ModNScalar).Set(secrets[0]))
+	participant_a.UpdatePolynomialCommitments(2, dealers[1].PolynomialCommitments[2])
+	participant_a.UpdatePolynomialCommitments(3, dealers[2].PolynomialCommitments[3])
+
+	participant_b := testhelper.NewFrostParticipant(&suite, logger, n, threshold, 1, new(btcec.ModNScalar).Set(secrets[0]))
+	participant_b.UpdatePolynomialCommitments(2, dealers[1].PolynomialCommitments[2])
+	participant_b.UpdatePolynomialCommitments(4, dealers[3].PolynomialCommitments[4])
+
+	assert.Equal(t, participant_a.CalculateGroupPublicKey(), participant_b.CalculateGroupPublicKey())
+	assert.Equal(t, []int64{1, 2, 3}, participant_a.QualifiedSet())
+	assert.Equal(t, []int64{1, 2, 4}, participant_b.QualifiedSet())
+
+	// commitment is deterministic
+	assert.Equal(t, participant_a.GroupKeyCommitment(), participant_a.GroupKeyCommitment())
+	// same key, different qualified set
+	assert.NotEqual(t, participant_a.GroupKeyCommitment(), participant_b.GroupKeyCommitment())
+}
+
+// go test -v -run ^TestFrostPublicShareMerkleProof$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostPublicShareMerkleProof(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// odd n to exercise the duplicated last node
+	participants, _ := runFrostDKG(&suite, 7, 4)
+
+	root, err := participants[0].PublicShareMerkleRoot()
+	assert.NoError(t, err)
+	for _, participant := range participants[1:] {
+		other_root, err := participant.PublicShareMerkleRoot()
+		assert.NoError(t, err)
+		assert.Equal(t, root, other_root)
+	}
+
+	for index := int64(1); index <= 7; index++ {
+		proof, err := participants[0].PublicShareMerkleProof(index)
+		assert.NoError(t, err)
+		share := participants[index-1].GetPublicSigningShares(index)
+		assert.True(t, testhelper.VerifyPublicShareMerkleProof(root, index, share, proof))
+
+		// proof does not hold for a different index or share
+		assert.False(t, testhelper.VerifyPublicShareMerkleProof(root, index%7+1, share, proof))
+		other_share := participants[0].GetPublicSigningShares(index%7 + 1)
+		assert.False(t, testhelper.VerifyPublicShareMerkleProof(root, index, other_share, proof))
+	}
+
+	// indices outside [1, n] have no leaf
+	for _, index := range []int64{0, 8, -1} {
+		_, err := participants[0].PublicShareMerkleProof(index)
+		assert.ErrorIs(t, err, testhelper.ErrIndexOutOfRange)
+	}
+
+	// a missing public signing share is an error, not a tree over a partial set
+	participants[1].PublicSigningShares.Delete(int64(4))
+	_, err = participants[1].PublicShareMerkleRoot()
+	assert.ErrorIs(t, err, testhelper.ErrMissingPublicSigningShare)
+	_, err = participants[1].PublicShareMerkleProof(2)
+	assert.ErrorIs(t, err, testhelper.ErrMissingPublicSigningShare)
+	_, err = participants[1].DKGResult()
+	assert.ErrorIs(t, err, testhelper.ErrMissingPublicSigningShare)
+}
+
+// go test -v -race -run ^TestFrostParseQWMapConcurrent$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostParseQWMapConcurrent(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(10)
+	threshold := int64(6)
+	participants := make([]*testhelper.FrostParticipant, n)
+	logger := log.Default()
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, logger, n, threshold, i+1, nil)
+	}
+	for i := int64(1); i < n; i++ {
+		participants[0].UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+	}
+
+	participants[0].DerivePowerMap()
+	participants[0].DeriveExternalQMap()
+	participants[0].DeriveExternalWMap()
+	q_map := participants[0].CopyQMap()
+	w_map := participants[0].CopyWMap()
+
+	// all participants parse the same source maps
+	var wg sync.WaitGroup
+	for i := int64(1); i < n; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			participants[i].ParseQMap(q_map)
+			participants[i].ParseWMap(w_map)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := int64(1); i < n; i++ {
+		for posi := int64(1); posi <= n; posi++ {
+			source_q := q_map[posi].([]*btcec.JacobianPoint)
+			source_w := w_map[posi].([]*btcec.JacobianPoint)
+			parsed_q := participants[i].GetQMapItem(posi)
+			parsed_w := participants[i].GetWMapItem(posi)
+			assert.Equal(t, source_q, parsed_q)
+			assert.Equal(t, source_w, parsed_w)
+
+			// no point is shared with the source
+			for j := range source_q {
+				assert.NotSame(t, source_q[j], parsed_q[j])
+				assert.NotSame(t, source_w[j], parsed_w[j])
+			}
+		}
+	}
+}
+
+// go test -v -run ^TestFrostEstimateProofCost$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostEstimateProofCost(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(20)
+	estimate, err := testhelper.EstimateProofCost(n)
+	assert.NoError(t, err)
+
+	participants := make([]*testhelper.FrostParticipant, n)
+	logger := log.Default()
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, logger, n, 5, i+1, nil)
+	}
+
+	// secret proofs phase as in the benchmark
+	time_now := time.Now()
+	for i := int64(0); i < n; i++ {
+		participant := participants[i]
+		challenge := participant.CalculateSecretProofs([32]byte{})
+		assert.NoError(t, participant.VerifySecretProofs([32]byte{}, challenge, i+1, participant.PolynomialCommitments[participant.Position][0]))
+	}
+	measured := time.Since(time_now)
+
+	t.Logf("estimate: %v, measured: %v", estimate, measured)
+	assert.Less(t, estimate, measured*10)
+	assert.Greater(t, estimate, measured/10)
+}
+
+// go test -v -run ^TestFrostEncryptedShares$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostEncryptedShares(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(4)
+	threshold := int64(2)
+	logger := log.Default()
+	dealer := testhelper.NewFrostParticipant(&suite, logger, n, threshold, 1, nil)
+	dealer.CalculateSecretShares()
+
+	encryption_pairs := make(map[int64]testhelper.KeyPair)
+	encryption_keys := make(map[int64]*btcec.PublicKey)
+	for posi := int64(2); posi <= n; posi++ {
+		encryption_pairs[posi] = suite.NewKeyPairFromBytes(nil)
+		encryption_keys[posi] = encryption_pairs[posi].Pub
+	}
+	encrypted_shares, err := dealer.DealVerifiablyEncrypted(encryption_keys)
+	assert.NoError(t, err)
+
+	// an auditor holding only the commitments of the dealer
+	auditor := testhelper.NewFrostParticipant(&suite, logger, n, threshold, 2, nil)
+	auditor.UpdatePolynomialCommitments(1, dealer.PolynomialCommitments[1])
+	for posi := int64(2); posi <= n; posi++ {
+		assert.NoError(t, auditor.VerifyEncryptedShare(1, encrypted_shares[posi], encryption_keys[posi]))
+
+		recipient := testhelper.NewFrostParticipant(&suite, logger, n, threshold, posi, nil)
+		recipient.UpdatePolynomialCommitments(1, dealer.PolynomialCommitments[1])
+		encryption_pair := encryption_pairs[posi]
+		share, err := recipient.DecryptShare(1, encrypted_shares[posi], encryption_pair.GetTestPriv())
+		assert.NoError(t, err)
+		assert.True(t, dealer.GetSecretShares(posi).Equals(share))
+
+		// a share encrypted to another recipient
+		_, err = recipient.DecryptShare(1, encrypted_shares[posi%(n-1)+2], encryption_pair.GetTestPriv())
+		assert.ErrorIs(t, err, testhelper.ErrEncryptedShareMismatch)
+	}
+	// proofs are bound to the encryption key of the recipient
+	assert.ErrorIs(t, auditor.VerifyEncryptedShare(1, encrypted_shares[2], encryption_keys[3]), testhelper.ErrInvalidEncryptedShare)
+
+	// a dealer encrypting shares of a polynomial other than the committed one is caught without decryption
+	cheater := testhelper.NewFrostParticipant(&suite, logger, n, threshold, 1, nil)
+	cheating_shares, err := cheater.DealVerifiablyEncrypted(map[int64]*btcec.PublicKey{2: encryption_keys[2]})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, auditor.VerifyEncryptedShare(1, cheating_shares[2], encryption_keys[2]), testhelper.ErrInvalidEncryptedShare)
+	recipient := testhelper.NewFrostParticipant(&suite, logger, n, threshold, 2, nil)
+	recipient.UpdatePolynomialCommitments(1, dealer.PolynomialCommitments[1])
+	encryption_pair := encryption_pairs[2]
+	_, err = recipient.DecryptShare(1, cheating_shares[2], encryption_pair.GetTestPriv())
+	assert.ErrorIs(t, err, testhelper.ErrEncryptedShareMismatch)
+
+	// a swapped bit ciphertext breaks its bit proof
+	tampered := *encrypted_shares[2]
+	tampered.C2 = append([]*btcec.PublicKey{}, tampered.C2...)
+	tampered.C2[0], tampered.C2[1] = tampered.C2[1], tampered.C2[0]
+	assert.ErrorIs(t, auditor.VerifyEncryptedShare(1, &tampered, encryption_keys[2]), testhelper.ErrInvalidEncryptedShare)
+	tampered.C2 = tampered.C2[:255]
+	assert.ErrorIs(t, auditor.VerifyEncryptedShare(1, &tampered, encryption_keys[2]), testhelper.ErrInvalidEncryptedShare)
+}
+
+// go test -v -run ^TestFrostSigningShareFromPolynomials$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSigningShareFromPolynomials(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 6, 3)
+
+	dealer_polys := make(map[int64][]*btcec.ModNScalar)
+	for _, participant := range participants {
+		dealer_polys[participant.Position] = participant.SecretPolynomial()
+	}
+
+	for _, participant := range participants {
+		expected := testhelper.SigningShareFromPolynomials(dealer_polys, participant.Position)
+		assert.Equal(t, expected, signing_shares[participant.Position])
+		assert.Equal(t, expected, participant.GetSigningShares())
+	}
+}
+
+// go test -v -run ^TestFrostDKGCertificate$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDKGCertificate(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	coordinator := testhelper.NewFrostCoordinator(&suite, participants[0])
+
+	// all participants agree on the certificate message
+	message := coordinator.CertificateMessage()
+	for _, participant := range participants[1:] {
+		assert.Equal(t, message, testhelper.NewFrostCoordinator(&suite, participant).CertificateMessage())
+	}
+
+	// not enough signatures
+	_, err := coordinator.GenerateDKGCertificate()
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+
+	honest := []int64{1, 2, 4}
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message)
+	for posi, partial_sig := range partial_sigs {
+		coordinator.SubmitCertificateSignature(posi, partial_sig)
+	}
+
+	certificate, err := coordinator.GenerateDKGCertificate()
+	assert.NoError(t, err)
+	assert.NoError(t, testhelper.VerifyDKGCertificate(certificate))
+	assert.Equal(t, schnorr.SerializePubKey(participants[0].GroupPublicKey), certificate[0:32])
+
+	// tampered qualified set
+	tampered := make([]byte, len(certificate))
+	copy(tampered, certificate)
+	tampered[47] ^= 1
+	assert.ErrorIs(t, testhelper.VerifyDKGCertificate(tampered), testhelper.ErrInvalidDKGCertificate)
+	assert.ErrorIs(t, testhelper.VerifyDKGCertificate(certificate[:len(certificate)-1]), testhelper.ErrInvalidDKGCertificate)
+}
+
+// go test -v -run ^TestFrostCommitmentHasher$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostCommitmentHasher(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(6)
+	threshold := int64(3)
+	participants := make([]*testhelper.FrostParticipant, n)
+	points := make([]*btcec.JacobianPoint, 0)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+		for _, commitment := range participants[i].PolynomialCommitments[i+1] {
+			point := new(btcec.JacobianPoint)
+			commitment.AsJacobian(point)
+			points = append(points, point)
+		}
+	}
+
+	// commitments stream in chunks of one dealer
+	hasher := participants[0].CommitmentHasher()
+	chunk_size := int(threshold + 1)
+	for chunk_start := 0; chunk_start < len(points); chunk_start += chunk_size {
+		for _, point := range points[chunk_start : chunk_start+chunk_size] {
+			hasher.Write(point)
+		}
+	}
+	assert.Equal(t, testhelper.HashCommitments(points), hasher.Sum())
+
+	// order matters
+	reordered := append([]*btcec.JacobianPoint{points[1], points[0]}, points[2:]...)
+	assert.NotEqual(t, testhelper.HashCommitments(reordered), hasher.Sum())
+}
+
+// go test -v -run ^TestFrostPublicSigningSharesForSet$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostPublicSigningSharesForSet(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(7)
+	threshold := int64(3)
+	participants, _ := runFrostDKG(&suite, n, threshold)
+
+	// an observer only holds the polynomial commitments
+	observer := testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, 1, nil)
+	for i := int64(1); i <= n; i++ {
+		observer.UpdatePolynomialCommitments(i, participants[i-1].PolynomialCommitments[i])
+	}
+
+	signers := map[int64]bool{2: true, 4: true, 5: true, 7: true}
+	observer.CalculatePublicSigningSharesForSet(signers)
+
+	for posi := int64(1); posi <= n; posi++ {
+		_, ok := observer.PublicSigningShares.Load(posi)
+		assert.Equal(t, signers[posi], ok)
+		if signers[posi] {
+			assert.Equal(t, participants[0].GetPublicSigningShares(posi), observer.GetPublicSigningShares(posi))
+		}
+	}
+}
+
+// go test -v -run ^TestFrostVerifyBatchSecretProofs$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostVerifyBatchSecretProofs(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(8)
+	threshold := int64(4)
+	participants := make([]*testhelper.FrostParticipant, n)
+	proofs := make(map[int64]*testhelper.SecretProof)
+	commitments := make(map[int64]*btcec.JacobianPoint)
+	context_hash := sha256.Sum256([]byte("frost batch secret proofs"))
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+		proofs[i+1] = &testhelper.SecretProof{
+			ContextHash: context_hash,
+			Signature:   participants[i].CalculateSecretProofs(context_hash),
+		}
+		commitments[i+1] = new(btcec.JacobianPoint)
+		participants[i].PolynomialCommitments[i+1][0].AsJacobian(commitments[i+1])
+	}
+
+	ok, failed := participants[0].VerifyBatchSecretProofs(proofs, commitments)
+	assert.True(t, ok)
+	assert.Empty(t, failed)
+
+	// proof of participant 6 is made over a different context
+	proofs[6] = &testhelper.SecretProof{
+		ContextHash: context_hash,
+		Signature:   participants[5].CalculateSecretProofs([32]byte{}),
+	}
+	ok, failed = participants[0].VerifyBatchSecretProofs(proofs, commitments)
+	assert.False(t, ok)
+	assert.Equal(t, []int64{6}, failed)
+}
+
+// go test -v -run ^TestMessageSizeReport$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestMessageSizeReport(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 4, 2)
+
+	// each participant broadcasts its polynomial commitments
+	expected := int64(0)
+	for _, participant := range participants {
+		commitments_bytes := make([]byte, 0)
+		for _, commitment := range participant.PolynomialCommitments[participant.Position] {
+			commitments_bytes = append(commitments_bytes, commitment.SerializeCompressed()...)
+		}
+		msg_bytes, err := suite.MarshalMessage(wrapperspb.Bytes(commitments_bytes))
+		assert.NoError(t, err)
+		expected += int64(len(msg_bytes))
+	}
+	// other message types are tracked separately
+	_, err := suite.MarshalMessage(wrapperspb.String("secret shares"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, expected, suite.MessageBytes("BytesValue"))
+
+	report := new(bytes.Buffer)
+	suite.Logger = log.New(report, "", 0)
+	suite.FlushBenchmarkThreadSafeReport()
+	assert.Contains(t, report.String(), fmt.Sprintf("bytes/BytesValue %d\n", expected))
+	assert.Contains(t, report.String(), "bytes/StringValue 15\n")
+
+	// flushed totals are reset
+	assert.Equal(t, int64(0), suite.MessageBytes("BytesValue"))
+}
+
+// go test -v -run ^TestFrostDKGDryRun$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDKGDryRun(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(6)
+	threshold := int64(3)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+	}
+	coordinator := testhelper.NewFrostCoordinator(&suite, participants[0])
+
+	// count deliveries of a real run
+	commitment_messages, proof_messages, share_messages, bandwidth := int64(0), int64(0), int64(0), int64(0)
+	for i := int64(0); i < n; i++ {
+		proof := participants[i].CalculateSecretProofs([32]byte{})
+		participants[i].CalculateSecretShares()
+		for j := int64(0); j < n; j++ {
+			if i == j {
+				continue
+			}
+			participants[j].UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+			commitment_messages++
+			bandwidth += int64(len(participants[i].PolynomialCommitments[i+1])) * 33
+
+			assert.NoError(t, participants[j].VerifySecretProofs([32]byte{}, proof, i+1, participants[i].PolynomialCommitments[i+1][0]))
+			proof_messages++
+			bandwidth += int64(len(proof.Serialize()))
+
+			share := participants[i].GetSecretShares(j + 1)
+			share_messages++
+			bandwidth += int64(len(share.Bytes()))
+		}
+	}
+
+	plan, err := coordinator.DryRun(n, threshold)
+	assert.NoError(t, err)
+	assert.Equal(t, commitment_messages, plan.CommitmentMessages)
+	assert.Equal(t, proof_messages, plan.ProofMessages)
+	assert.Equal(t, share_messages, plan.ShareMessages)
+	assert.Equal(t, commitment_messages+proof_messages+share_messages, plan.TotalMessages)
+	assert.Equal(t, bandwidth, plan.BandwidthBytes)
+
+	// large configurations are planned without crypto
+	plan, err = coordinator.DryRun(1000, 700)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000*999), plan.CommitmentMessages)
+	assert.Equal(t, int64(1000*999), plan.ProofMessages)
+	assert.Equal(t, int64(1000*999), plan.ShareMessages)
+	assert.Greater(t, plan.MemoryBytes, int64(0))
+	assert.Greater(t, plan.EstimatedTime, time.Duration(0))
+
+	_, err = coordinator.DryRun(1000, 1000)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidDKGParameters)
+	_, err = coordinator.DryRun(1, 0)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidDKGParameters)
+}
+
+// go test -v -run ^TestFrostGroupKeyFromPublicShares$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostGroupKeyFromPublicShares(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(7)
+	threshold := int64(3)
+	participants, _ := runFrostDKG(&suite, n, threshold)
+
+	// any threshold + 1 subset
+	for _, subset := range [][]int64{{1, 2, 3, 4}, {2, 4, 6, 7}, {1, 3, 5, 6, 7}} {
+		public_shares := make(map[int64]*btcec.PublicKey)
+		for _, posi := range subset {
+			public_shares[posi] = participants[0].GetPublicSigningShares(posi)
+		}
+
+		group_key, err := testhelper.GroupKeyFromPublicShares(public_shares, threshold)
+		assert.NoError(t, err)
+		assert.Equal(t, participants[0].GroupPublicKey, group_key)
+	}
+
+	public_shares := map[int64]*btcec.PublicKey{
+		1: participants[0].GetPublicSigningShares(1),
+		5: participants[0].GetPublicSigningShares(5),
+		6: participants[0].GetPublicSigningShares(6),
+	}
+	_, err := testhelper.GroupKeyFromPublicShares(public_shares, threshold)
+	assert.ErrorIs(t, err, testhelper.ErrNotEnoughPublicShares)
+}
+
+// go test -v -run ^TestFrostUpdateParticipantCount$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostUpdateParticipantCount(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	threshold := int64(3)
+	participants := make([]*testhelper.FrostParticipant, 6)
+	// first 5 participants registered with a stale view of n = 5
+	for i := int64(0); i < 5; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), 5, threshold, i+1, nil)
+	}
+	participants[5] = testhelper.NewFrostParticipant(&suite, log.Default(), 6, threshold, 6, nil)
+
+	assert.ErrorIs(t, participants[0].UpdateParticipantCount(4), testhelper.ErrInvalidParticipantCount)
+	for i := int64(0); i < 5; i++ {
+		assert.NoError(t, participants[i].UpdateParticipantCount(6))
+	}
+
+	signing_shares := completeFrostDKG(&suite, participants)
+	for _, participant := range participants[1:] {
+		assert.Equal(t, participants[0].GroupPublicKey, participant.GroupPublicKey)
+	}
+
+	// the new participant signs with the group
+	honest := []int64{1, 3, 5, 6}
+	message_hash := suite.RandomMessage()
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message_hash)
+	sig := testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].GroupPublicKey))
+
+	// too late once shares are computed
+	assert.ErrorIs(t, participants[0].UpdateParticipantCount(7), testhelper.ErrSharesAlreadyComputed)
+}
+
+// go test -v -run ^TestFrostExpectedGroupKey$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostExpectedGroupKey(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	threshold := int64(2)
+	secrets := make(map[int64]*btcec.ModNScalar)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		// known constant terms
+		secrets[i+1] = new(btcec.ModNScalar).SetInt(uint32(1000 + i))
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, new(btcec.ModNScalar).Set(secrets[i+1]))
+	}
+	completeFrostDKG(&suite, participants)
+
+	expected := testhelper.ExpectedGroupKey(secrets)
+	for _, participant := range participants {
+		assert.Equal(t, expected, participant.GroupPublicKey)
+	}
+}
+
+// go test -v -run ^TestFrostExpectedPublicSigningShare$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostExpectedPublicSigningShare(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	participants, signing_shares := runFrostDKG(&suite, n, 2)
+	dealer_commitments := make(map[int64][]*btcec.JacobianPoint)
+	for i := int64(0); i < n; i++ {
+		for _, commitment := range participants[i].PolynomialCommitments[i+1] {
+			A_ij := new(btcec.JacobianPoint)
+			commitment.AsJacobian(A_ij)
+			dealer_commitments[i+1] = append(dealer_commitments[i+1], A_ij)
+		}
+	}
+
+	for index := int64(1); index <= n; index++ {
+		expected := testhelper.ExpectedPublicSigningShare(index, dealer_commitments)
+		assert.True(t, expected.IsEqual(btcec.PrivKeyFromScalar(signing_shares[index]).PubKey()), "index %d", index)
+		for _, participant := range participants {
+			assert.True(t, expected.IsEqual(participant.GetPublicSigningShares(index)), "index %d seen by %d", index, participant.Position)
+		}
+	}
+}
+
+// go test -v -run ^TestFrostDKGOutputsEquivalent$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDKGOutputsEquivalent(t *testing.T) {
+	// each run owns its suite and the injected source, nothing is shared between runs
+	run := func(seed int64) *testhelper.DKGResult {
+		suite := testhelper.TestSuite{}
+		suite.SetupStaticSimNetSuite(t, log.Default())
+		suite.SetTestRandSource(testhelper.NewTestRandSource(seed))
+		participants, _ := runFrostDKG(&suite, 5, 2)
+		return dkgResult(t, participants[0])
+	}
+
+	// a DKG on crypto/rand running alongside does not disturb the seeded runs
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		suite := testhelper.TestSuite{}
+		suite.SetupStaticSimNetSuite(t, log.Default())
+		runFrostDKG(&suite, 5, 2)
+	}()
+	result_a := run(7)
+	result_b := run(7)
+	wg.Wait()
+	assert.NotSame(t, result_a.GroupPublicKey, result_b.GroupPublicKey)
+	assert.True(t, testhelper.DKGOutputsEquivalent(result_a, result_b))
+
+	result_c := run(8)
+	assert.False(t, testhelper.DKGOutputsEquivalent(result_a, result_c))
+
+	// without an injected source two runs never agree
+	unseeded := func() *testhelper.DKGResult {
+		suite := testhelper.TestSuite{}
+		suite.SetupStaticSimNetSuite(t, log.Default())
+		participants, _ := runFrostDKG(&suite, 5, 2)
+		return dkgResult(t, participants[0])
+	}
+	assert.False(t, testhelper.DKGOutputsEquivalent(unseeded(), unseeded()))
+}
+
+// go test -v -run ^TestWritePrometheus$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestWritePrometheus(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	suite.LogBenchmarkThreadSafeReport("ms/verify-batch-public-secret-shares", float64(12), true)
+	suite.LogBenchmarkThreadSafeReport("ms/derive-external-q-w-map", int64(340), true)
+	suite.RecordMessageSize("MsgSecretShares", 320)
+	suite.RecordMessageSize("MsgSecretShares", 320)
+	suite.RecordMessageSize("MsgPolynomialCommitments", 132)
+
+	output := new(bytes.Buffer)
+	assert.NoError(t, suite.WritePrometheus(output))
+
+	// text exposition format: comments or samples
+	help_line := regexp.MustCompile(`^# HELP [a-zA-Z_:][a-zA-Z0-9_:]* .+$`)
+	type_line := regexp.MustCompile(`^# TYPE [a-zA-Z_:][a-zA-Z0-9_:]* (gauge|counter)$`)
+	sample_line := regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{type="[^"]*"\})? [0-9.eE+-]+$`)
+	samples := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n") {
+		if help_line.MatchString(line) || type_line.MatchString(line) {
+			continue
+		}
+		match := sample_line.FindStringSubmatch(line)
+		if assert.NotNil(t, match, "invalid line: %s", line) {
+			samples[match[1]+match[2]] = line[strings.LastIndex(line, " ")+1:]
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"ms_verify_batch_public_secret_shares":                 "12",
+		"ms_derive_external_q_w_map":                           "340",
+		`message_bytes_total{type="MsgSecretShares"}`:          "640",
+		`message_bytes_total{type="MsgPolynomialCommitments"}`: "132",
+	}, samples)
+
+	// non numeric values cannot be exported
+	suite.LogBenchmarkThreadSafeReport("phase", "dkg", true)
+	assert.ErrorIs(t, suite.WritePrometheus(new(bytes.Buffer)), testhelper.ErrNonNumericMetric)
+}
+
+// go test -v -run ^TestSnapshotRestoreReport$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestSnapshotRestoreReport(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	suite.LogBenchmarkThreadSafeReport("ms/derive-external-q-w-map", int64(340), true)
+	suite.RecordMessageSize("MsgSecretShares", 320)
+	snapshot := suite.SnapshotReport()
+
+	// a phase logs a new metric and more bytes
+	suite.LogBenchmarkThreadSafeReport("ms/verify-batch-public-secret-shares", float64(12), true)
+	suite.RecordMessageSize("MsgSecretShares", 320)
+	suite.RecordMessageSize("MsgPolynomialCommitments", 132)
+	diff := snapshot.Diff(suite.SnapshotReport())
+	assert.Equal(t, map[interface{}]interface{}{"ms/verify-batch-public-secret-shares": float64(12)}, diff.Metrics)
+	assert.Equal(t, map[string]int64{"MsgSecretShares": 320, "MsgPolynomialCommitments": 132}, diff.MessageBytes)
+
+	suite.RestoreReport(snapshot)
+	_, ok := suite.BenchmarkThreadSafeReport.Load("ms/verify-batch-public-secret-shares")
+	assert.False(t, ok)
+	value, ok := suite.BenchmarkThreadSafeReport.Load("ms/derive-external-q-w-map")
+	assert.True(t, ok)
+	assert.Equal(t, int64(340), value)
+	assert.Equal(t, int64(320), suite.MessageBytes("MsgSecretShares"))
+	assert.Equal(t, int64(0), suite.MessageBytes("MsgPolynomialCommitments"))
+
+	// the snapshot is not affected by later records
+	suite.RecordMessageSize("MsgSecretShares", 1)
+	assert.Equal(t, int64(320), snapshot.MessageBytes["MsgSecretShares"])
+}
+
+// go test -v -run ^TestTimedSection$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestTimedSection(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// setup around the section is not reported
+	time.Sleep(100 * time.Millisecond)
+	suite.TimedSection("section", func() {
+		time.Sleep(20 * time.Millisecond)
+	})
+	time.Sleep(100 * time.Millisecond)
+
+	value, ok := suite.BenchmarkThreadSafeReport.Load("ms/section")
+	assert.True(t, ok)
+	first := value.(float64)
+	assert.GreaterOrEqual(t, first, float64(20))
+	assert.Less(t, first, float64(100))
+
+	// sections of the same name add up
+	suite.TimedSection("section", func() {
+		time.Sleep(20 * time.Millisecond)
+	})
+	value, _ = suite.BenchmarkThreadSafeReport.Load("ms/section")
+	assert.GreaterOrEqual(t, value.(float64), first+20)
+}
+
+// go test -v -run ^TestDetectDealerIndexCollision$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestDetectDealerIndexCollision(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	threshold := int64(2)
+	participant := testhelper.NewFrostParticipant(&suite, nil, n, threshold, 1, nil)
+	dealer := testhelper.NewFrostParticipant(&suite, nil, n, threshold, 3, nil)
+	imposter := testhelper.NewFrostParticipant(&suite, nil, n, threshold, 3, nil)
+
+	// the same commitments delivered twice is not a collision
+	participant.UpdatePolynomialCommitments(3, dealer.PolynomialCommitments[3])
+	participant.UpdatePolynomialCommitments(3, dealer.PolynomialCommitments[3])
+	collisions, err := participant.DetectDealerIndexCollision()
+	assert.NoError(t, err)
+	assert.Empty(t, collisions)
+
+	// a distinct commitment list under index 3
+	participant.UpdatePolynomialCommitments(3, imposter.PolynomialCommitments[3])
+	collisions, err = participant.DetectDealerIndexCollision()
+	assert.ErrorIs(t, err, testhelper.ErrDealerIndexCollision)
+	assert.Equal(t, []int64{3}, collisions)
+}
+
+// go test -v -run ^TestWstsExpectedShareCount$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestWstsExpectedShareCount(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// participant 1 holds keys 1 - 3, participant 2 holds keys 4 - 5, participant 3 holds keys 6 - 7
+	n_p := int64(3)
+	participants := runWstsDKG(&suite, []int64{3, 2, 2}, 4)
+
+	for i := int64(0); i < n_p; i++ {
+		participant := participants[i]
+		stored := int64(0)
+		for j := range participant.Keys[i+1] {
+			stored += int64(len(participant.GetSecretSharesMap(j)))
+		}
+		assert.Equal(t, stored, participant.ExpectedShareCount(n_p))
+	}
+	assert.Equal(t, int64(9), participants[0].ExpectedShareCount(n_p))
+}
+
+// participant i holds key_counts[i - 1] consecutive keys
+// runs the wsts DKG, every participant computes all public signing shares and the group public key
+func runWstsDKG(suite *testhelper.TestSuite, key_counts []int64, threshold int64) []*testhelper.WstsParticipant {
+	n_p := int64(len(key_counts))
+	n_keys := int64(0)
+	for _, count := range key_counts {
+		n_keys += count
+	}
+
+	participants := make([]*testhelper.WstsParticipant, n_p)
+	for i := int64(0); i < n_p; i++ {
+		frost := testhelper.NewFrostParticipant(suite, nil, n_keys, threshold, i+1, nil)
+		participants[i] = testhelper.NewWSTSParticipant(suite, n_p, frost)
+	}
+	for i := int64(0); i < n_p; i++ {
+		for j := int64(0); j < n_p; j++ {
+			if i != j {
+				participants[j].Frost.UpdatePolynomialCommitments(i+1, participants[i].Frost.PolynomialCommitments[i+1])
+			}
+		}
+	}
+
+	range_keys := suite.DeriveRangeOfKeys(key_counts)
+	keys := make(map[int64]map[int64]bool)
+	for i := int64(0); i < n_p; i++ {
+		keys[i+1] = make(map[int64]bool)
+		for j := range_keys[i+1][0]; j < range_keys[i+1][1]; j++ {
+			keys[i+1][j] = true
+		}
+	}
+	for i := int64(0); i < n_p; i++ {
+		participants[i].LoadKeyRange(keys)
+		participants[i].Frost.CalculateSecretShares()
+	}
+
+	// distribute to all participants
+	for i := int64(0); i < n_p; i++ {
+		participant := participants[i]
+		for j := range participant.Keys[i+1] {
+			secrets := make(map[int64]*btcec.ModNScalar)
+			for m := int64(0); m < n_p; m++ {
+				secrets[m+1] = participants[m].Frost.GetSecretShares(j)
+			}
+			participant.StoreSecretShares(j, secrets)
+		}
+	}
+
+	for _, participant := range participants {
+		participant.CalculateSigningShares()
+		participant.CalculateInternalPublicSigningShares()
+		participant.Frost.DerivePowerMap()
+		participant.Frost.DeriveExternalQMap()
+		participant.Frost.DeriveExternalWMap()
+		participant.CalculateBatchPublicSigningShares()
+		participant.Frost.CalculateGroupPublicKey()
+	}
+
+	return participants
+}
+
+// go test -v -run ^TestAssertQWMapAgreement$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestAssertQWMapAgreement(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	assert.NoError(t, testhelper.AssertQWMapAgreement(participants))
+
+	// participant 3 receives different commitments from dealer 2
+	imposter := testhelper.NewFrostParticipant(&suite, nil, 5, 2, 2, nil)
+	participants[2].UpdatePolynomialCommitments(2, imposter.PolynomialCommitments[2])
+	err := testhelper.AssertQWMapAgreement(participants)
+	assert.ErrorIs(t, err, testhelper.ErrQWMapDivergence)
+	assert.Contains(t, err.Error(), "participant 3")
+}
+
+// go test -v -run ^TestDerivePowerMapForSet$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestDerivePowerMapForSet(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(7)
+	threshold := int64(3)
+	participants, _ := runFrostDKG(&suite, n, threshold)
+
+	// a fresh view of the same commitments, only a threshold subset signs
+	signers := map[int64]bool{2: true, 4: true, 5: true, 7: true}
+	sparse := testhelper.NewFrostParticipant(&suite, nil, n, threshold, 1, nil)
+	for posi := int64(1); posi <= n; posi++ {
+		sparse.UpdatePolynomialCommitments(posi, participants[posi-1].PolynomialCommitments[posi])
+	}
+	sparse.DerivePowerMapForSet(signers)
+	sparse.DeriveExternalQMap()
+
+	for posi := range signers {
+		assert.Equal(t, participants[0].GetPowerMapItem(posi), sparse.GetPowerMapItem(posi))
+
+		// Y_i = \prod_{j=0}^{t} Q_j^i^j
+		Q_j_arr := sparse.GetQMapItem(posi)
+		i_power_arr := sparse.GetPowerMapItem(posi)
+		Y := new(btcec.JacobianPoint)
+		for j := int64(0); j <= threshold; j++ {
+			term := new(btcec.JacobianPoint)
+			btcec.ScalarMultNonConst(i_power_arr[j], Q_j_arr[j], term)
+			btcec.AddNonConst(Y, term, Y)
+		}
+		Y.ToAffine()
+		assert.True(t, participants[0].GetPublicSigningShares(posi).IsEqual(btcec.NewPublicKey(&Y.X, &Y.Y)))
+	}
+}
+
+// go test -v -run ^TestFrostCoordinatorConfigHash$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostCoordinatorConfigHash(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// nodes with different positions and secrets share the same config
+	node_1 := testhelper.NewFrostCoordinator(&suite, testhelper.NewFrostParticipant(&suite, nil, 5, 3, 1, nil))
+	node_2 := testhelper.NewFrostCoordinator(&suite, testhelper.NewFrostParticipant(&suite, nil, 5, 3, 4, nil))
+	assert.Equal(t, node_1.ConfigHash(), node_2.ConfigHash())
+
+	// positions are sorted before hashing
+	node_2.Positions = []int64{5, 4, 3, 2, 1}
+	assert.Equal(t, node_1.ConfigHash(), node_2.ConfigHash())
+
+	other_threshold := testhelper.NewFrostCoordinator(&suite, testhelper.NewFrostParticipant(&suite, nil, 5, 2, 1, nil))
+	assert.NotEqual(t, node_1.ConfigHash(), other_threshold.ConfigHash())
+
+	node_2.Positions = []int64{1, 2, 3, 4, 6}
+	assert.NotEqual(t, node_1.ConfigHash(), node_2.ConfigHash())
+}
+
+// go test -v -run ^TestParsePointStrictDecode$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestParsePointStrictDecode(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participant := testhelper.NewFrostParticipant(&suite, nil, 3, 1, 1, nil)
+	commitment := participant.PolynomialCommitments[1][0]
+	compressed := commitment.SerializeCompressed()
+	uncompressed := commitment.SerializeUncompressed()
+	// hybrid encoding carries the parity of Y in the prefix
+	hybrid := append([]byte{}, uncompressed...)
+	hybrid[0] = 0x06 | (compressed[0] & 0x01)
+	overlong := append(append([]byte{}, compressed...), 0x00)
+
+	// lenient mode behaves as before
+	for _, encoding := range [][]byte{compressed, uncompressed, hybrid} {
+		point, err := suite.ParsePoint(encoding)
+		assert.NoError(t, err)
+		assert.True(t, point.IsEqual(commitment))
+	}
+	_, err := suite.ParsePoint(overlong)
+	assert.Error(t, err)
+
+	suite.StrictDecode = true
+	point, err := suite.ParsePoint(compressed)
+	assert.NoError(t, err)
+	assert.True(t, point.IsEqual(commitment))
+	for _, encoding := range [][]byte{uncompressed, hybrid, overlong, compressed[:32]} {
+		_, err := suite.ParsePoint(encoding)
+		assert.ErrorIs(t, err, testhelper.ErrNonCanonicalPoint)
+	}
+}
+
+// go test -v -run ^TestDeriveWeightedRangeOfKeys$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestDeriveWeightedRangeOfKeys(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	weights := map[int64]int64{1: 300, 2: 50, 3: 1, 4: 149}
+	range_keys, err := suite.DeriveWeightedRangeOfKeys(500, weights)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64][2]int64{
+		1: {1, 301},
+		2: {301, 351},
+		3: {351, 352},
+		4: {352, 501},
+	}, range_keys)
+
+	// every key from 1 to n_keys is owned once
+	owners := make(map[int64]int64)
+	for posi, key_range := range range_keys {
+		assert.Equal(t, weights[posi], key_range[1]-key_range[0])
+		for key := key_range[0]; key < key_range[1]; key++ {
+			_, taken := owners[key]
+			assert.False(t, taken, "key %d owned twice", key)
+			owners[key] = posi
+		}
+	}
+	assert.Len(t, owners, 500)
+
+	_, err = suite.DeriveWeightedRangeOfKeys(499, weights)
+	assert.ErrorIs(t, err, testhelper.ErrWeightTotalMismatch)
+	_, err = suite.DeriveWeightedRangeOfKeys(500, map[int64]int64{1: 500, 2: 0})
+	assert.ErrorIs(t, err, testhelper.ErrZeroWeight)
+	_, err = suite.DeriveWeightedRangeOfKeys(500, map[int64]int64{1: 250, 3: 250})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidWeightPosition)
+}
+
+// go test -v -run ^TestFrostReconstructSecret$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostReconstructSecret(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	shares := map[int64]*btcec.ModNScalar{1: signing_shares[1], 3: signing_shares[3], 5: signing_shares[5]}
+	secret, err := participants[0].ReconstructSecret(shares)
+	assert.NoError(t, err)
+
+	// the secret derives the group public key
+	scalar := secret.Scalar()
+	Y := new(btcec.JacobianPoint)
+	btcec.ScalarBaseMultNonConst(scalar, Y)
+	Y.ToAffine()
+	assert.True(t, btcec.NewPublicKey(&Y.X, &Y.Y).IsEqual(participants[0].GroupPublicKey))
+	assert.False(t, scalar.IsZero())
+
+	assert.NoError(t, secret.Release())
+	assert.True(t, scalar.IsZero())
+	assert.Nil(t, secret.Scalar())
+	assert.False(t, secret.Locked())
+	assert.ErrorIs(t, secret.Release(), testhelper.ErrSecretReleased)
+
+	_, err = participants[0].ReconstructSecret(map[int64]*btcec.ModNScalar{1: signing_shares[1], 3: signing_shares[3]})
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+	shares[3] = signing_shares[4]
+	_, err = participants[0].ReconstructSecret(shares)
+	assert.ErrorIs(t, err, testhelper.ErrReconstructedKeyWrong)
+}
+
+// go test -v -run ^TestDeriveRangeOfKeysSeeded$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestDeriveRangeOfKeysSeeded(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	keys := []int64{5, 1, 3, 7}
+	n_keys := int64(16)
+
+	range_keys := suite.DeriveRangeOfKeysSeeded(keys, 42)
+	assert.Equal(t, range_keys, suite.DeriveRangeOfKeysSeeded(keys, 42))
+	assert.NotEqual(t, range_keys, suite.DeriveRangeOfKeysSeeded(keys, 43))
+
+	// disjoint and complete
+	owners := make(map[int64]int64)
+	for posi, owned := range range_keys {
+		assert.Len(t, owned, int(keys[posi-1]))
+		for key := range owned {
+			_, taken := owners[key]
+			assert.False(t, taken, "key %d owned twice", key)
+			owners[key] = posi
+		}
+	}
+	assert.Len(t, owners, int(n_keys))
+	for key := int64(1); key <= n_keys; key++ {
+		assert.Contains(t, owners, key)
+	}
+}
+
+// go test -v -run ^TestSecuritySummary$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestSecuritySummary(t *testing.T) {
+	test_suite := []struct {
+		n         int64
+		threshold int64
+		expected  testhelper.SecurityLevel
+	}{
+		{
+			n:         3,
+			threshold: 1,
+			expected:  testhelper.SecurityLevel{N: 3, Threshold: 1, CollusionToForge: 2, MaxOffline: 1, MajorityHonest: true},
+		},
+		{
+			n:         100,
+			threshold: 70,
+			expected:  testhelper.SecurityLevel{N: 100, Threshold: 70, CollusionToForge: 71, MaxOffline: 29, MajorityHonest: true},
+		},
+		{
+			n:         10,
+			threshold: 4,
+			expected:  testhelper.SecurityLevel{N: 10, Threshold: 4, CollusionToForge: 5, MaxOffline: 5, MajorityHonest: false},
+		},
+		{
+			n:         7,
+			threshold: 6,
+			expected:  testhelper.SecurityLevel{N: 7, Threshold: 6, CollusionToForge: 7, MaxOffline: 0, MajorityHonest: true},
+		},
+	}
+
+	for _, test := range test_suite {
+		assert.Equal(t, test.expected, testhelper.SecuritySummary(test.n, test.threshold))
+	}
+}
+
+// go test -v -run ^TestFrostSubGroupKey$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSubGroupKey(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 6, 3)
+	dealers := map[int64]bool{2: true, 4: true, 6: true}
+
+	expected := new(btcec.JacobianPoint)
+	for posi := range dealers {
+		A_0 := new(btcec.JacobianPoint)
+		participants[posi-1].PolynomialCommitments[posi][0].AsJacobian(A_0)
+		btcec.AddNonConst(expected, A_0, expected)
+	}
+	expected.ToAffine()
+
+	sub_key := participants[0].SubGroupKey(dealers)
+	assert.True(t, sub_key.IsEqual(btcec.NewPublicKey(&expected.X, &expected.Y)))
+	assert.True(t, sub_key.IsEqual(participants[4].SubGroupKey(dealers)))
+	assert.False(t, sub_key.IsEqual(participants[0].GroupPublicKey))
+
+	// the sub group keys of complementary halves sum to the group public key
+	complement := participants[0].SubGroupKey(map[int64]bool{1: true, 3: true, 5: true})
+	Y := new(btcec.JacobianPoint)
+	sub_key.AsJacobian(Y)
+	C := new(btcec.JacobianPoint)
+	complement.AsJacobian(C)
+	btcec.AddNonConst(Y, C, Y)
+	Y.ToAffine()
+	assert.True(t, participants[0].GroupPublicKey.IsEqual(btcec.NewPublicKey(&Y.X, &Y.Y)))
+
+	assert.Nil(t, participants[0].SubGroupKey(map[int64]bool{}))
+}
+
+// go test -v -run ^TestFrostSharePEM$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSharePEM(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 3, 1)
+	passphrase := []byte("correct horse battery staple")
+
+	pem_bytes, err := participants[1].ExportSharePEM(passphrase)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(pem_bytes), "-----BEGIN "+testhelper.SharePEMType+"-----"))
+
+	position, share, err := testhelper.ImportSharePEM(pem_bytes, passphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), position)
+	assert.True(t, share.Equals(signing_shares[2]))
+
+	_, _, err = testhelper.ImportSharePEM(pem_bytes, []byte("wrong passphrase"))
+	assert.ErrorIs(t, err, testhelper.ErrWrongPassphrase)
+	_, _, err = testhelper.ImportSharePEM([]byte("not a pem block"), passphrase)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidSharePEM)
+
+	// iteration counts out of bounds are rejected before any key derivation
+	for _, iterations := range []string{"0", "1", "99999", "10000001", "2147483647"} {
+		tampered := strings.Replace(string(pem_bytes), "Iterations: 100000", "Iterations: "+iterations, 1)
+		_, _, err = testhelper.ImportSharePEM([]byte(tampered), passphrase)
+		assert.ErrorIs(t, err, testhelper.ErrInvalidSharePEM, iterations)
+	}
+
+	// salt and nonce are fresh for every export
+	other_bytes, err := participants[1].ExportSharePEM(passphrase)
+	assert.NoError(t, err)
+	assert.NotEqual(t, pem_bytes, other_bytes)
+
+	// no signing share to export
+	fresh := testhelper.NewFrostParticipant(&suite, log.Default(), 3, 1, 1, nil)
+	_, err = fresh.ExportSharePEM(passphrase)
+	assert.ErrorIs(t, err, testhelper.ErrMissingSigningShare)
+}
+
+// go test -v -run ^TestFrostExportToHWIFormat$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostExportToHWIFormat(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	participant := participants[2]
+	device_key, err := btcec.NewPrivateKey()
+	assert.NoError(t, err)
+
+	_, err = participant.ExportToHWIFormat()
+	assert.ErrorIs(t, err, testhelper.ErrMissingHWIEncryptionKey)
+
+	participant.SetHWIEncryptionKey(device_key.PubKey())
+	blob, err := participant.ExportToHWIFormat()
+	assert.NoError(t, err)
+	assert.Len(t, blob, 123+5*33)
+
+	export, err := testhelper.ParseHWIFormat(blob)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), export.Position)
+	assert.Equal(t, int64(2), export.Threshold)
+	assert.True(t, export.GroupPublicKey.IsEqual(participant.GroupPublicKey))
+	assert.Len(t, export.PublicSigningShares, 5)
+	for posi := int64(1); posi <= 5; posi++ {
+		assert.True(t, export.PublicSigningShares[posi].IsEqual(participant.GetPublicSigningShares(posi)))
+	}
+
+	share, err := export.DecryptShare(device_key)
+	assert.NoError(t, err)
+	assert.True(t, share.Equals(signing_shares[3]))
+
+	// another device cannot decrypt
+	other_key, err := btcec.NewPrivateKey()
+	assert.NoError(t, err)
+	_, err = export.DecryptShare(other_key)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidHWIShare)
+
+	// a flipped bit in the masked share is caught on import
+	corrupted := append([]byte{}, blob...)
+	corrupted[100] ^= 1
+	export, err = testhelper.ParseHWIFormat(corrupted)
+	assert.NoError(t, err)
+	_, err = export.DecryptShare(device_key)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidHWIShare)
+
+	_, err = testhelper.ParseHWIFormat(blob[:len(blob)-1])
+	assert.ErrorIs(t, err, testhelper.ErrInvalidHWIFormat)
+	_, err = testhelper.ParseHWIFormat(append([]byte{0x02}, blob[1:]...))
+	assert.ErrorIs(t, err, testhelper.ErrInvalidHWIFormat)
+
+	// every public signing share is exported
+	participant.PublicSigningShares.Delete(int64(5))
+	_, err = participant.ExportToHWIFormat()
+	assert.ErrorIs(t, err, testhelper.ErrMissingPublicSigningShare)
+}
+
+// go test -race -v -run ^TestRunDKGConcurrent$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestRunDKGConcurrent(t *testing.T) {
+	result, err := testhelper.RunDKGConcurrent(10, 6)
+	assert.NoError(t, err)
+	assert.NotNil(t, result.GroupPublicKey)
+	assert.Len(t, result.PublicSigningShares, 10)
+
+	// the group public key is interpolated back from any threshold + 1 public signing shares
+	group_key, err := testhelper.GroupKeyFromPublicShares(result.PublicSigningShares, 6)
+	assert.NoError(t, err)
+	assert.True(t, group_key.IsEqual(result.GroupPublicKey))
+
+	_, err = testhelper.RunDKGConcurrent(3, 3)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidConcurrentParams)
+}
+
+// go test -v -run ^TestFrostVerifySecretProofsError$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostVerifySecretProofsError(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	participants := make([]*testhelper.FrostParticipant, n)
+	proofs := make(map[int64]*schnorr.Signature)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 2, i+1, nil)
+		proofs[i+1] = participants[i].CalculateSecretProofs([32]byte{})
+	}
+	// dealer 3 replays the proof of dealer 2, dealer 5 proves under another context
+	proofs[3] = proofs[2]
+	proofs[5] = participants[4].CalculateSecretProofs(sha256.Sum256([]byte("other context")))
+
+	// the coordinator collects failures from every verifier and keeps the honest dealers
+	dishonest := make(map[int64]bool)
+	for j := int64(0); j < n; j++ {
+		for i := int64(0); i < n; i++ {
+			err := participants[j].VerifySecretProofs([32]byte{}, proofs[i+1], i+1, participants[i].PolynomialCommitments[i+1][0])
+			if err != nil {
+				assert.ErrorIs(t, err, testhelper.ErrInvalidSecretProof)
+				dishonest[i+1] = true
+			}
+		}
+	}
+	assert.Equal(t, map[int64]bool{3: true, 5: true}, dishonest)
+}
+
+// go test -v -run ^TestCurveOpCounts$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestCurveOpCounts(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(3)
+	threshold := int64(1)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+	}
+	for i := int64(0); i < n; i++ {
+		for j := int64(0); j < n; j++ {
+			if i != j {
+				participants[j].UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+			}
+		}
+	}
+	for i := int64(0); i < n; i++ {
+		proof := participants[i].CalculateSecretProofs([32]byte{})
+		for j := int64(0); j < n; j++ {
+			if i != j {
+				assert.NoError(t, participants[j].VerifySecretProofs([32]byte{}, proof, i+1, participants[i].PolynomialCommitments[i+1][0]))
+			}
+		}
+		participants[i].CalculateSecretShares()
+	}
+	for j := int64(0); j < n; j++ {
+		for i := int64(0); i < n; i++ {
+			participants[j].VerifyPublicSecretShares(participants[i].GetSecretShares(j+1), i+1, uint32(j+1))
+		}
+		participants[j].CalculateGroupPublicKey()
+	}
+
+	// commitments A_ik = a_ik * G: n * (t + 1) base mults
+	// secret proof R = k * G and its self verification: 2 base mults, 1 mult, 1 add per dealer
+	// proof verification g^\mu * A_i0^-c: n * (n - 1) * (1 base mult, 1 mult, 1 add)
+	// share verification g^f(j) against \prod A_ik^j^k: n * n * (1 base mult, t + 1 mults, t + 1 adds)
+	// group public key \sum A_i0: n adds per participant
+	expected := testhelper.CurveOpCounts{
+		BaseMults:   n*(threshold+1) + 2*n + n*(n-1) + n*n,
+		ScalarMults: n + n*(n-1) + n*n*(threshold+1),
+		Adds:        n + n*(n-1) + n*n*(threshold+1) + n*n,
+	}
+	assert.Equal(t, expected, suite.CurveOpCounts())
+	assert.Equal(t, testhelper.CurveOpCounts{BaseMults: 27, ScalarMults: 27, Adds: 36}, suite.CurveOpCounts())
+
+	suite.ResetCurveOpCounts()
+	assert.Equal(t, testhelper.CurveOpCounts{}, suite.CurveOpCounts())
+}
+
+// go test -v -run ^TestVerifyBatchPublicSecretSharesBlame$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestVerifyBatchPublicSecretSharesBlame(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(7)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 3, i+1, nil)
+	}
+	receiver := participants[1]
+	secret_shares := make(map[int64]*btcec.ModNScalar)
+	for i := int64(0); i < n; i++ {
+		if i != 1 {
+			receiver.UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+		}
+		participants[i].CalculateSecretShares()
+		secret_shares[i+1] = participants[i].GetSecretShares(2)
+	}
+	receiver.DerivePowerMap()
+
+	bad_dealers, err := receiver.VerifyBatchPublicSecretShares(secret_shares, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, bad_dealers)
+
+	// dealer 5 corrupts the share sent to participant 2
+	secret_shares[5] = new(btcec.ModNScalar).Set(secret_shares[5]).Add(new(btcec.ModNScalar).SetInt(1))
+	suite.ResetCurveOpCounts()
+	bad_dealers, err = receiver.VerifyBatchPublicSecretShares(secret_shares, 2)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidSecretShare)
+	assert.Equal(t, []int64{5}, bad_dealers)
+	// the fallback only bisects the failing subsets, at most one base mult per share
+	assert.LessOrEqual(t, suite.CurveOpCounts().BaseMults, n)
+
+	// a missing share is blamed as well
+	delete(secret_shares, 7)
+	bad_dealers, err = receiver.VerifyBatchPublicSecretShares(secret_shares, 2)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidSecretShare)
+	assert.Equal(t, []int64{5, 7}, bad_dealers)
+}
+
+// go test -v -run ^TestFrostDKGContextCancellation$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDKGContextCancellation(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// n * t polynomial evaluations run far longer than the deadline
+	participant := testhelper.NewFrostParticipant(&suite, log.Default(), 3000, 2000, 1, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := participant.CalculateSecretSharesCtx(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 250*time.Millisecond)
+	assert.Nil(t, participant.AllSecretShares())
+
+	// cancelled before the work starts
+	participants := make([]*testhelper.FrostParticipant, 4)
+	for i := range participants {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), 4, 2, int64(i+1), nil)
+	}
+	receiver := participants[1]
+	secret_shares := make(map[int64]*btcec.ModNScalar)
+	for i, participant := range participants {
+		if i != 1 {
+			receiver.UpdatePolynomialCommitments(int64(i+1), participant.PolynomialCommitments[int64(i+1)])
+		}
+		participant.CalculateSecretShares()
+		secret_shares[int64(i+1)] = participant.GetSecretShares(2)
+	}
+	cancelled, cancel_now := context.WithCancel(context.Background())
+	cancel_now()
+	assert.ErrorIs(t, receiver.DerivePowerMapCtx(cancelled), context.Canceled)
+	assert.NoError(t, receiver.DerivePowerMapCtx(context.Background()))
+
+	bad_dealers, err := receiver.VerifyBatchPublicSecretSharesCtx(cancelled, secret_shares, 2)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, bad_dealers)
+	bad_dealers, err = receiver.VerifyBatchPublicSecretSharesCtx(context.Background(), secret_shares, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, bad_dealers)
+}
+
+// go test -v -run ^TestFrostDealerContribution$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDealerContribution(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	auditor := participants[2]
+
+	// Y = \sum_{i} A_i0
+	Y := new(btcec.JacobianPoint)
+	for dealer := int64(1); dealer <= 5; dealer++ {
+		contribution := auditor.DealerContribution(dealer)
+		assert.NotNil(t, contribution)
+		assert.True(t, contribution.IsEqual(participants[dealer-1].PolynomialCommitments[dealer][0]))
+
+		A_0 := new(btcec.JacobianPoint)
+		contribution.AsJacobian(A_0)
+		btcec.AddNonConst(Y, A_0, Y)
+	}
+	Y.ToAffine()
+	assert.True(t, btcec.NewPublicKey(&Y.X, &Y.Y).IsEqual(auditor.CalculateGroupPublicKey()))
+
+	// an excluded dealer no longer contributes
+	auditor.ExcludeDealer(4)
+	assert.Nil(t, auditor.DealerContribution(4))
+	assert.Nil(t, auditor.DealerContribution(6))
+}
+
+// go test -v -run ^TestNewFrostParticipantChecked$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestNewFrostParticipantChecked(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participant, err := testhelper.NewFrostParticipantChecked(&suite, log.Default(), 5, 2, 3, nil)
+	assert.NoError(t, err)
+	assert.Len(t, participant.PolynomialCommitments[3], 3)
+
+	tests := []struct {
+		name      string
+		n         int64
+		threshold int64
+		posi      int64
+	}{
+		{"threshold 0", 5, 0, 1},
+		{"threshold above n", 5, 6, 1},
+		{"threshold n", 5, 5, 1},
+		{"position 0", 5, 2, 0},
+		{"position above n", 5, 2, 6},
+		{"no participants", 0, 0, 1},
+	}
+	for _, test := range tests {
+		participant, err := testhelper.NewFrostParticipantChecked(&suite, log.Default(), test.n, test.threshold, test.posi, nil)
+		assert.ErrorIs(t, err, testhelper.ErrInvalidFrostParams, test.name)
+		assert.Nil(t, participant, test.name)
+	}
+	_, err = testhelper.NewFrostParticipantChecked(&suite, log.Default(), 5, 2, 6, nil)
+	assert.ErrorIs(t, err, testhelper.ErrIndexOutOfRange)
+}
+
+// go test -v -run ^TestFrostDKGTranscript$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDKGTranscript(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(4)
+	participants, _ := runFrostDKG(&suite, n, 2)
+	context_hash := sha256.Sum256([]byte("dkg transcript"))
+	secret_proofs := make(map[int64]*schnorr.Signature)
+	for i := int64(0); i < n; i++ {
+		secret_proofs[i+1] = participants[i].CalculateSecretProofs(context_hash)
+	}
+	recorder := participants[1]
+	recorder.CalculateGroupPublicKey()
+	transcript, err := recorder.RecordTranscript(context_hash, secret_proofs)
+	assert.NoError(t, err)
+
+	// the auditor only sees the json
+	transcript_json, err := json.Marshal(transcript)
+	assert.NoError(t, err)
+	audited := new(testhelper.Transcript)
+	assert.NoError(t, json.Unmarshal(transcript_json, audited))
+	assert.Equal(t, context_hash, audited.ContextHash)
+	assert.True(t, audited.GroupPublicKey.IsEqual(recorder.GroupPublicKey))
+	auditor := testhelper.TestSuite{}
+	auditor.SetupStaticSimNetSuite(t, log.Default())
+	assert.NoError(t, testhelper.VerifyTranscript(&auditor, audited))
+
+	// proofs are bound to the dealer and the context
+	audited.SecretProofs[1], audited.SecretProofs[2] = audited.SecretProofs[2], audited.SecretProofs[1]
+	assert.ErrorIs(t, testhelper.VerifyTranscript(&auditor, audited), testhelper.ErrInvalidSecretProof)
+	assert.NoError(t, json.Unmarshal(transcript_json, audited))
+	audited.ContextHash = [32]byte{}
+	assert.ErrorIs(t, testhelper.VerifyTranscript(&auditor, audited), testhelper.ErrInvalidSecretProof)
+
+	// a group key other than \sum_{i} A_i0
+	assert.NoError(t, json.Unmarshal(transcript_json, audited))
+	audited.GroupPublicKey = audited.Commitments[1][0]
+	assert.ErrorIs(t, testhelper.VerifyTranscript(&auditor, audited), testhelper.ErrTranscriptGroupKey)
+
+	// a dealer committing to a polynomial of lower degree
+	assert.NoError(t, json.Unmarshal(transcript_json, audited))
+	audited.Commitments[3] = audited.Commitments[3][:2]
+	assert.ErrorIs(t, testhelper.VerifyTranscript(&auditor, audited), testhelper.ErrInvalidTranscript)
+
+	// malformed encodings are rejected before verification
+	tampered := strings.Replace(string(transcript_json), `"proof_s":"`, `"proof_s":"zz`, 1)
+	assert.ErrorIs(t, json.Unmarshal([]byte(tampered), audited), testhelper.ErrInvalidTranscriptEncoding)
+
+	delete(secret_proofs, 3)
+	_, err = recorder.RecordTranscript(context_hash, secret_proofs)
+	assert.ErrorIs(t, err, testhelper.ErrMissingSecretProof)
+}
+
+// go test -v -run ^TestVerifyBatchPublicSecretSharesSingleMSM$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestVerifyBatchPublicSecretSharesSingleMSM(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(7)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 3, i+1, nil)
+	}
+	receiver := participants[1]
+	receiver.SetBatchVerifyStrategy(testhelper.BatchVerifyMSM)
+	secret_shares := make(map[int64]*btcec.ModNScalar)
+	for i := int64(0); i < n; i++ {
+		if i != 1 {
+			receiver.UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+		}
+		participants[i].CalculateSecretShares()
+		secret_shares[i+1] = participants[i].GetSecretShares(2)
+	}
+	receiver.DerivePowerMap()
+
+	// a valid batch costs a single multi scalar multiplication and a single base mult
+	suite.ResetCurveOpCounts()
+	bad_dealers, err := receiver.VerifyBatchPublicSecretShares(secret_shares, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, bad_dealers)
+	assert.Equal(t, int64(1), suite.CurveOpCounts().BaseMults)
+
+	// any single bad share is caught and blamed
+	for dealer := int64(1); dealer <= n; dealer++ {
+		valid := secret_shares[dealer]
+		secret_shares[dealer] = new(btcec.ModNScalar).Set(valid).Add(new(btcec.ModNScalar).SetInt(1))
+		bad_dealers, err = receiver.VerifyBatchPublicSecretShares(secret_shares, 2)
+		assert.ErrorIs(t, err, testhelper.ErrInvalidSecretShare)
+		assert.Equal(t, []int64{dealer}, bad_dealers)
+		secret_shares[dealer] = valid
+	}
+
+	// errors that cancel out without weights, s_3 + 1 and s_4 - 1, are caught as well
+	secret_shares[3] = new(btcec.ModNScalar).Set(secret_shares[3]).Add(new(btcec.ModNScalar).SetInt(1))
+	secret_shares[4] = new(btcec.ModNScalar).Set(secret_shares[4]).Add(new(btcec.ModNScalar).SetInt(1).Negate())
+	bad_dealers, err = receiver.VerifyBatchPublicSecretShares(secret_shares, 2)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidSecretShare)
+	assert.Equal(t, []int64{3, 4}, bad_dealers)
+}
+
+// go test -v -run ^TestFrostBelongsToGroup$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostBelongsToGroup(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	other_group, _ := runFrostDKG(&suite, 5, 2)
+	group_key := participants[0].GroupPublicKey
+
+	// participant 3 restored from its persisted share and the public commitments of the group
+	pem_bytes, err := participants[2].ExportSharePEM([]byte("passphrase"))
+	assert.NoError(t, err)
+	position, share, err := testhelper.ImportSharePEM(pem_bytes, []byte("passphrase"))
+	assert.NoError(t, err)
+	restored := testhelper.NewFrostParticipant(&suite, log.Default(), 5, 2, position, nil)
+	for posi, commitments := range participants[2].PolynomialCommitments {
+		restored.UpdatePolynomialCommitments(posi, commitments)
+	}
+	restored.StoreSigningShares(share)
+	restored.CalculateGroupPublicKey()
+
+	assert.True(t, restored.BelongsToGroup(group_key))
+	assert.False(t, restored.BelongsToGroup(other_group[0].GroupPublicKey))
+	assert.False(t, restored.BelongsToGroup(nil))
+
+	// a tampered group public key does not match the commitments
+	restored.GroupPublicKey = other_group[0].GroupPublicKey
+	assert.False(t, restored.BelongsToGroup(other_group[0].GroupPublicKey))
+	assert.False(t, restored.BelongsToGroup(group_key))
+}
+
+// go test -v -run ^TestMarshalPolynomialCommitments$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestMarshalPolynomialCommitments(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(1000)
+	threshold := int64(700)
+	dealer := testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, 42, nil)
+	receiver := testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, 1, nil)
+
+	data, err := dealer.MarshalPolynomialCommitments()
+	assert.NoError(t, err)
+	assert.Len(t, data, 8+4+int(threshold+1)*33)
+
+	assert.NoError(t, receiver.UnmarshalPolynomialCommitments(data))
+	assert.Len(t, receiver.PolynomialCommitments[42], int(threshold+1))
+	for j, commitment := range dealer.PolynomialCommitments[42] {
+		assert.True(t, commitment.IsEqual(receiver.PolynomialCommitments[42][j]), "coefficient %d", j)
+	}
+
+	// truncated
+	assert.ErrorIs(t, receiver.UnmarshalPolynomialCommitments(data[:len(data)-1]), testhelper.ErrMalformedCommitments)
+	assert.ErrorIs(t, receiver.UnmarshalPolynomialCommitments(data[:10]), testhelper.ErrMalformedCommitments)
+	// wrong number of coefficients for the threshold
+	other := testhelper.NewFrostParticipant(&suite, log.Default(), n, 2, 3, nil)
+	other_data, err := other.MarshalPolynomialCommitments()
+	assert.NoError(t, err)
+	assert.ErrorIs(t, receiver.UnmarshalPolynomialCommitments(other_data), testhelper.ErrMalformedCommitments)
+	// x coordinate not on the curve, x = 5 has no square root y^2 = x^3 + 7
+	off_curve := append([]byte{}, data...)
+	copy(off_curve[12+33:12+66], append([]byte{0x02}, make([]byte, 32)...))
+	off_curve[12+65] = 5
+	assert.ErrorIs(t, receiver.UnmarshalPolynomialCommitments(off_curve), testhelper.ErrMalformedCommitments)
+	// uncompressed prefix
+	bad_prefix := append([]byte{}, data...)
+	bad_prefix[12] = 0x04
+	assert.ErrorIs(t, receiver.UnmarshalPolynomialCommitments(bad_prefix), testhelper.ErrMalformedCommitments)
+}
+
+// go test -v -run ^TestFrostRunDKGOverTransport$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostRunDKGOverTransport(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(10)
+	threshold := int64(6)
+	context_hash := sha256.Sum256([]byte("dkg over transport"))
+	transports := testhelper.NewChannelTransports(n)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := int64(0); i < n; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			errs[i] = participants[i].RunDKG(transports[i], context_hash)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := int64(0); i < n; i++ {
+		assert.NoError(t, errs[i], "participant %d", i+1)
+		assert.True(t, participants[i].GroupPublicKey.IsEqual(participants[0].GroupPublicKey))
+		assert.True(t, testhelper.DKGOutputsEquivalent(dkgResult(t, participants[0]), dkgResult(t, participants[i])))
+	}
+	group_key, err := testhelper.GroupKeyFromPublicShares(dkgResult(t, participants[0]).PublicSigningShares, threshold)
+	assert.NoError(t, err)
+	assert.True(t, group_key.IsEqual(participants[0].GroupPublicKey))
+
+	// a message from outside the group is refused
+	transports = testhelper.NewChannelTransports(2)
+	participant := testhelper.NewFrostParticipant(&suite, log.Default(), 2, 1, 1, nil)
+	assert.NoError(t, transports[1].Broadcast(5, testhelper.DKGMessage{Type: testhelper.DKGMessageCommitments}))
+	assert.ErrorIs(t, participant.RunDKG(transports[0], context_hash), testhelper.ErrInvalidDKGMsgSender)
+
+	// every message is retried, the DKG completes as if each was sent once
+	n = 4
+	transports = testhelper.NewChannelTransports(n)
+	participants = make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 2, i+1, nil)
+	}
+	errs = make([]error, n)
+	for i := int64(0); i < n; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			errs[i] = participants[i].RunDKG(&retryingTransport{Transport: transports[i]}, context_hash)
+		}(i)
+	}
+	wg.Wait()
+	for i := int64(0); i < n; i++ {
+		assert.NoError(t, errs[i], "participant %d", i+1)
+		assert.True(t, participants[i].GroupPublicKey.IsEqual(participants[0].GroupPublicKey))
+	}
+
+	// commitments claiming another dealer are refused before they are stored
+	transports = testhelper.NewChannelTransports(3)
+	dealer := testhelper.NewFrostParticipant(&suite, log.Default(), 3, 1, 3, nil)
+	payload, err := dealer.MarshalPolynomialCommitments()
+	assert.NoError(t, err)
+	assert.NoError(t, transports[1].Broadcast(2, testhelper.DKGMessage{Type: testhelper.DKGMessageCommitments, Payload: payload}))
+	participant = testhelper.NewFrostParticipant(&suite, log.Default(), 3, 1, 1, nil)
+	assert.ErrorIs(t, participant.RunDKG(transports[0], context_hash), testhelper.ErrUnexpectedDKGMsg)
+	_, ok := participant.GetPolynomialCommitments(3)
+	assert.False(t, ok)
+
+	// a second, different commitment list of the same dealer is refused
+	transports = testhelper.NewChannelTransports(3)
+	other := testhelper.NewFrostParticipant(&suite, log.Default(), 3, 1, 3, nil)
+	other_payload, err := other.MarshalPolynomialCommitments()
+	assert.NoError(t, err)
+	assert.NoError(t, transports[2].Broadcast(3, testhelper.DKGMessage{Type: testhelper.DKGMessageCommitments, Payload: payload, Proof: dealer.CalculateSecretProofs(context_hash).Serialize()}))
+	assert.NoError(t, transports[2].Broadcast(3, testhelper.DKGMessage{Type: testhelper.DKGMessageCommitments, Payload: other_payload, Proof: other.CalculateSecretProofs(context_hash).Serialize()}))
+	participant = testhelper.NewFrostParticipant(&suite, log.Default(), 3, 1, 1, nil)
+	assert.ErrorIs(t, participant.RunDKG(transports[0], context_hash), testhelper.ErrUnexpectedDKGMsg)
+}
+
+// go test -v -run ^TestDKGRoundCount$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestDKGRoundCount(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	context_hash := sha256.Sum256([]byte("dkg round count"))
+	transports := testhelper.NewChannelTransports(n)
+	participants := make([]*testhelper.FrostParticipant, n)
+	counters := make([]*phaseCountingTransport, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 2, i+1, nil)
+		counters[i] = &phaseCountingTransport{Transport: transports[i]}
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := int64(0); i < n; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			errs[i] = participants[i].RunDKG(counters[i], context_hash)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := int64(0); i < n; i++ {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, testhelper.DKGRoundCount(false), counters[i].phases, "participant %d", i+1)
+	}
+	assert.Equal(t, testhelper.DKGRoundCount(false), groupRounds(counters))
+
+	// 1 accuses dealer 2, which answers with the share it dealt
+	accused := int64(2)
+	assert.NoError(t, counters[0].Broadcast(1, testhelper.DKGMessage{Type: testhelper.DKGMessageComplaint, Payload: testhelper.Int64ToBytes(accused)}))
+	msg, err := transports[accused-1].Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, testhelper.DKGMessageComplaint, msg.Type)
+	complaint := testhelper.Complaint{Accuser: msg.From, Accused: testhelper.BytesToInt64(msg.Payload)}
+	justification := participants[accused-1].Justify(complaint)
+	share := justification.Share.Bytes()
+	payload := append(testhelper.Int64ToBytes(complaint.Accuser), share[:]...)
+	assert.NoError(t, counters[accused-1].Broadcast(accused, testhelper.DKGMessage{Type: testhelper.DKGMessageJustification, Payload: payload}))
+
+	// the accuser receives the justification of the honest dealer and drops the complaint
+	msg, err = transports[0].Receive()
+	assert.NoError(t, err)
+	assert.Equal(t, testhelper.DKGMessageJustification, msg.Type)
+	revealed := new(btcec.ModNScalar)
+	revealed.SetByteSlice(msg.Payload[8:])
+	coordinator := testhelper.NewFrostCoordinator(&suite, participants[0])
+	coordinator.SubmitJustification(testhelper.Justification{Complaint: complaint, Share: revealed})
+	assert.False(t, coordinator.ResolveComplaint(complaint))
+	assert.Equal(t, testhelper.DKGRoundCount(true), groupRounds(counters))
+}
+
+// go test -v -run ^TestFrostVerifyConstantTermConsistency$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostVerifyConstantTermConsistency(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 4, 2)
+	verifier := participants[0]
+	for dealer := int64(1); dealer <= 4; dealer++ {
+		assert.NoError(t, verifier.VerifyConstantTermConsistency(dealer))
+	}
+
+	// the stored constant term of dealer 3 is swapped after its proof verified
+	// the slice is copied since commitments are shared with the dealer
+	corrupted := append([]*btcec.PublicKey{}, verifier.PolynomialCommitments[3]...)
+	corrupted[0] = participants[1].PolynomialCommitments[2][0]
+	verifier.PolynomialCommitments[3] = corrupted
+	assert.ErrorIs(t, verifier.VerifyConstantTermConsistency(3), testhelper.ErrInconsistentConstant)
+	assert.NoError(t, participants[1].VerifyConstantTermConsistency(3))
+
+	// no proof was verified by a fresh participant
+	fresh := testhelper.NewFrostParticipant(&suite, log.Default(), 4, 2, 1, nil)
+	assert.ErrorIs(t, fresh.VerifyConstantTermConsistency(2), testhelper.ErrMissingProvenKey)
+	assert.ErrorIs(t, verifier.VerifyConstantTermConsistency(5), testhelper.ErrIndexOutOfRange)
+}
+
+// go test -v -run ^TestFrostSaveLoadState$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSaveLoadState(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	threshold := int64(2)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+	}
+
+	// halfway: commitments and proofs are exchanged, secret shares are sent
+	for i := int64(0); i < n; i++ {
+		proof := participants[i].CalculateSecretProofs([32]byte{})
+		for j := int64(0); j < n; j++ {
+			if i == j {
+				continue
+			}
+			participants[j].UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+			assert.NoError(t, participants[j].VerifySecretProofs([32]byte{}, proof, i+1, participants[i].PolynomialCommitments[i+1][0]))
+		}
+		participants[i].CalculateSecretShares()
+	}
+	for i := int64(0); i < n; i++ {
+		for j := int64(0); j < n; j++ {
+			participants[j].ReceiveSecretShare(i+1, participants[i].GetSecretShares(j+1))
+		}
+	}
+
+	// every process restarts, the DKG completes on the restored participants
+	restored := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		var buf bytes.Buffer
+		assert.NoError(t, participants[i].SaveState(&buf))
+		loaded, err := suite.LoadState(&buf)
+		assert.NoError(t, err)
+		assert.NoError(t, loaded.VerifyConstantTermConsistency((i+1)%n+1))
+		restored[i] = loaded
+	}
+	for _, participants := range [][]*testhelper.FrostParticipant{participants, restored} {
+		for _, participant := range participants {
+			participant.DerivePowerMap()
+			_, err := participant.VerifyBatchPublicSecretShares(participant.ReceivedSecretShares(), uint32(participant.Position))
+			assert.NoError(t, err)
+			signing_shares := participant.CalculateSigningShares(participant.ReceivedSecretShares())
+			participant.CalculateInternalPublicSigningShares(signing_shares, participant.Position)
+			participant.DeriveExternalQMap()
+			participant.DeriveExternalWMap()
+			participant.CalculateBatchPublicSigningShares(map[int64]bool{participant.Position: true})
+			participant.CalculateGroupPublicKey()
+		}
+	}
+	for i := int64(0); i < n; i++ {
+		assert.True(t, restored[i].GroupPublicKey.IsEqual(participants[0].GroupPublicKey))
+		assert.True(t, testhelper.DKGOutputsEquivalent(dkgResult(t, participants[i]), dkgResult(t, restored[i])))
+		assert.True(t, restored[i].GetSigningShares().Equals(participants[i].GetSigningShares()))
+	}
+
+	// a completed participant round trips with its derived Q / W maps
+	var buf bytes.Buffer
+	assert.NoError(t, restored[2].SaveState(&buf))
+	saved := buf.Bytes()
+	loaded, err := suite.LoadState(bytes.NewReader(saved))
+	assert.NoError(t, err)
+	assert.Len(t, loaded.GetWMapItem(4), int(threshold+1))
+	assert.True(t, testhelper.DKGOutputsEquivalent(dkgResult(t, restored[2]), dkgResult(t, loaded)))
+	// maps are saved in affine coordinates, thus re - saving gives the same bytes
+	var resaved bytes.Buffer
+	assert.NoError(t, loaded.SaveState(&resaved))
+	assert.Equal(t, saved, resaved.Bytes())
+
+	// foreign, future and truncated states are refused
+	_, err = suite.LoadState(bytes.NewReader([]byte("not a state")))
+	assert.ErrorIs(t, err, testhelper.ErrInvalidStateMagic)
+	future := append([]byte{}, saved...)
+	future[9] = 2
+	_, err = suite.LoadState(bytes.NewReader(future))
+	assert.ErrorIs(t, err, testhelper.ErrUnsupportedStateVersion)
+	_, err = suite.LoadState(bytes.NewReader(saved[:len(saved)-1]))
+	assert.ErrorIs(t, err, testhelper.ErrMalformedState)
+}
+
+// go test -race -v -run ^TestFrostUpdateAllPolynomialCommitments$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostUpdateAllPolynomialCommitments(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(20)
+	participants := make([]*testhelper.FrostParticipant, n)
+	all_commitments := make(map[int64][]*btcec.PublicKey, n)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 3, i+1, nil)
+		all_commitments[i+1] = participants[i].PolynomialCommitments[i+1]
+	}
+
+	// readers run while the commitments are ingested
+	receiver := participants[0]
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for k := 0; k < 100; k++ {
+			for dealer := int64(1); dealer <= n; dealer++ {
+				if commitments, ok := receiver.GetPolynomialCommitments(dealer); ok {
+					assert.Len(t, commitments, 4)
+				}
+			}
+			receiver.DetectDealerIndexCollision()
+			receiver.QualifiedSet()
+			receiver.CalculateGroupPublicKey()
+		}
+	}()
+	receiver.UpdateAllPolynomialCommitments(all_commitments)
+	wg.Wait()
+
+	for dealer := int64(1); dealer <= n; dealer++ {
+		commitments, ok := receiver.GetPolynomialCommitments(dealer)
+		assert.True(t, ok)
+		assert.Equal(t, all_commitments[dealer], commitments)
+	}
+	collisions, err := receiver.DetectDealerIndexCollision()
+	assert.NoError(t, err)
+	assert.Empty(t, collisions)
+
+	// a second batch carrying other commitments for dealer 7 is flagged as the per call path does
+	other := make(map[int64][]*btcec.PublicKey, n)
+	for dealer, commitments := range all_commitments {
+		other[dealer] = commitments
+	}
+	other[7] = all_commitments[8]
+	receiver.UpdateAllPolynomialCommitments(other)
+	collisions, err = receiver.DetectDealerIndexCollision()
+	assert.ErrorIs(t, err, testhelper.ErrDealerIndexCollision)
+	assert.Equal(t, []int64{7}, collisions)
+
+	// out of range dealers are reported and not stored
+	recorder := &recordingT{}
+	suite.T = recorder
+	receiver.UpdateAllPolynomialCommitments(map[int64][]*btcec.PublicKey{n + 1: all_commitments[1]})
+	suite.T = t
+	assert.NotEmpty(t, recorder.errors)
+	_, ok := receiver.GetPolynomialCommitments(n + 1)
+	assert.False(t, ok)
+
+	// a polynomial of another degree than the threshold is refused by both paths
+	fresh := testhelper.NewFrostParticipant(&suite, log.Default(), n, 3, 1, nil)
+	short := all_commitments[2][:3]
+	recorder = &recordingT{}
+	suite.T = recorder
+	fresh.UpdateAllPolynomialCommitments(map[int64][]*btcec.PublicKey{2: short})
+	fresh.UpdatePolynomialCommitments(3, append(all_commitments[3], all_commitments[3][0]))
+	suite.T = t
+	assert.Len(t, recorder.errors, 2)
+	assert.Equal(t, []int64{1}, fresh.QualifiedSet())
+}
+
+// go test -v -run ^TestMultiScalarMul$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestMultiScalarMul(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	for _, n := range []int{0, 1, 3, 4, 5, 17, 100, 701} {
+		scalars := make([]*btcec.ModNScalar, n)
+		points := make([]*btcec.PublicKey, n)
+		for i := 0; i < n; i++ {
+			seed := suite.Generate32BSeed()
+			scalars[i] = new(btcec.ModNScalar)
+			scalars[i].SetBytes(&seed)
+			seed = suite.Generate32BSeed()
+			key, _ := btcec.PrivKeyFromBytes(seed[:])
+			points[i] = key.PubKey()
+		}
+		// edge scalars and a repeated point
+		if n >= 4 {
+			scalars[0].SetInt(0)
+			scalars[1].SetInt(1)
+			scalars[2].SetInt(1).Negate()
+			points[3] = points[2]
+		}
+
+		// naive sequential accumulation
+		expected := new(btcec.JacobianPoint)
+		for i := 0; i < n; i++ {
+			term := new(btcec.JacobianPoint)
+			points[i].AsJacobian(term)
+			btcec.ScalarMultNonConst(scalars[i], term, term)
+			btcec.AddNonConst(expected, term, expected)
+		}
+
+		actual, err := testhelper.MultiScalarMul(scalars, points)
+		assert.NoError(t, err)
+		expected.ToAffine()
+		actual.ToAffine()
+		assert.True(t, expected.X.Equals(&actual.X) && expected.Y.Equals(&actual.Y), "n = %d", n)
+
+		// a missing scalar or point is an error, not a shorter sum
+		if n > 0 {
+			_, err = testhelper.MultiScalarMul(scalars[1:], points)
+			assert.ErrorIs(t, err, testhelper.ErrMSMLengthMismatch)
+			_, err = testhelper.MultiScalarMul(scalars, points[1:])
+			assert.ErrorIs(t, err, testhelper.ErrMSMLengthMismatch)
+		}
+	}
+}
+
+// go test -v -run ^TestFrostCoordinatorInsufficientQualifiedDealers$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostCoordinatorInsufficientQualifiedDealers(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(6)
+	threshold := int64(3)
+	participants := make([]*testhelper.FrostParticipant, n)
+	proofs := make(map[int64]*schnorr.Signature)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, nil)
+		proofs[i+1] = participants[i].CalculateSecretProofs([32]byte{})
+	}
+	coordinator := testhelper.NewFrostCoordinator(&suite, participants[0])
+	for i := int64(1); i < n; i++ {
+		participants[0].UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+	}
+
+	// dealer 2 replays the proof of dealer 3, 5 qualified dealers remain
+	proofs[2] = proofs[3]
+	assert.NoError(t, coordinator.QualifyDealers([32]byte{}, proofs))
+	assert.Equal(t, []int64{2}, coordinator.Disqualified())
+	assert.Equal(t, []int64{1, 3, 4, 5, 6}, participants[0].QualifiedSet())
+
+	// dealer 4 never sends its proof, dealer 6 misses shares
+	delete(proofs, 4)
+	coordinator.Disqualify(6)
+	err := coordinator.QualifyDealers([32]byte{}, proofs)
+	assert.ErrorIs(t, err, testhelper.ErrInsufficientQualifiedDealers)
+	assert.Equal(t, []int64{2, 4, 6}, coordinator.Disqualified())
+	assert.Equal(t, []int64{1, 3, 5}, participants[0].QualifiedSet())
+
+	// no certificate for a group formed by threshold dealers
+	_, err = coordinator.GenerateDKGCertificate()
+	assert.ErrorIs(t, err, testhelper.ErrInsufficientQualifiedDealers)
+}
+
+// go test -race -v -run ^TestFrostAttachPowerTable$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostAttachPowerTable(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(8)
+	threshold := int64(5)
+	table := testhelper.NewPowerTable(n, threshold)
+	participants := make([]*testhelper.FrostParticipant, 4)
+	var wg sync.WaitGroup
+	for i := range participants {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, int64(i+1), nil)
+		assert.NoError(t, participants[i].AttachPowerTable(table))
+		wg.Add(1)
+		go func(participant *testhelper.FrostParticipant) {
+			defer wg.Done()
+			participant.DerivePowerMap()
+		}(participants[i])
+	}
+	wg.Wait()
+
+	// maps derived independently, item by item
+	independent := testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, 5, nil)
+	all := make(map[int64]bool)
+	for posi := int64(1); posi <= n; posi++ {
+		all[posi] = true
+	}
+	independent.DerivePowerMapForSet(all)
+	for _, participant := range participants {
+		assert.Same(t, table, participant.PowerTable())
+		for posi := int64(1); posi <= n; posi++ {
+			assert.Equal(t, independent.GetPowerMapItem(posi), participant.GetPowerMapItem(posi))
+		}
+	}
+
+	// a participant without table builds its own, which can be shared in turn
+	independent.DerivePowerMap()
+	assert.NotNil(t, independent.PowerTable())
+	assert.Equal(t, table.Row(7), independent.PowerTable().Row(7))
+
+	other := testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold-1, 1, nil)
+	assert.ErrorIs(t, other.AttachPowerTable(table), testhelper.ErrPowerTableMismatch)
+}
+
+// go test -v -run ^TestFrostComplaintDisqualifiesCheatingDealer$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostComplaintDisqualifiesCheatingDealer(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	threshold := int64(2)
+	cheater := int64(3)
+	secrets := make(map[int64]*btcec.ModNScalar)
+	participants := make([]*testhelper.FrostParticipant, n)
+	for i := int64(0); i < n; i++ {
+		secrets[i+1] = new(btcec.ModNScalar).SetInt(uint32(2000 + i))
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, threshold, i+1, new(btcec.ModNScalar).Set(secrets[i+1]))
+	}
+	for i := int64(0); i < n; i++ {
+		for j := int64(0); j < n; j++ {
+			if i != j {
+				participants[j].UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+			}
+		}
+	}
+
+	// the cheating dealer sends shares off its polynomial to participants 1 and 2
+	secret_shares_map := make(map[int64]map[int64]*btcec.ModNScalar)
+	for i := int64(0); i < n; i++ {
+		participants[i].CalculateSecretShares()
+		secret_shares_map[i+1] = make(map[int64]*btcec.ModNScalar)
+	}
+	for i := int64(0); i < n; i++ {
+		for j := int64(0); j < n; j++ {
+			secret_shares_map[j+1][i+1] = participants[i].GetSecretShares(j + 1)
+		}
+	}
+	tampered := new(btcec.ModNScalar).Set(secret_shares_map[1][cheater]).Add(new(btcec.ModNScalar).SetInt(1))
+	secret_shares_map[1][cheater] = tampered
+	secret_shares_map[2][cheater] = new(btcec.ModNScalar).SetInt(7)
+
+	complaints := make([]testhelper.Complaint, 0)
+	for i := int64(0); i < n; i++ {
+		participants[i].DerivePowerMap()
+		bad_dealers, err := participants[i].VerifyBatchPublicSecretShares(secret_shares_map[i+1], uint32(i+1))
+		if i+1 <= 2 {
+			assert.ErrorIs(t, err, testhelper.ErrInvalidSecretShare)
+			assert.Equal(t, []int64{cheater}, bad_dealers)
+		} else {
+			assert.NoError(t, err)
+		}
+		for _, dealer := range bad_dealers {
+			complaints = append(complaints, participants[i].RaiseComplaint(dealer))
+		}
+	}
+	// participant 4 accuses honest dealer 5 without reason
+	false_complaint := participants[3].RaiseComplaint(5)
+
+	coordinator := testhelper.NewFrostCoordinator(&suite, participants[4])
+	// the cheater stands by the share it sent to 1 and does not answer 2
+	coordinator.SubmitJustification(testhelper.Justification{Complaint: complaints[0], Share: tampered})
+	coordinator.SubmitJustification(participants[4].Justify(false_complaint))
+
+	assert.False(t, coordinator.ResolveComplaint(false_complaint))
+	for _, complaint := range complaints {
+		assert.True(t, coordinator.ResolveComplaint(complaint))
+	}
+	assert.Equal(t, []int64{cheater}, coordinator.Disqualified())
+	assert.NoError(t, coordinator.CheckQualifiedSet())
+
+	// rerun the share derivation with the remaining honest set
+	honest_set := []int64{1, 2, 4, 5}
+	signing_shares := make(map[int64]*btcec.ModNScalar)
+	for _, posi := range honest_set {
+		participant := participants[posi-1]
+		participant.ExcludeDealer(cheater)
+		delete(secret_shares_map[posi], cheater)
+		assert.Equal(t, honest_set, participant.QualifiedSet())
+
+		_, err := participant.VerifyBatchPublicSecretShares(secret_shares_map[posi], uint32(posi))
+		assert.NoError(t, err)
+		signing_shares[posi] = participant.CalculateSigningShares(secret_shares_map[posi])
+		participant.CalculateInternalPublicSigningShares(signing_shares[posi], posi)
+		participant.DeriveExternalQMap()
+		participant.DeriveExternalWMap()
+		participant.CalculateBatchPublicSigningShares(map[int64]bool{posi: true})
+		participant.CalculateGroupPublicKey()
+	}
+
+	delete(secrets, cheater)
+	expected := testhelper.ExpectedGroupKey(secrets)
+	for _, posi := range honest_set {
+		assert.True(t, expected.IsEqual(participants[posi-1].GroupPublicKey))
+	}
+
+	message_hash := sha256.Sum256([]byte("complaint"))
+	signers := []int64{1, 4, 5}
+	partial_sigs := runFrostSigning(participants, signing_shares, signers, message_hash)
+	sig := testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], expected))
+}
+
+// go test -v -run ^TestFrostProveSameGroupKey$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostProveSameGroupKey(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	threshold := int64(2)
+	participants, signing_shares := runFrostDKG(&suite, n, threshold)
+	public_shares := func(shares map[int64]*btcec.ModNScalar) map[int64]*btcec.JacobianPoint {
+		points := make(map[int64]*btcec.JacobianPoint)
+		for posi, share := range shares {
+			points[posi] = new(btcec.JacobianPoint)
+			btcec.ScalarBaseMultNonConst(share, points[posi])
+		}
+		return points
+	}
+	old_shares := public_shares(signing_shares)
+
+	// every participant deals a reshare, the signing shares change and the group key does not
+	all := []int64{1, 2, 3, 4, 5}
+	transcripts := make([]*testhelper.ReshareTranscript, 0, n)
+	for _, participant := range participants {
+		transcript, err := participant.Reshare(threshold, all)
+		assert.NoError(t, err)
+		transcripts = append(transcripts, transcript)
+	}
+	new_signing_shares := make(map[int64]*btcec.ModNScalar)
+	for _, participant := range participants {
+		assert.NoError(t, participant.ApplyReshare(transcripts))
+		new_signing_shares[participant.Position] = participant.GetSigningShares()
+		assert.False(t, new_signing_shares[participant.Position].Equals(signing_shares[participant.Position]))
+	}
+	new_shares := public_shares(new_signing_shares)
+
+	proof, err := participants[0].ProveSameGroupKey(old_shares, new_shares)
+	assert.NoError(t, err)
+	assert.True(t, proof.GroupKey.IsEqual(participants[0].GroupPublicKey))
+	assert.Len(t, proof.NewSet, int(threshold+1))
+	assert.NoError(t, testhelper.VerifySameGroupKey(&suite, threshold, old_shares, new_shares, proof))
+
+	// a genuinely different group key
+	_, other_signing_shares := runFrostDKG(&suite, n, threshold)
+	other_shares := public_shares(other_signing_shares)
+	_, err = participants[0].ProveSameGroupKey(old_shares, other_shares)
+	assert.ErrorIs(t, err, testhelper.ErrGroupKeyChanged)
+
+	// the verifier checks against the shares it knows, a proof for other shares does not carry over
+	other_proof, err := participants[0].ProveSameGroupKey(other_shares, other_shares)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, testhelper.VerifySameGroupKey(&suite, threshold, old_shares, new_shares, other_proof), testhelper.ErrInvalidEqualityProof)
+	assert.ErrorIs(t, testhelper.VerifySameGroupKey(&suite, threshold, old_shares, other_shares, proof), testhelper.ErrInvalidEqualityProof)
+	// a lower threshold than the one known to the verifier
+	assert.ErrorIs(t, testhelper.VerifySameGroupKey(&suite, threshold-1, old_shares, new_shares, proof), testhelper.ErrInvalidEqualityProof)
+	malformed := []*testhelper.EqualityProof{
+		{GroupKey: proof.GroupKey, OldSet: []int64{1, 2}, NewSet: proof.NewSet},
+		{GroupKey: proof.GroupKey, OldSet: []int64{1, 1, 2}, NewSet: proof.NewSet},
+		{GroupKey: proof.GroupKey, OldSet: proof.OldSet, NewSet: []int64{1, 2, 7}},
+	}
+	for _, forged := range malformed {
+		assert.ErrorIs(t, testhelper.VerifySameGroupKey(&suite, threshold, old_shares, new_shares, forged), testhelper.ErrInvalidEqualityProof)
+	}
+	// any interpolation set of consistent sharings gives the same key
+	moved := &testhelper.EqualityProof{GroupKey: proof.GroupKey, OldSet: []int64{5, 2, 4}, NewSet: []int64{5, 3, 1}}
+	assert.NoError(t, testhelper.VerifySameGroupKey(&suite, threshold, old_shares, new_shares, moved))
+
+	// a share off the polynomial cannot hide outside of the interpolation set
+	new_shares[5] = old_shares[5]
+	_, err = participants[0].ProveSameGroupKey(old_shares, new_shares)
+	assert.ErrorIs(t, err, testhelper.ErrInconsistentSharing)
+	assert.ErrorIs(t, testhelper.VerifySameGroupKey(&suite, threshold, old_shares, new_shares, proof), testhelper.ErrInconsistentSharing)
+}
+
+// go test -race -v -run ^TestSetupAndExchangeCommitments$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestSetupAndExchangeCommitments(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(20)
+	threshold := int64(13)
+	participants := testhelper.SetupAndExchangeCommitments(&suite, log.Default(), n, threshold)
+	assert.Len(t, participants, int(n))
+
+	for i, participant := range participants {
+		assert.Equal(t, int64(i+1), participant.Position)
+		assert.Len(t, participant.QualifiedSet(), int(n))
+		for dealer := int64(1); dealer <= n; dealer++ {
+			commitments, ok := participant.GetPolynomialCommitments(dealer)
+			assert.True(t, ok, "participant %d, dealer %d", i+1, dealer)
+			assert.Len(t, commitments, int(threshold+1))
+			assert.True(t, commitments[0].IsEqual(participants[dealer-1].PolynomialCommitments[dealer][0]))
+		}
+		collisions, err := participant.DetectDealerIndexCollision()
+		assert.NoError(t, err)
+		assert.Empty(t, collisions)
+	}
+}
+
+// collects assertion failures reported by a participant instead of failing the test
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// go test -v -run ^TestFrostValidateIndex$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostValidateIndex(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(4)
+	participants, _ := runFrostDKG(&suite, n, 2)
+	participant := participants[0]
+	participant.DerivePowerMap()
+	commitments := participant.PolynomialCommitments[1]
+
+	recorder := &recordingT{}
+	suite.T = recorder
+
+	calls := map[string]func(i int64){
+		"UpdatePolynomialCommitments": func(i int64) { participant.UpdatePolynomialCommitments(i, commitments) },
+		"GetSecretShares":             func(i int64) { assert.Nil(t, participant.GetSecretShares(i)) },
+		"GetPublicSigningShares":      func(i int64) { assert.Nil(t, participant.GetPublicSigningShares(i)) },
+		"StorePublicSigningShares":    func(i int64) { participant.StorePublicSigningShares(i, participant.GroupPublicKey) },
+		"GetQMapItem":                 func(i int64) { assert.Nil(t, participant.GetQMapItem(i)) },
+		"GetWMapItem":                 func(i int64) { assert.Nil(t, participant.GetWMapItem(i)) },
+		"GetPowerMapItem":             func(i int64) { assert.Nil(t, participant.GetPowerMapItem(i)) },
+	}
+	for name, call := range calls {
+		for _, i := range []int64{0, n + 1} {
+			recorder.errors = nil
+			call(i)
+			assert.Len(t, recorder.errors, 1, name)
+			for _, err := range recorder.errors {
+				assert.Contains(t, err, testhelper.ErrIndexOutOfRange.Error(), name)
+			}
+		}
+	}
+
+	// out of range indices are not stored
+	_, ok := participant.PolynomialCommitments[0]
+	assert.False(t, ok)
+	_, ok = participant.PolynomialCommitments[n+1]
+	assert.False(t, ok)
+}
+
+// run a full DKG among n participants
+// returns all participants with public signing shares and group public key derived, and their signing shares
+func runFrostDKG(suite *testhelper.TestSuite, n, threshold int64) ([]*testhelper.FrostParticipant, map[int64]*btcec.ModNScalar) {
+	participants := make([]*testhelper.FrostParticipant, n)
+	logger := log.Default()
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(suite, logger, n, threshold, i+1, nil)
+	}
+
+	return participants, completeFrostDKG(suite, participants)
+}
+
+// run the DKG rounds among already constructed participants
+// returns the signing shares of all participants
+func completeFrostDKG(suite *testhelper.TestSuite, participants []*testhelper.FrostParticipant) map[int64]*btcec.ModNScalar {
+	n := int64(len(participants))
+
+	// update polynomial commitments
+	for i := int64(0); i < n; i++ {
+		for j := int64(0); j < n; j++ {
+			if i == j {
+				continue
+			}
+			participants[j].UpdatePolynomialCommitments(i+1, participants[i].PolynomialCommitments[i+1])
+		}
+	}
+
+	// secret proofs
+	for i := int64(0); i < n; i++ {
+		participant := participants[i]
+		challenge := participant.CalculateSecretProofs([32]byte{})
+		for j := int64(0); j < n; j++ {
+			assert.NoError(suite.T, participants[j].VerifySecretProofs([32]byte{}, challenge, i+1, participant.PolynomialCommitments[i+1][0]))
+		}
+	}
+
+	// calculate and distribute secret shares
+	secret_shares_map := make(map[int64]map[int64]*btcec.ModNScalar)
+	for i := int64(0); i < n; i++ {
+		secret_shares_map[i+1] = make(map[int64]*btcec.ModNScalar)
+	}
+	for i := int64(0); i < n; i++ {
+		participants[i].CalculateSecretShares()
+		for j := int64(0); j < n; j++ {
+			secret_shares_map[j+1][i+1] = participants[i].GetSecretShares(j + 1)
+		}
+	}
+
+	// verify secret shares and derive signing shares
+	signing_shares := make(map[int64]*btcec.ModNScalar)
+	for i := int64(0); i < n; i++ {
+		participant := participants[i]
+		participant.DerivePowerMap()
+		_, err := participant.VerifyBatchPublicSecretShares(secret_shares_map[i+1], uint32(i+1))
+		assert.NoError(suite.T, err)
+
+		signing_shares[i+1] = participant.CalculateSigningShares(secret_shares_map[i+1])
+	}
+
+	// calculate public signing shares and group public key
+	for i := int64(0); i < n; i++ {
+		participant := participants[i]
+		participant.CalculateInternalPublicSigningShares(signing_shares[i+1], i+1)
+		participant.DeriveExternalQMap()
+		participant.DeriveExternalWMap()
+		participant.CalculateBatchPublicSigningShares(map[int64]bool{i + 1: true})
+		participant.CalculateGroupPublicKey()
+	}
+
+	return signing_shares
+}
+
+// public output of a participant, all public signing shares must be present
+func dkgResult(t *testing.T, participant *testhelper.FrostParticipant) *testhelper.DKGResult {
+	result, err := participant.DKGResult()
+	assert.NoError(t, err)
+
+	return result
+}
+
+// deal the shares of a single polynomial f of degree threshold, cheaper than a DKG for a large n
+// returns the dealer with all public signing shares stored, the group public key Y = f(0) * G and s_i = f(i)
+func singleDealerShares(suite *testhelper.TestSuite, n, threshold int64) (*testhelper.FrostParticipant, *btcec.PublicKey, map[int64]*btcec.ModNScalar) {
+	dealer := testhelper.NewFrostParticipant(suite, log.Default(), n, threshold, 1, nil)
+	dealer.CalculateSecretShares()
+	group_key := dealer.CalculateGroupPublicKey()
+
+	signing_shares := make(map[int64]*btcec.ModNScalar, n)
+	for posi := int64(1); posi <= n; posi++ {
+		signing_shares[posi] = dealer.GetSecretShares(posi)
+		dealer.StorePublicSigningShares(posi, btcec.PrivKeyFromScalar(signing_shares[posi]).PubKey())
+	}
+
+	return dealer, group_key, signing_shares
+}
+
+// sends every message twice, as a sender retrying after a lost acknowledgement
+type retryingTransport struct {
+	testhelper.Transport
+}
+
+func (t *retryingTransport) Broadcast(from int64, msg testhelper.DKGMessage) error {
+	if err := t.Transport.Broadcast(from, msg); err != nil {
+		return err
+	}
+
+	return t.Transport.Broadcast(from, msg)
+}
+
+// counts the broadcast phases of a participant, a phase starts when the type of sent messages changes
+type phaseCountingTransport struct {
+	testhelper.Transport
+
+	phases int
+	last   testhelper.DKGMessageType
+	sent   map[testhelper.DKGMessageType]bool
+}
+
+// rounds of the whole group, each round has its own message type
+func groupRounds(counters []*phaseCountingTransport) int {
+	types := make(map[testhelper.DKGMessageType]bool)
+	for _, counter := range counters {
+		for msg_type := range counter.sent {
+			types[msg_type] = true
+		}
+	}
+
+	return len(types)
+}
+
+func (t *phaseCountingTransport) Broadcast(from int64, msg testhelper.DKGMessage) error {
+	if msg.Type != t.last {
+		t.phases++
+		t.last = msg.Type
+	}
+	if t.sent == nil {
+		t.sent = make(map[testhelper.DKGMessageType]bool)
+	}
+	t.sent[msg.Type] = true
+
+	return t.Transport.Broadcast(from, msg)
+}
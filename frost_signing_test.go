@@ -0,0 +1,1971 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/nghuyenthevinh2000/bitcoin-playground/frost"
+	"github.com/nghuyenthevinh2000/bitcoin-playground/testhelper"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v -run ^TestFrostAggregateStrict$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostAggregateStrict(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(7)
+	threshold := int64(4)
+	participants, signing_shares := runFrostDKG(&suite, n, threshold)
+	message_hash := sha256.Sum256([]byte("frost aggregate strict"))
+
+	// threshold + 1 signers
+	honest := []int64{1, 2, 4, 6, 7}
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message_hash)
+	aggregator := testhelper.NewFrostAggregator(&suite, participants[honest[0]-1])
+	sig, err := aggregator.AggregateStrict(0, partial_sigs)
+	assert.Nil(t, err)
+	assert.True(t, sig.Verify(message_hash[:], aggregator.Frost.GroupPublicKey))
+
+	// one signer short
+	honest = []int64{1, 2, 4, 6}
+	partial_sigs = runFrostSigning(participants, signing_shares, honest, message_hash)
+	aggregator = testhelper.NewFrostAggregator(&suite, participants[honest[0]-1])
+	sig, err = aggregator.AggregateStrict(0, partial_sigs)
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+	assert.Nil(t, sig)
+
+	// forcing aggregation does not produce a valid signature
+	sig = aggregator.AggregatePartialSignatures(0, partial_sigs)
+	assert.False(t, sig.Verify(message_hash[:], aggregator.Frost.GroupPublicKey))
+}
+
+// go test -v -run ^TestFrostContributionIncluded$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostContributionIncluded(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{2, 3, 5}
+	message_hash := sha256.Sum256([]byte("frost contribution included"))
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message_hash)
+
+	aggregator := testhelper.NewFrostAggregator(&suite, participants[1])
+	// nothing is included before aggregation
+	for posi := int64(1); posi <= 5; posi++ {
+		assert.False(t, aggregator.ContributionIncluded(posi))
+	}
+
+	signature := aggregator.AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, signature.Verify(message_hash[:], participants[1].GroupPublicKey))
+
+	for _, posi := range honest {
+		assert.True(t, aggregator.ContributionIncluded(posi))
+	}
+	assert.False(t, aggregator.ContributionIncluded(1))
+	assert.False(t, aggregator.ContributionIncluded(4))
+}
+
+// go test -v -run ^TestFrostLagrangeCoefficients$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostLagrangeCoefficients(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participant := testhelper.NewFrostParticipant(&suite, log.Default(), 7, 3, 1, nil)
+	aggregator := testhelper.NewFrostAggregator(&suite, participant)
+
+	signers := map[int64]bool{1: true, 3: true, 4: true, 6: true, 7: false}
+	coefficients := aggregator.LagrangeCoefficients(signers)
+	assert.Len(t, coefficients, 4)
+	assert.NotContains(t, coefficients, int64(7))
+
+	sum := new(btcec.ModNScalar)
+	for _, lambda := range coefficients {
+		sum.Add(lambda)
+	}
+	assert.True(t, sum.Equals(new(btcec.ModNScalar).SetInt(1)))
+}
+
+// go test -v -run ^TestFrostSigningLatency$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSigningLatency(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participant := testhelper.NewFrostParticipant(&suite, log.Default(), 5, 2, 1, nil)
+	aggregator := testhelper.NewFrostAggregator(&suite, participant)
+
+	latencies := map[int64]time.Duration{
+		1: 20 * time.Millisecond,
+		2: 500 * time.Millisecond,
+		3: 80 * time.Millisecond,
+		4: 35 * time.Millisecond,
+		5: time.Second,
+	}
+
+	// slow signers 2 and 5 are not selected
+	assert.Equal(t, 80*time.Millisecond, aggregator.SigningLatency(latencies, map[int64]bool{1: true, 3: true, 4: true, 5: false}))
+	assert.Equal(t, time.Second, aggregator.SigningLatency(latencies, map[int64]bool{1: true, 4: true, 5: true}))
+}
+
+// go test -v -run ^TestFrostThinVerify$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostThinVerify(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 2, 5}
+	message_hash := suite.RandomMessage()
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message_hash)
+	aggregator := testhelper.NewFrostAggregator(&suite, participants[0])
+	sig := aggregator.AggregatePartialSignatures(0, partial_sigs)
+
+	// only serialized outputs are passed to the verifier
+	group_key := ([32]byte)(schnorr.SerializePubKey(participants[0].GroupPublicKey))
+	sig_bytes := sig.Serialize()
+	assert.True(t, frost.Verify(group_key, message_hash, sig_bytes))
+
+	tampered := message_hash
+	tampered[0] ^= 1
+	assert.False(t, frost.Verify(group_key, tampered, sig_bytes))
+	assert.False(t, frost.Verify(group_key, message_hash, sig_bytes[:63]))
+}
+
+// go test -v -run ^TestFrostNoncesBoundToSighash$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostNoncesBoundToSighash(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 2, 4}
+	sighash := suite.RandomMessage()
+	other_sighash := suite.RandomMessage()
+
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	signing_index := int64(0)
+	for _, posi := range honest {
+		signing_index, public_nonces[posi] = participants[posi-1].PreprocessNoncesForSighash(sighash)
+	}
+
+	// errors instead of an empty partial signature, R is not derived yet and index 1 has no nonce pair
+	sig, err := participants[0].PartialSignForSighash(1, signing_index, honest, sighash, public_nonces, signing_shares[1])
+	assert.Nil(t, sig)
+	assert.ErrorIs(t, err, testhelper.ErrMissingAggrNonce)
+	_, err = participants[0].PartialSignForSighash(1, signing_index+1, honest, sighash, public_nonces, signing_shares[1])
+	assert.ErrorIs(t, err, testhelper.ErrIndexOutOfRange)
+
+	for _, posi := range honest {
+		_, err := participants[posi-1].CalculatePublicNonceCommitments(signing_index, honest, sighash, public_nonces)
+		assert.NoError(t, err)
+	}
+
+	// the nonce cannot be used for a different sighash
+	for _, posi := range honest {
+		_, err := participants[posi-1].PartialSignForSighash(posi, signing_index, honest, other_sighash, public_nonces, signing_shares[posi])
+		assert.ErrorIs(t, err, testhelper.ErrNonceBoundToOtherSighash)
+	}
+
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	for _, posi := range honest {
+		partial_sig, err := participants[posi-1].PartialSignForSighash(posi, signing_index, honest, sighash, public_nonces, signing_shares[posi])
+		assert.NoError(t, err)
+		partial_sigs[posi] = partial_sig
+	}
+	sig = testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(signing_index, partial_sigs)
+	assert.True(t, sig.Verify(sighash[:], participants[0].GroupPublicKey))
+}
+
+// go test -v -run ^TestRandomMessage$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestRandomMessage(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	message := suite.RandomMessage()
+	assert.Len(t, message, 32)
+	assert.NotEqual(t, [32]byte{}, message)
+	assert.NotEqual(t, message, suite.RandomMessage())
+
+	// reproducible under a fixed seed injected into the suite
+	suite.SetTestRandSource(testhelper.NewTestRandSource(42))
+	first := suite.RandomMessage()
+	suite.SetTestRandSource(testhelper.NewTestRandSource(42))
+	assert.Equal(t, first, suite.RandomMessage())
+	suite.SetTestRandSource(testhelper.NewTestRandSource(43))
+	assert.NotEqual(t, first, suite.RandomMessage())
+
+	// the seed is local to the suite, another suite keeps crypto/rand
+	other := testhelper.TestSuite{}
+	other.SetupStaticSimNetSuite(t, log.Default())
+	suite.SetTestRandSource(testhelper.NewTestRandSource(42))
+	assert.NotEqual(t, first, other.RandomMessage())
+
+	// back to crypto/rand
+	suite.SetTestRandSource(nil)
+	assert.NotEqual(t, first, suite.RandomMessage())
+}
+
+// go test -v -run ^TestFrostSerializeSignedTx$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSerializeSignedTx(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 3, 5}
+
+	pkScript, err := txscript.PayToTaprootScript(participants[0].GroupPublicKey)
+	assert.Nil(t, err)
+
+	var signed_tx *wire.MsgTx
+	suite.ValidateScript(pkScript, 1, func(t assert.TestingT, prevOut *wire.TxOut, tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, idx int) wire.TxWitness {
+		inputFetcher := txscript.NewCannedPrevOutputFetcher(
+			prevOut.PkScript,
+			prevOut.Value,
+		)
+		sigHash, err := txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, tx, idx, inputFetcher)
+		assert.Nil(t, err)
+
+		partial_sigs := runFrostSigning(participants, signing_shares, honest, ([32]byte)(sigHash))
+		aggregator := testhelper.NewFrostAggregator(&suite, participants[honest[0]-1])
+		sig := aggregator.AggregatePartialSignatures(0, partial_sigs)
+
+		signed_tx = tx
+		return wire.TxWitness{sig.Serialize()}
+	})
+
+	tx_hex, err := suite.SerializeSignedTx(signed_tx)
+	assert.Nil(t, err)
+
+	// round trip
+	tx_bytes, err := hex.DecodeString(tx_hex)
+	assert.Nil(t, err)
+	decoded_tx := new(wire.MsgTx)
+	err = decoded_tx.Deserialize(bytes.NewReader(tx_bytes))
+	assert.Nil(t, err)
+	assert.Equal(t, signed_tx.TxHash(), decoded_tx.TxHash())
+	assert.Equal(t, signed_tx.WitnessHash(), decoded_tx.WitnessHash())
+
+	// unsigned input is rejected
+	signed_tx.TxIn[0].Witness = nil
+	_, err = suite.SerializeSignedTx(signed_tx)
+	assert.ErrorIs(t, err, testhelper.ErrMissingWitness)
+}
+
+// go test -v -run ^TestFrostMaxConcurrentSessions$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostMaxConcurrentSessions(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := 3
+	participant := testhelper.NewFrostParticipant(&suite, log.Default(), 3, 1, 1, nil)
+	participant.SetMaxConcurrentSessions(n)
+
+	for i := 0; i < n; i++ {
+		assert.NoError(t, participant.BeginSigningSession(int64(i)))
+	}
+	assert.ErrorIs(t, participant.BeginSigningSession(int64(n)), testhelper.ErrTooManySessions)
+
+	// closing a session frees a slot
+	participant.EndSigningSession(1)
+	assert.NoError(t, participant.BeginSigningSession(int64(n)))
+	assert.ErrorIs(t, participant.BeginSigningSession(int64(n+1)), testhelper.ErrTooManySessions)
+}
+
+// go test -v -run ^TestFrostListSessions$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostListSessions(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	participant := participants[0]
+	assert.Empty(t, participant.ListSessions())
+
+	// a session signed by the quorum {1, 2, 3}
+	message_hash := suite.RandomMessage()
+	honest := []int64{1, 2, 3}
+	signing_indices := make(map[int64]int64)
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		signing_indices[posi], public_nonces[posi] = participants[posi-1].PreprocessNoncesForSighash(message_hash)
+	}
+	signed := signing_indices[1]
+	assert.NoError(t, participant.BeginSigningSession(signed))
+	time.Sleep(5 * time.Millisecond)
+
+	// a session with published nonces, and one not preprocessed yet
+	awaiting, _ := participant.PreprocessNoncesForSighash(suite.RandomMessage())
+	assert.NoError(t, participant.BeginSigningSession(awaiting))
+	assert.NoError(t, participant.BeginSigningSession(awaiting+1))
+
+	_, err := participant.CalculatePublicNonceCommitments(signed, honest, message_hash, public_nonces)
+	assert.NoError(t, err)
+	assert.NotNil(t, participant.PartialSign(1, signed, honest, message_hash, public_nonces, signing_shares[1]))
+
+	sessions := participant.ListSessions()
+	if assert.Len(t, sessions, 3) {
+		assert.Equal(t, signed, sessions[0].ID)
+		assert.Equal(t, testhelper.SessionSigned, sessions[0].State)
+		assert.Equal(t, awaiting, sessions[1].ID)
+		assert.Equal(t, testhelper.SessionAwaitingCommitments, sessions[1].State)
+		assert.Equal(t, awaiting+1, sessions[2].ID)
+		assert.Equal(t, testhelper.SessionPreprocessing, sessions[2].State)
+		assert.GreaterOrEqual(t, sessions[0].Age, 5*time.Millisecond)
+		assert.Greater(t, sessions[0].Age, sessions[1].Age)
+	}
+
+	// closed sessions are no longer listed
+	participant.EndSigningSession(signed)
+	sessions = participant.ListSessions()
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, awaiting, sessions[0].ID)
+}
+
+// go test -v -run ^TestFrostEncodeCommitmentList$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostEncodeCommitmentList(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	participants := make([]*testhelper.FrostParticipant, n)
+	commitments := make(map[int64][2]*btcec.JacobianPoint)
+	for i := int64(0); i < n; i++ {
+		participants[i] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 2, i+1, nil)
+		nonces := participants[i].GenerateSigningNonces(1)
+		D := new(btcec.JacobianPoint)
+		nonces[0][0].AsJacobian(D)
+		E := new(btcec.JacobianPoint)
+		nonces[0][1].AsJacobian(E)
+		commitments[i+1] = [2]*btcec.JacobianPoint{D, E}
+	}
+	aggregator := testhelper.NewFrostAggregator(&suite, participants[0])
+
+	// deterministic regardless of map iteration order
+	encoding := aggregator.EncodeCommitmentList(commitments)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, encoding, aggregator.EncodeCommitmentList(commitments))
+	}
+
+	// sorted by signer index
+	expected := make([]byte, 0)
+	for i := int64(1); i <= n; i++ {
+		expected = append(expected, commitments[i][0].X.Bytes()[:]...)
+		expected = append(expected, commitments[i][1].X.Bytes()[:]...)
+	}
+	assert.Equal(t, expected, encoding)
+}
+
+// go test -v -run ^TestFrostSplitShareForDevices$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSplitShareForDevices(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 3, 5}
+	message_hash := sha256.Sum256([]byte("frost split share"))
+
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi-1].GenerateSigningNonces(1)[0]
+	}
+	for _, posi := range honest {
+		participants[posi-1].CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+	}
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	for _, posi := range []int64{1, 5} {
+		partial_sigs[posi] = participants[posi-1].PartialSign(posi, 0, honest, message_hash, public_nonces, signing_shares[posi])
+	}
+
+	participant := participants[2]
+	shareA, shareB := participant.SplitShareForDevices()
+	assert.Equal(t, signing_shares[3], new(btcec.ModNScalar).Add2(shareA, shareB))
+
+	// no R derived for signing index 1
+	_, err := participant.DeviceSignContribution(3, 1, honest, message_hash, shareA)
+	assert.ErrorIs(t, err, testhelper.ErrMissingAggrNonce)
+
+	// each device signs with its half, the combined partial signature completes the group signature
+	contributionA, err := participant.DeviceSignContribution(3, 0, honest, message_hash, shareA)
+	assert.NoError(t, err)
+	contributionB, err := participant.DeviceSignContribution(3, 0, honest, message_hash, shareB)
+	assert.NoError(t, err)
+	partial_sigs[3] = participant.PartialSignFromHalves(3, 0, honest, message_hash, public_nonces, contributionA, contributionB)
+
+	sig := testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].GroupPublicKey))
+}
+
+// each honest participant generates a nonce pair for signing index 0, derives the aggregated nonce commitment,
+// then produces a partial signature over message_hash
+func runFrostSigning(participants []*testhelper.FrostParticipant, signing_shares map[int64]*btcec.ModNScalar, honest []int64, message_hash [32]byte) map[int64]*schnorr.Signature {
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		nonces := participants[posi-1].GenerateSigningNonces(1)
+		public_nonces[posi] = nonces[0]
+	}
+
+	for _, posi := range honest {
+		participants[posi-1].CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+	}
+
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	for _, posi := range honest {
+		partial_sigs[posi] = participants[posi-1].PartialSign(posi, 0, honest, message_hash, public_nonces, signing_shares[posi])
+	}
+
+	return partial_sigs
+}
+
+// \sum_{j} \lambda_j(x) * shares_j, the value at x of the polynomial through the shares
+func interpolateAt(suite *testhelper.TestSuite, shares map[int64]*btcec.ModNScalar, x int64) *btcec.ModNScalar {
+	set := make([]int64, 0, len(shares))
+	for posi := range shares {
+		set = append(set, posi)
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+	value := new(btcec.ModNScalar)
+	for _, posi := range set {
+		value.Add(suite.CalculateLagrangeCoeffAt(posi, x, set).Mul(shares[posi]))
+	}
+
+	return value
+}
+
+// go test -v -run ^TestSessionFingerprint$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestSessionFingerprint(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	session_id := sha256.Sum256([]byte("session"))
+	msg := sha256.Sum256([]byte("message"))
+	signers := map[int64]bool{1: true, 3: true, 4: true}
+
+	fingerprint := participants[0].SessionFingerprint(session_id, msg, signers)
+	assert.Len(t, fingerprint, 16)
+	assert.Equal(t, fingerprint, participants[2].SessionFingerprint(session_id, msg, map[int64]bool{4: true, 3: true, 1: true}))
+
+	other_session := sha256.Sum256([]byte("other session"))
+	other_msg := sha256.Sum256([]byte("other message"))
+	assert.NotEqual(t, fingerprint, participants[2].SessionFingerprint(other_session, msg, signers))
+	assert.NotEqual(t, fingerprint, participants[2].SessionFingerprint(session_id, other_msg, signers))
+	assert.NotEqual(t, fingerprint, participants[2].SessionFingerprint(session_id, msg, map[int64]bool{1: true, 3: true, 5: true}))
+}
+
+// go test -v -run ^TestSignBIP322$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestSignBIP322(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	output_key := txscript.ComputeTaprootKeyNoScript(participants[0].GroupPublicKey)
+	address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(output_key), suite.BtcdChainConfig)
+	assert.NoError(t, err)
+
+	message := "hello frost"
+	signature, err := suite.SignBIP322(participants, map[int64]bool{1: true, 3: true, 5: true}, address, message)
+	assert.NoError(t, err)
+	assert.NoError(t, testhelper.VerifyBIP322(address, message, signature))
+
+	// signature does not hold for a different message
+	assert.ErrorIs(t, testhelper.VerifyBIP322(address, "hello bitcoin", signature), testhelper.ErrBIP322InvalidSignature)
+
+	// below threshold
+	_, err = suite.SignBIP322(participants, map[int64]bool{1: true, 3: true}, address, message)
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+
+	// address of the untweaked group key is not the group address
+	untweaked, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(participants[0].GroupPublicKey), suite.BtcdChainConfig)
+	assert.NoError(t, err)
+	_, err = suite.SignBIP322(participants, map[int64]bool{1: true, 3: true, 5: true}, untweaked, message)
+	assert.ErrorIs(t, err, testhelper.ErrBIP322AddressMismatch)
+}
+
+// go test -v -run ^TestFrostTaprootOutputKey$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostTaprootOutputKey(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	group_key := participants[0].GroupPublicKey
+
+	// BIP86, no script path
+	output_key, err := participants[0].TaprootOutputKey(nil)
+	assert.NoError(t, err)
+	assert.True(t, output_key.IsEqual(txscript.ComputeTaprootKeyNoScript(group_key)))
+	address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(output_key), suite.BtcdChainConfig)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(address.EncodeAddress(), "sb1p"))
+	pkScript, err := txscript.PayToAddrScript(address)
+	assert.NoError(t, err)
+	expected_script, err := txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(group_key))
+	assert.NoError(t, err)
+	assert.Equal(t, expected_script, pkScript)
+
+	// with a script path
+	group_script, err := participants[0].GroupCheckSigScript()
+	assert.NoError(t, err)
+	merkle_root := txscript.AssembleTaprootScriptTree(txscript.NewBaseTapLeaf(group_script)).RootNode.TapHash()
+	output_key, err = participants[0].TaprootOutputKey(merkle_root[:])
+	assert.NoError(t, err)
+	assert.True(t, output_key.IsEqual(txscript.ComputeTaprootOutputKey(group_key, merkle_root[:])))
+
+	// Q = P + t * G, with P lifted to even Y coordinate
+	tweak, odd, err := participants[0].TaprootTweak(merkle_root[:])
+	assert.NoError(t, err)
+	assert.Equal(t, output_key.SerializeCompressed()[0] == 0x03, odd)
+	P, err := schnorr.ParsePubKey(schnorr.SerializePubKey(group_key))
+	assert.NoError(t, err)
+	P_point := new(btcec.JacobianPoint)
+	P.AsJacobian(P_point)
+	Q := new(btcec.JacobianPoint)
+	btcec.ScalarBaseMultNonConst(tweak, Q)
+	btcec.AddNonConst(P_point, Q, Q)
+	Q.ToAffine()
+	assert.True(t, output_key.IsEqual(btcec.NewPublicKey(&Q.X, &Q.Y)))
+
+	_, err = participants[0].TaprootOutputKey(merkle_root[:31])
+	assert.ErrorIs(t, err, testhelper.ErrInvalidMerkleRoot)
+	_, err = testhelper.NewFrostParticipant(&suite, log.Default(), 5, 2, 1, nil).TaprootOutputKey(nil)
+	assert.ErrorIs(t, err, testhelper.ErrMissingGroupKey)
+}
+
+// go test -v -run ^TestFrostSignTaprootKeyPath$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSignTaprootKeyPath(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// several groups, so that both Y parities of P and Q occur
+	for run := 0; run < 6; run++ {
+		participants, _ := runFrostDKG(&suite, 5, 2)
+		group_script, err := participants[0].GroupCheckSigScript()
+		assert.NoError(t, err)
+		merkle_root := txscript.AssembleTaprootScriptTree(txscript.NewBaseTapLeaf(group_script)).RootNode.TapHash()
+
+		for _, root := range [][]byte{nil, merkle_root[:]} {
+			output_key, err := participants[0].TaprootOutputKey(root)
+			assert.NoError(t, err)
+			address, err := btcutil.NewAddressTaproot(schnorr.SerializePubKey(output_key), suite.BtcdChainConfig)
+			assert.NoError(t, err)
+			pkScript, err := txscript.PayToAddrScript(address)
+			assert.NoError(t, err)
+
+			// spend the P2TR output of the group through the key path
+			prevOut := wire.NewTxOut(100000, pkScript)
+			outpoint := wire.OutPoint{Hash: sha256.Sum256([]byte{byte(run)}), Index: 0}
+			tx := wire.NewMsgTx(2)
+			tx.AddTxIn(wire.NewTxIn(&outpoint, nil, nil))
+			tx.AddTxOut(wire.NewTxOut(90000, pkScript))
+			fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+			sig_hashes := txscript.NewTxSigHashes(tx, fetcher)
+			sighash, err := txscript.CalcTaprootSignatureHash(sig_hashes, txscript.SigHashDefault, tx, 0, fetcher)
+			assert.NoError(t, err)
+
+			sig, err := suite.SignTaprootKeyPath(participants, ([32]byte)(sighash), root, map[int64]bool{1: true, 3: true, 4: true})
+			assert.NoError(t, err)
+			assert.True(t, sig.Verify(sighash, output_key))
+			assert.False(t, sig.Verify(sighash, participants[0].GroupPublicKey))
+
+			tx.TxIn[0].Witness = wire.TxWitness{sig.Serialize()}
+			engine, err := txscript.NewEngine(prevOut.PkScript, tx, 0, txscript.StandardVerifyFlags, nil, sig_hashes, prevOut.Value, fetcher)
+			assert.NoError(t, err)
+			assert.NoError(t, engine.Execute(), "run %d, merkle root %x", run, root)
+		}
+	}
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	_, err := suite.SignTaprootKeyPath(participants, suite.RandomMessage(), nil, map[int64]bool{1: true, 3: true})
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+	_, err = suite.SignTaprootKeyPath(participants, suite.RandomMessage(), []byte{1}, map[int64]bool{1: true, 3: true, 4: true})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidMerkleRoot)
+}
+
+// go test -v -run ^TestScriptOnlyTaprootKey$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestScriptOnlyTaprootKey(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{2, 3, 5}
+
+	group_script, err := participants[0].GroupCheckSigScript()
+	assert.NoError(t, err)
+	tapleaf := txscript.NewBaseTapLeaf(group_script)
+	taptree := txscript.AssembleTaprootScriptTree(tapleaf)
+	merkle_root := taptree.RootNode.TapHash()
+
+	output_key, err := participants[0].ScriptOnlyTaprootKey(merkle_root[:])
+	assert.NoError(t, err)
+	_, err = participants[0].ScriptOnlyTaprootKey(merkle_root[:31])
+	assert.ErrorIs(t, err, testhelper.ErrInvalidMerkleRoot)
+
+	// the group key tweaked with the same tree is not the output key
+	assert.False(t, output_key.IsEqual(txscript.ComputeTaprootOutputKey(participants[0].GroupPublicKey, merkle_root[:])))
+
+	pkScript, err := txscript.PayToTaprootScript(output_key)
+	assert.NoError(t, err)
+	control_block := taptree.LeafMerkleProofs[0].ToControlBlock(testhelper.NUMSInternalKey())
+	control_block_bytes, err := control_block.ToBytes()
+	assert.NoError(t, err)
+
+	// script path with a threshold signature of the group
+	suite.ValidateScript(pkScript, 1, func(t assert.TestingT, prevOut *wire.TxOut, tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, idx int) wire.TxWitness {
+		inputFetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+		sigHash, err := txscript.CalcTapscriptSignaturehash(sigHashes, txscript.SigHashDefault, tx, idx, inputFetcher, tapleaf)
+		assert.Nil(t, err)
+
+		partial_sigs := runFrostSigning(participants, signing_shares, honest, ([32]byte)(sigHash))
+		sig := testhelper.NewFrostAggregator(&suite, participants[honest[0]-1]).AggregatePartialSignatures(0, partial_sigs)
+
+		return wire.TxWitness{sig.Serialize(), group_script, control_block_bytes}
+	})
+
+	// key path with a threshold signature of the group is rejected
+	recorder := &recordingT{}
+	key_path_suite := testhelper.TestSuite{}
+	key_path_suite.SetupStaticSimNetSuite(recorder, log.Default())
+	key_path_suite.ValidateScript(pkScript, 1, func(_ assert.TestingT, prevOut *wire.TxOut, tx *wire.MsgTx, sigHashes *txscript.TxSigHashes, idx int) wire.TxWitness {
+		inputFetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+		sigHash, err := txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, tx, idx, inputFetcher)
+		assert.Nil(t, err)
+
+		partial_sigs := runFrostSigning(participants, signing_shares, honest, ([32]byte)(sigHash))
+		sig := testhelper.NewFrostAggregator(&suite, participants[honest[0]-1]).AggregatePartialSignatures(0, partial_sigs)
+
+		return wire.TxWitness{sig.Serialize()}
+	})
+	assert.NotEmpty(t, recorder.errors)
+}
+
+// go test -v -run ^TestFrostShareRecovery$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostShareRecovery(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	lost_share := signing_shares[3]
+
+	// participant 3 loses its share
+	victim := participants[2]
+	victim.StoreSigningShares(nil)
+	delete(signing_shares, 3)
+
+	_, err := victim.RequestShareRecovery([]int64{1, 4})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidRecoveryHelpers)
+	request, err := victim.RequestShareRecovery([]int64{5, 1, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, testhelper.RecoveryRequest{Victim: 3, Helpers: []int64{1, 4, 5}}, request)
+
+	helpers := request.Helpers
+	_, err = participants[0].DealRecoveryMasks(3, []int64{1, 3, 5})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidRecoveryHelpers)
+	_, err = participants[1].DealRecoveryMasks(request.Victim, helpers)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidRecoveryHelpers)
+
+	// the helpers answer the request, exchanging their masks among themselves
+	masks := make([]testhelper.RecoveryMasks, 0, len(helpers))
+	for _, posi := range helpers {
+		recovery, err := participants[posi-1].DealRecoveryMasks(request.Victim, helpers)
+		assert.NoError(t, err)
+		masks = append(masks, recovery)
+	}
+	// a helper refuses to answer without its own mask
+	_, err = participants[0].ProvideRecoveryShare(masks[1:])
+	assert.ErrorIs(t, err, testhelper.ErrMissingRecoveryMask)
+
+	// not enough helpers
+	recovery_shares := make(map[int64]*btcec.ModNScalar)
+	for _, posi := range helpers {
+		recovery_shares[posi], err = participants[posi-1].ProvideRecoveryShare(masks)
+		assert.NoError(t, err)
+	}
+	_, err = victim.CompleteShareRecovery(map[int64]*btcec.ModNScalar{1: recovery_shares[1], 4: recovery_shares[4]})
+	assert.ErrorIs(t, err, testhelper.ErrNotEnoughRecoveryShares)
+
+	// the victim only sees r_j, neither r_j nor f + g interpolated at any position gives a share s_j,
+	// even with the masks of helpers 1 and 4 colluding with the victim, the mask of helper 5 hides every s_j
+	colluding := masks[:2]
+	for posi := int64(1); posi <= 5; posi++ {
+		if posi == 3 {
+			continue
+		}
+		candidate, ok := recovery_shares[posi]
+		if !ok {
+			candidate = interpolateAt(&suite, recovery_shares, posi)
+		}
+		unmasked := new(btcec.ModNScalar).Set(candidate)
+		for _, recovery := range colluding {
+			if mask, ok := recovery.Masks[posi]; ok {
+				unmasked.Add(new(btcec.ModNScalar).NegateVal(mask))
+			}
+		}
+		assert.False(t, candidate.Equals(signing_shares[posi]), "position %d", posi)
+		assert.False(t, unmasked.Equals(signing_shares[posi]), "position %d", posi)
+	}
+
+	recovered, err := victim.CompleteShareRecovery(recovery_shares)
+	assert.NoError(t, err)
+	assert.True(t, recovered.Equals(lost_share))
+	assert.True(t, victim.GetSigningShares().Equals(lost_share))
+	signing_shares[3] = recovered
+
+	// the victim can sign again
+	honest := []int64{2, 3, 5}
+	message_hash := sha256.Sum256([]byte("recovered"))
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message_hash)
+	sig := testhelper.NewFrostAggregator(&suite, participants[honest[0]-1]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].GroupPublicKey))
+}
+
+// go test -v -run ^TestFrostInvalidAggregatedNonce$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostInvalidAggregatedNonce(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 2, 4}
+	message_hash := sha256.Sum256([]byte("invalid nonce"))
+
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi-1].GenerateSigningNonces(1)[0]
+	}
+	nonce_commitments, err := participants[0].CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+	assert.NoError(t, err)
+
+	// R_4 = -(R_1 + R_2) sums to the point at infinity
+	R := new(btcec.JacobianPoint)
+	for _, posi := range []int64{1, 2} {
+		R_i := new(btcec.JacobianPoint)
+		nonce_commitments[posi].AsJacobian(R_i)
+		btcec.AddNonConst(R, R_i, R)
+	}
+	R.ToAffine()
+	R.Y.Negate(1).Normalize()
+	nonce_commitments[4] = btcec.NewPublicKey(&R.X, &R.Y)
+	_, err = testhelper.AggregateNonceCommitments(nonce_commitments)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidNonce)
+
+	// retry path: signer 4 regenerates its nonces
+	public_nonces[4] = participants[3].GenerateSigningNonces(1)[0]
+	nonce_commitments, err = participants[0].CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+	assert.NoError(t, err)
+	R_aggr, err := testhelper.AggregateNonceCommitments(nonce_commitments)
+	assert.NoError(t, err)
+	assert.True(t, R_aggr.X.Equals(&participants[0].AggrNonceCommitment[0].X))
+}
+
+// go test -v -run ^TestWstsWeightedLagrangeCoefficient$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestWstsWeightedLagrangeCoefficient(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// uneven weights, participant 1 holds 4 keys, participant 2 holds 1 key, participant 3 holds 2 keys
+	participants := runWstsDKG(&suite, []int64{4, 1, 2}, 3)
+	signers := map[int64]bool{1: true, 3: true}
+	honest := []int64{1, 3}
+	message_hash := sha256.Sum256([]byte("wsts weighted"))
+
+	// weights of all signers sum to 1
+	total_weight := new(btcec.ModNScalar)
+	for _, posi := range honest {
+		total_weight.Add(participants[posi-1].WeightedLagrangeCoefficient(signers))
+	}
+	assert.True(t, total_weight.Equals(new(btcec.ModNScalar).SetInt(1)))
+	assert.False(t, participants[0].WeightedLagrangeCoefficient(signers).Equals(participants[2].WeightedLagrangeCoefficient(signers)))
+
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi-1].Frost.GenerateSigningNonces(1)[0]
+	}
+	for _, posi := range honest {
+		_, err := participants[posi-1].Frost.CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+		assert.NoError(t, err)
+	}
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	for _, posi := range honest {
+		partial_sigs[posi] = participants[posi-1].WeightedPartialSign(0, honest, message_hash, public_nonces)
+	}
+
+	sig, err := participants[0].AggregateWeightedPartialSignatures(0, partial_sigs)
+	assert.NoError(t, err)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].Frost.GroupPublicKey))
+
+	// 2 keys do not pass the threshold
+	_, err = participants[0].AggregateWeightedPartialSignatures(0, map[int64]*schnorr.Signature{3: partial_sigs[3]})
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+}
+
+// go test -v -run ^TestWstsWeightedSigningRound$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestWstsWeightedSigningRound(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// n_p = 10, n_keys = 100, threshold = 70
+	key_counts := []int64{25, 15, 12, 10, 10, 8, 7, 6, 4, 3}
+	participants := runWstsDKG(&suite, key_counts, 70)
+	message_hash := suite.RandomMessage()
+
+	// participants 1 to 6 hold 80 keys, participants 1 to 5 and 7 hold 79 keys
+	// both sessions sign the same message, their rounds are interleaved
+	signers := map[int64]bool{1: true, 2: true, 3: true, 4: true, 5: true, 6: true}
+	other_signers := map[int64]bool{1: true, 2: true, 3: true, 4: true, 5: true, 7: true}
+	commitNonces := func(signers map[int64]bool) map[int64]*testhelper.NonceCommitment {
+		nonces := make(map[int64]*testhelper.NonceCommitment)
+		for posi := range signers {
+			nonces[posi] = participants[posi-1].CommitWeightedNonces(message_hash, signers)
+		}
+		return nonces
+	}
+	nonces := commitNonces(signers)
+	other_nonces := commitNonces(other_signers)
+	for posi := range signers {
+		assert.NoError(t, participants[posi-1].ReceiveWeightedNonces(message_hash, nonces))
+	}
+	for posi := range other_signers {
+		assert.NoError(t, participants[posi-1].ReceiveWeightedNonces(message_hash, other_nonces))
+	}
+
+	calculateShares := func(signers map[int64]bool) map[int64]*btcec.ModNScalar {
+		shares := make(map[int64]*btcec.ModNScalar)
+		for posi := range signers {
+			share, err := participants[posi-1].CalculateWeightedSignatureShare(message_hash, signers)
+			assert.NoError(t, err)
+			shares[posi] = share
+		}
+		return shares
+	}
+	shares := calculateShares(signers)
+	other_shares := calculateShares(other_signers)
+	sig, err := participants[0].AggregateWeightedSignatureShares(message_hash, shares)
+	assert.NoError(t, err)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].Frost.GroupPublicKey))
+	other_sig, err := participants[0].AggregateWeightedSignatureShares(message_hash, other_shares)
+	assert.NoError(t, err)
+	assert.True(t, other_sig.Verify(message_hash[:], participants[0].Frost.GroupPublicKey))
+
+	// a signer set of the same size with no committed session is rejected
+	mismatched_signers := map[int64]bool{1: true, 2: true, 3: true, 4: true, 5: true, 8: true}
+	_, err = participants[0].CalculateWeightedSignatureShare(message_hash, mismatched_signers)
+	assert.ErrorIs(t, err, testhelper.ErrMissingWeightedRound)
+	mismatched_nonces := make(map[int64]*testhelper.NonceCommitment)
+	for posi := range mismatched_signers {
+		mismatched_nonces[posi] = nonces[posi]
+	}
+	mismatched_nonces[8] = other_nonces[7]
+	assert.ErrorIs(t, participants[0].ReceiveWeightedNonces(message_hash, mismatched_nonces), testhelper.ErrMissingWeightedRound)
+
+	// a tampered share is attributed to its signer
+	tampered := make(map[int64]*btcec.ModNScalar)
+	for posi, share := range shares {
+		tampered[posi] = share
+	}
+	tampered[3] = new(btcec.ModNScalar).Add2(shares[3], new(btcec.ModNScalar).SetInt(1))
+	_, err = participants[0].AggregateWeightedSignatureShares(message_hash, tampered)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidWeightedShare)
+
+	// participants 7 to 10 hold 20 keys, far below the threshold
+	_, err = participants[6].CalculateWeightedSignatureShare(message_hash, map[int64]bool{7: true, 8: true, 9: true, 10: true})
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+	_, err = participants[6].CalculateWeightedSignatureShare(message_hash, signers)
+	assert.ErrorIs(t, err, testhelper.ErrNotWeightedSigner)
+}
+
+// go test -v -run ^TestWstsTransferKeys$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestWstsTransferKeys(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants := runWstsDKG(&suite, []int64{20, 5, 5}, 15)
+	group_key := participants[0].Frost.GroupPublicKey
+	weightedSign := func(signers map[int64]bool) error {
+		message_hash := suite.RandomMessage()
+		nonces := make(map[int64]*testhelper.NonceCommitment)
+		for posi := range signers {
+			nonces[posi] = participants[posi-1].CommitWeightedNonces(message_hash, signers)
+		}
+		shares := make(map[int64]*btcec.ModNScalar)
+		var aggregator *testhelper.WstsParticipant
+		for posi := range signers {
+			aggregator = participants[posi-1]
+			if err := participants[posi-1].ReceiveWeightedNonces(message_hash, nonces); err != nil {
+				return err
+			}
+			share, err := participants[posi-1].CalculateWeightedSignatureShare(message_hash, signers)
+			if err != nil {
+				return err
+			}
+			shares[posi] = share
+		}
+		sig, err := aggregator.AggregateWeightedSignatureShares(message_hash, shares)
+		if err != nil {
+			return err
+		}
+		assert.True(t, sig.Verify(message_hash[:], group_key))
+		return nil
+	}
+
+	// participants 2 and 3 hold 10 keys, participants 1 and 3 hold 25 keys
+	assert.ErrorIs(t, weightedSign(map[int64]bool{2: true, 3: true}), testhelper.ErrThresholdNotMet)
+	assert.NoError(t, weightedSign(map[int64]bool{1: true, 3: true}))
+
+	keys := make([]int64, 0, 10)
+	for key := range participants[0].Keys[1] {
+		if len(keys) < 10 {
+			keys = append(keys, key)
+		}
+	}
+	_, err := participants[0].TransferKeys(1, 1, keys)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidTransferRecipient)
+	_, err = participants[0].TransferKeys(1, 2, []int64{keys[0], 30})
+	assert.ErrorIs(t, err, testhelper.ErrKeyNotOwned)
+
+	transfer, err := participants[0].TransferKeys(1, 2, keys)
+	assert.NoError(t, err)
+	tampered := testhelper.TransferShares{From: 1, To: 2, Keys: keys, Shares: map[int64]*btcec.ModNScalar{}}
+	for key, share := range transfer.Shares {
+		tampered.Shares[key] = new(btcec.ModNScalar).Add2(share, new(btcec.ModNScalar).SetInt(1))
+	}
+	assert.ErrorIs(t, participants[1].AcceptKeys(tampered), testhelper.ErrInvalidTransferredShare)
+	assert.NoError(t, participants[1].AcceptKeys(transfer))
+	participants[2].ApplyKeyTransfer(transfer.From, transfer.To, transfer.Keys)
+
+	// group key is unchanged, the new owner gains 10 keys and the old owner loses them
+	assert.True(t, participants[2].Frost.CalculateGroupPublicKey().IsEqual(group_key))
+	assert.Len(t, participants[1].Keys[2], 15)
+	assert.Len(t, participants[0].Keys[1], 10)
+	assert.NoError(t, weightedSign(map[int64]bool{2: true, 3: true}))
+	assert.ErrorIs(t, weightedSign(map[int64]bool{1: true, 3: true}), testhelper.ErrThresholdNotMet)
+	_, err = participants[0].TransferKeys(1, 3, keys[:1])
+	assert.ErrorIs(t, err, testhelper.ErrKeyNotOwned)
+}
+
+// go test -v -run ^TestSignTaprootAllInputs$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestSignTaprootAllInputs(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	signers := map[int64]bool{1: true, 2: true, 4: true}
+
+	group_script, err := txscript.PayToTaprootScript(participants[0].GroupPublicKey)
+	assert.NoError(t, err)
+	bip86_script, err := txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(participants[0].GroupPublicKey))
+	assert.NoError(t, err)
+
+	// 3 inputs spending outputs of the group
+	prevOuts := []*wire.TxOut{
+		wire.NewTxOut(100000, group_script),
+		wire.NewTxOut(200000, bip86_script),
+		wire.NewTxOut(300000, group_script),
+	}
+	tx := wire.NewMsgTx(2)
+	prev_out_map := make(map[wire.OutPoint]*wire.TxOut)
+	for i, prevOut := range prevOuts {
+		outpoint := wire.OutPoint{Hash: sha256.Sum256([]byte{byte(i)}), Index: uint32(i)}
+		tx.AddTxIn(wire.NewTxIn(&outpoint, nil, nil))
+		prev_out_map[outpoint] = prevOut
+	}
+	tx.AddTxOut(wire.NewTxOut(590000, group_script))
+
+	assert.NoError(t, suite.SignTaprootAllInputs(tx, prevOuts, participants, signers))
+
+	fetcher := txscript.NewMultiPrevOutFetcher(prev_out_map)
+	sig_hashes := txscript.NewTxSigHashes(tx, fetcher)
+	for i, prevOut := range prevOuts {
+		assert.Len(t, tx.TxIn[i].Witness, 1)
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sig_hashes, prevOut.Value, fetcher)
+		assert.NoError(t, err)
+		assert.NoError(t, engine.Execute(), "input %d", i)
+	}
+
+	// distinct nonces for each input
+	assert.NotEqual(t, tx.TxIn[0].Witness[0][0:32], tx.TxIn[2].Witness[0][0:32])
+
+	// an output not controlled by the group
+	unsigned := tx.Copy()
+	for _, txIn := range unsigned.TxIn {
+		txIn.Witness = nil
+	}
+	other_script, err := txscript.PayToTaprootScript(participants[0].GetPublicSigningShares(1))
+	assert.NoError(t, err)
+	err = suite.SignTaprootAllInputs(unsigned, []*wire.TxOut{prevOuts[0], prevOuts[1], wire.NewTxOut(300000, other_script)}, participants, signers)
+	assert.ErrorIs(t, err, testhelper.ErrUnknownOutputKey)
+	for _, txIn := range unsigned.TxIn {
+		assert.Empty(t, txIn.Witness)
+	}
+}
+
+// go test -v -run ^TestFrostAttributablePartial$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostAttributablePartial(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 3, 5}
+	message_hash := sha256.Sum256([]byte("attributable"))
+
+	aggregator := testhelper.NewFrostAggregator(&suite, participants[0])
+	for _, participant := range participants {
+		aggregator.RegisterIdentityKey(participant.Position, participant.GenerateIdentityKey())
+	}
+
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi-1].GenerateSigningNonces(1)[0]
+	}
+	for _, posi := range honest {
+		_, err := participants[posi-1].CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+		assert.NoError(t, err)
+	}
+
+	partials := make(map[int64]*testhelper.AttributablePartial)
+	for _, posi := range honest {
+		attributable, err := participants[posi-1].PartialSignAttributable(posi, 0, honest, message_hash, public_nonces, signing_shares[posi])
+		assert.NoError(t, err)
+		partials[posi] = attributable
+	}
+	assert.NoError(t, aggregator.VerifyAttribution(message_hash, partials[3]))
+
+	sig, err := aggregator.AggregateAttributable(0, message_hash, partials)
+	assert.NoError(t, err)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].GroupPublicKey))
+
+	// the attribution of signer 1 does not attribute the partial of signer 3
+	forged := &testhelper.AttributablePartial{
+		Position:     3,
+		SigningIndex: 0,
+		Partial:      partials[3].Partial,
+		Attribution:  partials[1].Attribution,
+	}
+	assert.ErrorIs(t, aggregator.VerifyAttribution(message_hash, forged), testhelper.ErrInvalidAttribution)
+	// the attribution of signer 3 does not cover a substituted partial
+	substituted := &testhelper.AttributablePartial{
+		Position:     3,
+		SigningIndex: 0,
+		Partial:      partials[1].Partial,
+		Attribution:  partials[3].Attribution,
+	}
+	assert.ErrorIs(t, aggregator.VerifyAttribution(message_hash, substituted), testhelper.ErrInvalidAttribution)
+
+	partials[3] = forged
+	_, err = aggregator.AggregateAttributable(0, message_hash, partials)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidAttribution)
+
+	// signer without identity key
+	fresh := testhelper.NewFrostParticipant(&suite, log.Default(), 5, 2, 1, nil)
+	_, err = fresh.PartialSignAttributable(1, 0, honest, message_hash, public_nonces, signing_shares[1])
+	assert.ErrorIs(t, err, testhelper.ErrMissingIdentityKey)
+}
+
+// go test -v -run ^TestFrostDetectConflictingPartials$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDetectConflictingPartials(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 2, 4}
+	message_hash := sha256.Sum256([]byte("conflicting partials"))
+
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message_hash)
+	aggregator := testhelper.NewFrostAggregator(&suite, participants[0])
+	for posi, p_sig := range partial_sigs {
+		aggregator.SubmitPartial(0, posi, p_sig)
+	}
+	// resubmitting the same partial is not a conflict
+	aggregator.SubmitPartial(0, 2, partial_sigs[2])
+	conflicts, err := aggregator.DetectConflictingPartials()
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	// a faulty signer 2 submits a second partial for the same nonce, e.g. computed with a different share
+	// an honest signer refuses to sign twice with a nonce pair, thus the partial is forged here
+	first_bytes := partial_sigs[2].Serialize()
+	R_x := new(btcec.FieldVal)
+	R_x.SetByteSlice(first_bytes[0:32])
+	other_z := new(btcec.ModNScalar)
+	other_z.SetByteSlice(first_bytes[32:64])
+	other_z.Add(new(btcec.ModNScalar).SetInt(1))
+	other_sig := schnorr.NewSignature(R_x, other_z)
+	aggregator.SubmitPartial(0, 2, other_sig)
+
+	conflicts, err = aggregator.DetectConflictingPartials()
+	assert.ErrorIs(t, err, testhelper.ErrConflictingPartials)
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, int64(0), conflicts[0].SigningIndex)
+	assert.Equal(t, int64(2), conflicts[0].Position)
+	assert.Equal(t, partial_sigs[2].Serialize(), conflicts[0].First.Serialize())
+	assert.Equal(t, other_sig.Serialize(), conflicts[0].Second.Serialize())
+
+	// the same signer in another session is tracked separately
+	aggregator.SubmitPartial(1, 2, other_sig)
+	conflicts, _ = aggregator.DetectConflictingPartials()
+	assert.Len(t, conflicts, 1)
+}
+
+// go test -v -run ^TestFrostSignRound$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSignRound(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	signers := map[int64]bool{1: true, 2: true, 4: true}
+	message_hash := sha256.Sum256([]byte("sign round"))
+
+	// repeat DKG until both parities of the group public key are covered
+	seen_parity := make(map[byte]bool)
+	for attempt := 0; attempt < 32 && len(seen_parity) < 2; attempt++ {
+		participants, _ := runFrostDKG(&suite, 5, 2)
+		group_key := participants[0].CalculateGroupPublicKey()
+		seen_parity[group_key.SerializeCompressed()[0]] = true
+
+		rounds := make(map[int64]*testhelper.SignRound)
+		for posi := range signers {
+			rounds[posi] = participants[posi-1].NewSignRound()
+		}
+		// R is not derived before the signature shares
+		_, err := rounds[1].Aggregate(map[int64]*btcec.ModNScalar{})
+		assert.ErrorIs(t, err, testhelper.ErrMissingAggrNonce)
+
+		// round 1: broadcast nonce commitments
+		for posi, round := range rounds {
+			D, E := round.GenerateSigningNonces(message_hash)
+			for other, other_round := range rounds {
+				if other != posi {
+					other_round.ReceiveCommitments(posi, D, E)
+				}
+			}
+		}
+		// every signer derives the same binding factors
+		for posi := range signers {
+			assert.True(t, rounds[1].ComputeBindingFactor(posi, signers).Equals(rounds[4].ComputeBindingFactor(posi, signers)))
+		}
+
+		// round 2: signature shares
+		shares := make(map[int64]*btcec.ModNScalar)
+		for posi, round := range rounds {
+			shares[posi] = round.ComputeSignatureShare(message_hash, signers)
+		}
+
+		sig, err := rounds[1].Aggregate(shares)
+		assert.NoError(t, err)
+		assert.True(t, sig.Verify(message_hash[:], group_key), "group key prefix %x", group_key.SerializeCompressed()[0])
+	}
+	assert.Len(t, seen_parity, 2)
+}
+
+// go test -v -run ^TestFrostNonceHistory$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostNonceHistory(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	honest := []int64{1, 2, 3}
+	message_hash := sha256.Sum256([]byte("first session"))
+
+	suite.SetTestRandSource(testhelper.NewTestRandSource(11))
+	partial_sigs := runFrostSigning(participants, signing_shares, honest, message_hash)
+	sig := testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].GroupPublicKey))
+	history := participants[0].NonceHistory()
+	assert.Len(t, history, 1)
+
+	// the secret nonces are gone once the partial signature is produced, a retry resends the partial signature
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi-1].NonceCommitments[0]
+	}
+	_, err := participants[0].PartialSignForSighash(1, 0, honest, message_hash, public_nonces, signing_shares[1])
+	assert.ErrorIs(t, err, testhelper.ErrNonceReused)
+	assert.Equal(t, history, participants[0].NonceHistory())
+
+	// replayed randomness regenerates the nonce commitments of the first session
+	suite.SetTestRandSource(testhelper.NewTestRandSource(11))
+	other_message := sha256.Sum256([]byte("second session"))
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi-1].GenerateSigningNonces(1)[0]
+	}
+	for _, posi := range honest {
+		_, err := participants[posi-1].CalculatePublicNonceCommitments(0, honest, other_message, public_nonces)
+		assert.NoError(t, err)
+	}
+
+	_, err = participants[0].PartialSignForSighash(1, 0, honest, other_message, public_nonces, signing_shares[1])
+	assert.ErrorIs(t, err, testhelper.ErrNonceReused)
+
+	recorder := &recordingT{}
+	suite.T = recorder
+	assert.Nil(t, participants[0].PartialSign(1, 0, honest, other_message, public_nonces, signing_shares[1]))
+	assert.NotEmpty(t, recorder.errors)
+	suite.T = t
+
+	// fresh randomness signs again
+	suite.SetTestRandSource(nil)
+	partial_sigs = runFrostSigning(participants, signing_shares, honest, other_message)
+	sig = testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(other_message[:], participants[0].GroupPublicKey))
+	assert.Len(t, participants[0].NonceHistory(), 2)
+}
+
+// go test -v -run ^TestCalculateLagrangeCoeffsBatch$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestCalculateLagrangeCoeffsBatch(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	sets := [][]int64{
+		{3},
+		{1, 2},
+		{1, 3, 4, 7, 10},
+		{2, 5, 8, 11, 14, 17, 20, 23, 26, 29, 32, 35},
+	}
+	for _, set := range sets {
+		coefficients := suite.CalculateLagrangeCoeffs(set)
+		assert.Len(t, coefficients, len(set))
+
+		sum := new(btcec.ModNScalar)
+		for _, i := range set {
+			assert.True(t, suite.CalculateLagrangeCoeff(i, set).Equals(coefficients[i]), "set %v, position %d", set, i)
+			sum.Add(coefficients[i])
+		}
+		assert.True(t, sum.Equals(new(btcec.ModNScalar).SetInt(1)), "set %v", set)
+	}
+
+	values := []*btcec.ModNScalar{new(btcec.ModNScalar).SetInt(5), new(btcec.ModNScalar).SetInt(9), new(btcec.ModNScalar).SetInt(1)}
+	for k, inverse := range testhelper.BatchInverse(values) {
+		assert.True(t, new(btcec.ModNScalar).InverseValNonConst(values[k]).Equals(inverse))
+	}
+	assert.Empty(t, testhelper.BatchInverse(nil))
+}
+
+// go test -v -run ^TestFrostAggregateSignatureShares$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostAggregateSignatureShares(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(1000)
+	threshold := int64(700)
+	dealer, group_key, signing_shares := singleDealerShares(&suite, n, threshold)
+
+	honest := make([]int64, threshold+1)
+	for i := range honest {
+		honest[i] = int64(i + 1)
+	}
+
+	// signers only need their nonces, the group public key and the aggregated nonce commitment
+	message_hash := suite.RandomMessage()
+	signers := make(map[int64]*testhelper.FrostParticipant)
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	nonces := make(map[int64]*testhelper.NonceCommitment)
+	for _, posi := range honest {
+		signers[posi] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 0, posi, nil)
+		signers[posi].GroupPublicKey = group_key
+		public_nonces[posi] = signers[posi].GenerateSigningNonces(1)[0]
+		nonces[posi] = &testhelper.NonceCommitment{D: public_nonces[posi][0], E: public_nonces[posi][1]}
+	}
+	_, err := dealer.CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+	assert.NoError(t, err)
+
+	shares := make(map[int64]*btcec.ModNScalar)
+	for _, posi := range honest {
+		signers[posi].AggrNonceCommitment[0] = dealer.AggrNonceCommitment[0]
+		partial_sig := signers[posi].PartialSign(posi, 0, honest, message_hash, public_nonces, signing_shares[posi])
+		shares[posi] = new(btcec.ModNScalar)
+		shares[posi].SetByteSlice(partial_sig.Serialize()[32:64])
+	}
+
+	aggregator := testhelper.NewFrostAggregator(&suite, dealer)
+	sig, err := aggregator.AggregateSignatureShares(message_hash, shares, nonces)
+	assert.NoError(t, err)
+	assert.True(t, sig.Verify(message_hash[:], group_key))
+	assert.NoError(t, aggregator.VerifyAggregatedSignature(message_hash, sig))
+	assert.ErrorIs(t, aggregator.VerifyAggregatedSignature(sha256.Sum256(message_hash[:]), sig), testhelper.ErrInvalidAggregatedSignature)
+
+	// a garbage share is attributed to its signer
+	shares[42] = new(btcec.ModNScalar).SetInt(42)
+	_, err = aggregator.AggregateSignatureShares(message_hash, shares, nonces)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidSignatureShare)
+	assert.Contains(t, err.Error(), "signer 42")
+
+	// not enough shares
+	delete(shares, 42)
+	_, err = aggregator.AggregateSignatureShares(message_hash, shares, nonces)
+	assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+}
+
+// go test -v -run ^TestFrostParticipantLagrangeCoeff$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostParticipantLagrangeCoeff(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participant := testhelper.NewFrostParticipant(&suite, log.Default(), 7, 2, 1, nil)
+
+	// naive reference over big integers modulo the curve order
+	reference := func(i int64, set []int64) *big.Int {
+		order := btcec.S256().N
+		lambda := big.NewInt(1)
+		for _, j := range set {
+			if j == i {
+				continue
+			}
+			denominator := new(big.Int).Mod(big.NewInt(j-i), order)
+			lambda.Mul(lambda, big.NewInt(j))
+			lambda.Mul(lambda, new(big.Int).ModInverse(denominator, order))
+			lambda.Mod(lambda, order)
+		}
+		return lambda
+	}
+
+	sets := [][]int64{{1, 2}, {1, 3, 5}, {2, 4, 6, 7}, {1, 2, 3, 4, 5, 6, 7}}
+	for _, set := range sets {
+		signers := make(map[int64]bool)
+		for _, j := range set {
+			signers[j] = true
+		}
+		for _, i := range set {
+			lambda := participant.CalculateLagrangeCoeff(i, signers)
+			lambda_bytes := lambda.Bytes()
+			assert.Equal(t, 0, reference(i, set).Cmp(new(big.Int).SetBytes(lambda_bytes[:])), "set %v, position %d", set, i)
+		}
+	}
+
+	// repeated quorums hit the cache, returned values are copies
+	cached := participant.LagrangeCacheLen()
+	assert.Equal(t, 2+3+4+7, cached)
+	lambda := participant.CalculateLagrangeCoeff(3, map[int64]bool{5: true, 1: true, 3: true})
+	assert.Equal(t, cached, participant.LagrangeCacheLen())
+	lambda.SetInt(0)
+	assert.False(t, participant.CalculateLagrangeCoeff(3, map[int64]bool{1: true, 3: true, 5: true}).IsZero())
+
+	// a change of the participant set drops the cache
+	assert.NoError(t, participant.UpdateParticipantCount(9))
+	assert.Equal(t, 0, participant.LagrangeCacheLen())
+}
+
+// go test -v -run ^TestFrostSignChannelCommitment$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostSignChannelCommitment(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	signers := map[int64]bool{1: true, 3: true, 5: true}
+	counterparty, err := btcec.NewPrivateKey()
+	assert.NoError(t, err)
+
+	funding_out, witness_template, err := participants[0].ChannelFundingOutput(counterparty.PubKey(), 1000000)
+	assert.NoError(t, err)
+
+	// commitment transaction spending the funding outpoint into to_local and to_remote outputs
+	to_local, err := txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(participants[0].GroupPublicKey))
+	assert.NoError(t, err)
+	to_remote, err := txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(counterparty.PubKey()))
+	assert.NoError(t, err)
+	commit_tx := wire.NewMsgTx(2)
+	commit_tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: sha256.Sum256([]byte("funding")), Index: 0}, nil, nil))
+	commit_tx.AddTxOut(wire.NewTxOut(600000, to_local))
+	commit_tx.AddTxOut(wire.NewTxOut(399000, to_remote))
+
+	// the counterparty signs first, as when sending its signature for our commitment
+	fetcher := txscript.NewCannedPrevOutputFetcher(funding_out.PkScript, funding_out.Value)
+	sig_hashes := txscript.NewTxSigHashes(commit_tx, fetcher)
+	leaf := txscript.NewBaseTapLeaf(witness_template[0])
+	sighash, err := txscript.CalcTapscriptSignaturehash(sig_hashes, txscript.SigHashDefault, commit_tx, 0, fetcher, leaf)
+	assert.NoError(t, err)
+	counterparty_sig, err := schnorr.Sign(counterparty, sighash)
+	assert.NoError(t, err)
+	commit_tx.TxIn[0].Witness = append(wire.TxWitness{counterparty_sig.Serialize()}, witness_template...)
+
+	assert.NoError(t, suite.SignChannelCommitment(commit_tx, funding_out, participants, signers))
+	assert.Len(t, commit_tx.TxIn[0].Witness, 4)
+
+	// the group signature portion verifies under the group key
+	group_sig, err := schnorr.ParseSignature(commit_tx.TxIn[0].Witness[1])
+	assert.NoError(t, err)
+	assert.True(t, group_sig.Verify(sighash, participants[0].GroupPublicKey))
+
+	engine, err := txscript.NewEngine(funding_out.PkScript, commit_tx, 0, txscript.StandardVerifyFlags, nil, sig_hashes, funding_out.Value, fetcher)
+	assert.NoError(t, err)
+	assert.NoError(t, engine.Execute())
+
+	// a witness without the funding template, and a funding output of another channel
+	unsigned := commit_tx.Copy()
+	unsigned.TxIn[0].Witness = wire.TxWitness{counterparty_sig.Serialize()}
+	assert.ErrorIs(t, suite.SignChannelCommitment(unsigned, funding_out, participants, signers), testhelper.ErrInvalidChannelWitness)
+	other_counterparty, err := btcec.NewPrivateKey()
+	assert.NoError(t, err)
+	other_out, _, err := participants[0].ChannelFundingOutput(other_counterparty.PubKey(), 1000000)
+	assert.NoError(t, err)
+	unsigned.TxIn[0].Witness = witness_template
+	assert.ErrorIs(t, suite.SignChannelCommitment(unsigned, other_out, participants, signers), testhelper.ErrFundingScriptMismatch)
+}
+
+// go test -v -run ^TestFrostRefreshPublicSigningShares$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostRefreshPublicSigningShares(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	threshold := int64(2)
+	participants, signing_shares := runFrostDKG(&suite, n, threshold)
+	group_key := participants[0].GroupPublicKey
+	old_public_share := participants[0].GetPublicSigningShares(3)
+
+	// every participant deals a refresh polynomial, only the dealers in the agreed set are applied
+	refresh := func(dealers []int64) map[int64]*btcec.ModNScalar {
+		for i := int64(0); i < n; i++ {
+			commitments := participants[i].GenerateRefreshPolynomial()
+			for j := int64(0); j < n; j++ {
+				if i != j {
+					participants[j].UpdateRefreshCommitments(i+1, commitments)
+				}
+			}
+		}
+		for j := int64(0); j < n; j++ {
+			refresh_shares := make(map[int64]*btcec.ModNScalar)
+			for _, dealer := range dealers {
+				refresh_shares[dealer] = participants[dealer-1].RefreshShare(j + 1)
+			}
+			assert.NoError(t, participants[j].ApplyRefreshShares(dealers, refresh_shares))
+		}
+		for _, participant := range participants {
+			assert.NoError(t, participant.RefreshPublicSigningShares(dealers))
+		}
+
+		// new public shares match the new private shares
+		refreshed := make(map[int64]*btcec.ModNScalar)
+		for j := int64(0); j < n; j++ {
+			refreshed[j+1] = participants[j].GetSigningShares()
+			for _, participant := range participants {
+				assert.True(t, participant.GetPublicSigningShares(j+1).IsEqual(btcec.PrivKeyFromScalar(refreshed[j+1]).PubKey()))
+			}
+		}
+		return refreshed
+	}
+	refreshed := refresh([]int64{1, 2, 3, 4, 5})
+	for j := int64(1); j <= n; j++ {
+		assert.False(t, refreshed[j].Equals(signing_shares[j]))
+	}
+	assert.False(t, participants[0].GetPublicSigningShares(3).IsEqual(old_public_share))
+
+	// dealers 2 and 5 dealt but were dropped, their stored commitments must not reach the public shares
+	refreshed = refresh([]int64{1, 3, 4})
+	recovered_key, err := testhelper.GroupKeyFromPublicShares(dkgResult(t, participants[1]).PublicSigningShares, threshold)
+	assert.NoError(t, err)
+	assert.True(t, recovered_key.IsEqual(group_key))
+
+	// refreshed shares still sign for the group
+	honest := []int64{1, 2, 4}
+	message_hash := sha256.Sum256([]byte("frost refresh"))
+	partial_sigs := runFrostSigning(participants, refreshed, honest, message_hash)
+	sig := testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], group_key))
+
+	// a tampered refresh share is refused and leaves the signing share untouched
+	participants[1].UpdateRefreshCommitments(1, participants[0].GenerateRefreshPolynomial())
+	bad_share := participants[0].RefreshShare(2)
+	bad_share.Add(new(btcec.ModNScalar).SetInt(1))
+	err = participants[1].ApplyRefreshShares([]int64{1}, map[int64]*btcec.ModNScalar{1: bad_share})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidRefreshShare)
+	assert.True(t, participants[1].GetSigningShares().Equals(refreshed[2]))
+	err = participants[1].ApplyRefreshShares([]int64{4}, map[int64]*btcec.ModNScalar{4: bad_share})
+	assert.ErrorIs(t, err, testhelper.ErrMissingRefreshCommitments)
+
+	// shares outside the dealer set, or dealers without a share, are refused
+	err = participants[1].ApplyRefreshShares([]int64{1}, map[int64]*btcec.ModNScalar{1: bad_share, 4: bad_share})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidRefreshDealers)
+	err = participants[1].ApplyRefreshShares([]int64{1, 4}, map[int64]*btcec.ModNScalar{1: bad_share})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidRefreshDealers)
+	err = participants[1].ApplyRefreshShares([]int64{1, 1}, map[int64]*btcec.ModNScalar{1: bad_share})
+	assert.ErrorIs(t, err, testhelper.ErrInvalidRefreshDealers)
+	assert.True(t, participants[1].GetSigningShares().Equals(refreshed[2]))
+	assert.ErrorIs(t, participants[1].RefreshPublicSigningShares([]int64{4}), testhelper.ErrMissingRefreshCommitments)
+}
+
+// go test -v -run ^TestFrostReshareRaiseThreshold$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostReshareRaiseThreshold(t *testing.T) {
+	cases := []struct {
+		name                        string
+		n, threshold, new_threshold int64
+		slow                        bool
+	}{
+		{"10-4-to-6", 10, 4, 6, false},
+		// the 700 to 800 case of a 1000 participants group takes several seconds, skipped with -short
+		{"1000-700-to-800", 1000, 700, 800, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.slow && testing.Short() {
+				t.Skip("large reshare skipped in short mode")
+			}
+			testFrostReshareRaiseThreshold(t, c.n, c.threshold, c.new_threshold)
+		})
+	}
+
+	// participants holding the Q map extend it to the new degree, public signing shares follow the new shares
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+	group, _ := runFrostDKG(&suite, 5, 2)
+	transcripts := make([]*testhelper.ReshareTranscript, 0, len(group))
+	for _, participant := range group {
+		transcript, err := participant.Reshare(3, []int64{1, 2, 3, 4, 5})
+		assert.NoError(t, err)
+		transcripts = append(transcripts, transcript)
+	}
+	for _, participant := range group {
+		assert.NoError(t, participant.ApplyReshare(transcripts))
+		assert.NoError(t, participant.RefreshPublicSigningShares(testhelper.ReshareDealers(transcripts)))
+	}
+	for _, participant := range group {
+		for j, other := range group {
+			expected := btcec.PrivKeyFromScalar(other.GetSigningShares()).PubKey()
+			assert.True(t, expected.IsEqual(participant.GetPublicSigningShares(int64(j+1))))
+		}
+	}
+}
+
+func testFrostReshareRaiseThreshold(t *testing.T, n, threshold, new_threshold int64) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	dealer, group_key, signing_shares := singleDealerShares(&suite, n, threshold)
+
+	all := make([]int64, n)
+	participants := make(map[int64]*testhelper.FrostParticipant)
+	for posi := int64(1); posi <= n; posi++ {
+		all[posi-1] = posi
+		participants[posi] = testhelper.NewFrostParticipant(&suite, log.Default(), n, 0, posi, nil)
+		participants[posi].Threshold = threshold
+		delete(participants[posi].PolynomialCommitments, posi)
+		participants[posi].UpdatePolynomialCommitments(1, dealer.PolynomialCommitments[1])
+		participants[posi].StoreSigningShares(signing_shares[posi])
+	}
+
+	// the zero secret polynomial cannot lower the degree of the sharing
+	_, err := participants[1].Reshare(threshold-1, all)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidReshareParameters)
+	_, err = participants[1].Reshare(n, all)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidReshareParameters)
+
+	// a single reshare dealer, every participant verifies its correction share against t' commitments
+	transcript, err := participants[n/2].Reshare(new_threshold, all)
+	assert.NoError(t, err)
+	assert.Len(t, transcript.Commitments, int(new_threshold))
+	transcripts := []*testhelper.ReshareTranscript{transcript}
+	for posi := int64(1); posi <= n; posi++ {
+		assert.NoError(t, participants[posi].ApplyReshare(transcripts))
+		assert.Equal(t, new_threshold, participants[posi].Threshold)
+		assert.True(t, group_key.IsEqual(participants[posi].CalculateGroupPublicKey()))
+	}
+
+	// Y = \sum_{i \in S} \lambda_i * s_i' * G needs t' + 1 shares now
+	interpolate := func(quorum []int64) *btcec.PublicKey {
+		lambdas := suite.CalculateLagrangeCoeffs(quorum)
+		secret := new(btcec.ModNScalar)
+		for _, posi := range quorum {
+			secret.Add(new(btcec.ModNScalar).Mul2(lambdas[posi], participants[posi].GetSigningShares()))
+		}
+		return btcec.PrivKeyFromScalar(secret).PubKey()
+	}
+	assert.True(t, group_key.IsEqual(interpolate(all[:new_threshold+1])))
+	assert.False(t, group_key.IsEqual(interpolate(all[:threshold+1])))
+
+	// the new quorum signs
+	honest := all[:new_threshold+1]
+	message_hash := suite.RandomMessage()
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi].GenerateSigningNonces(1)[0]
+	}
+	_, err = participants[1].CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+	assert.NoError(t, err)
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	for _, posi := range honest {
+		participants[posi].AggrNonceCommitment[0] = participants[1].AggrNonceCommitment[0]
+		partial_sigs[posi] = participants[posi].PartialSign(posi, 0, honest, message_hash, public_nonces, participants[posi].GetSigningShares())
+	}
+	sig := testhelper.NewFrostAggregator(&suite, participants[1]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], group_key))
+
+	// a correction share off the dealer commitments is refused, the signing share is kept
+	signing_share := participants[2].GetSigningShares()
+	transcripts[0].Shares[2] = new(btcec.ModNScalar).SetInt(2)
+	assert.ErrorIs(t, participants[2].ApplyReshare(transcripts), testhelper.ErrInvalidRefreshShare)
+	assert.True(t, signing_share.Equals(participants[2].GetSigningShares()))
+}
+
+// go test -v -run ^TestFrostEnrollRevokeParticipant$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostEnrollRevokeParticipant(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(5)
+	threshold := int64(2)
+	participants, signing_shares := runFrostDKG(&suite, n, threshold)
+	group_key := participants[0].GroupPublicKey
+
+	_, err := participants[0].EnrollParticipant(n + 2)
+	assert.ErrorIs(t, err, testhelper.ErrInvalidEnrollmentPosition)
+
+	// helpers 1, 2, 4 mask the shares of members 2, 3, 5 sent to the newcomer 6
+	enrollments := make([]testhelper.EnrollmentShares, 0)
+	for _, posi := range []int64{1, 2, 4} {
+		enrollment, err := participants[posi-1].EnrollParticipant(n + 1)
+		assert.NoError(t, err)
+		assert.Len(t, enrollment.Masks, int(n))
+		enrollments = append(enrollments, enrollment)
+	}
+	newcomer := testhelper.NewEnrollee(&suite, log.Default(), n+1, threshold, n+1, participants[0].PolynomialCommitments)
+	assert.True(t, group_key.IsEqual(newcomer.GroupPublicKey))
+	enrollment_shares := make(map[int64]*btcec.ModNScalar)
+	for _, posi := range []int64{2, 3, 5} {
+		enrollment_shares[posi], err = participants[posi-1].ProvideEnrollmentShare(enrollments)
+		assert.NoError(t, err)
+		assert.False(t, enrollment_shares[posi].Equals(signing_shares[posi]))
+	}
+	signing_shares[n+1], err = newcomer.CompleteEnrollment(enrollment_shares)
+	assert.NoError(t, err)
+	participants = append(participants, newcomer)
+
+	// the newcomer signs with the existing members
+	message_hash := sha256.Sum256([]byte("enrolled"))
+	partial_sigs := runFrostSigning(participants, signing_shares, []int64{1, 4, 6}, message_hash)
+	sig := testhelper.NewFrostAggregator(&suite, participants[0]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], group_key))
+
+	// every remaining member revokes 5 and refreshes the shares of the others
+	remaining := []int64{1, 2, 3, 4, 6}
+	transcripts := make([]*testhelper.ReshareTranscript, 0)
+	for _, posi := range remaining {
+		transcript, err := participants[posi-1].RevokeParticipant(5)
+		assert.NoError(t, err)
+		assert.Equal(t, remaining, transcript.Participants)
+		transcripts = append(transcripts, transcript)
+	}
+	for _, posi := range remaining {
+		assert.NoError(t, participants[posi-1].ApplyReshare(transcripts))
+		signing_shares[posi] = participants[posi-1].GetSigningShares()
+	}
+	_, err = participants[0].RevokeParticipant(5)
+	assert.ErrorIs(t, err, testhelper.ErrRevokedParticipant)
+
+	// the revoked member is refused as a signer
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range []int64{1, 2, 5} {
+		public_nonces[posi] = participants[posi-1].GenerateSigningNonces(1)[0]
+	}
+	_, err = participants[0].CalculatePublicNonceCommitments(0, []int64{1, 2, 5}, message_hash, public_nonces)
+	assert.ErrorIs(t, err, testhelper.ErrRevokedParticipant)
+
+	// and its stale share no longer completes a quorum
+	interpolate := func(quorum []int64) *btcec.PublicKey {
+		lambdas := suite.CalculateLagrangeCoeffs(quorum)
+		secret := new(btcec.ModNScalar)
+		for _, posi := range quorum {
+			secret.Add(new(btcec.ModNScalar).Mul2(lambdas[posi], signing_shares[posi]))
+		}
+		return btcec.PrivKeyFromScalar(secret).PubKey()
+	}
+	assert.False(t, group_key.IsEqual(interpolate([]int64{1, 2, 5})))
+	assert.True(t, group_key.IsEqual(interpolate([]int64{1, 2, 6})))
+
+	message_hash = sha256.Sum256([]byte("revoked"))
+	partial_sigs = runFrostSigning(participants, signing_shares, []int64{2, 3, 6}, message_hash)
+	sig = testhelper.NewFrostAggregator(&suite, participants[1]).AggregatePartialSignatures(0, partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], group_key))
+}
+
+// go test -v -run ^TestFrostRoastUnresponsiveSigners$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostRoastUnresponsiveSigners(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	n := int64(1000)
+	threshold := int64(99)
+	dealer, group_key, signing_shares := singleDealerShares(&suite, n, threshold)
+
+	// 100 signers commit but never answer, 5 send garbage shares
+	release := make(chan struct{})
+	defer close(release)
+	pool := make([]testhelper.RoastSigner, 0, n)
+	for posi := int64(1); posi <= n; posi++ {
+		participant := testhelper.NewFrostParticipant(&suite, log.Default(), n, 0, posi, nil)
+		participant.GroupPublicKey = group_key
+		participant.StoreSigningShares(signing_shares[posi])
+		signer := testhelper.NewFrostRoastSigner(participant)
+		switch {
+		case posi%10 == 3:
+			pool = append(pool, &unresponsiveRoastSigner{FrostRoastSigner: signer, release: release})
+		case posi%200 == 7:
+			pool = append(pool, &faultyRoastSigner{FrostRoastSigner: signer})
+		default:
+			pool = append(pool, signer)
+		}
+	}
+
+	message_hash := suite.RandomMessage()
+	coordinator := testhelper.NewRoastCoordinator(&suite, dealer)
+	sig, err := coordinator.Sign(message_hash, pool, threshold)
+	assert.NoError(t, err)
+	assert.True(t, sig.Verify(message_hash[:], group_key))
+	assert.Greater(t, coordinator.Sessions, int64(1))
+	assert.True(t, coordinator.Malicious(7))
+	assert.False(t, coordinator.Malicious(3))
+
+	// a pool below the quorum is refused right away
+	_, err = coordinator.Sign(message_hash, pool[:threshold], threshold)
+	assert.ErrorIs(t, err, testhelper.ErrRoastNotEnoughSigners)
+
+	// a coordinator announcing an R other than the one of the signers nonces is refused
+	session := &testhelper.RoastSession{
+		ID:      1,
+		Message: message_hash,
+		Signers: []int64{1, 2},
+		Nonces:  make(map[int64][2]*btcec.PublicKey),
+	}
+	signers := make([]*testhelper.FrostRoastSigner, 0, 2)
+	for _, posi := range session.Signers {
+		participant := testhelper.NewFrostParticipant(&suite, log.Default(), n, 0, posi, nil)
+		participant.GroupPublicKey = group_key
+		participant.StoreSigningShares(signing_shares[posi])
+		signer := testhelper.NewFrostRoastSigner(participant)
+		commitment, err := signer.Commit(message_hash)
+		assert.NoError(t, err)
+		session.Nonces[posi] = [2]*btcec.PublicKey{commitment.D, commitment.E}
+		signers = append(signers, signer)
+	}
+	session.R = new(btcec.JacobianPoint)
+	group_key.AsJacobian(session.R)
+	_, _, err = signers[0].Sign(session)
+	assert.ErrorIs(t, err, testhelper.ErrRoastSessionNonce)
+}
+
+// go test -v -run ^TestFrostDeterministicNonces$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostDeterministicNonces(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	message_hash := sha256.Sum256([]byte("deterministic"))
+
+	// a participant restored with the same share reproduces the same nonces
+	restored := testhelper.NewFrostParticipant(&suite, log.Default(), 5, 2, 1, nil)
+	restored.StoreSigningShares(signing_shares[1])
+	_, nonces, err := participants[0].GenerateDeterministicNonces(message_hash, []byte("session"))
+	assert.NoError(t, err)
+	_, restored_nonces, err := restored.GenerateDeterministicNonces(message_hash, []byte("session"))
+	assert.NoError(t, err)
+	assert.True(t, nonces[0].IsEqual(restored_nonces[0]))
+	assert.True(t, nonces[1].IsEqual(restored_nonces[1]))
+	assert.False(t, nonces[0].IsEqual(nonces[1]))
+
+	// the counter, the message, the extra data and the share all change the nonces
+	_, next_nonces, err := participants[0].GenerateDeterministicNonces(message_hash, []byte("session"))
+	assert.NoError(t, err)
+	assert.False(t, nonces[0].IsEqual(next_nonces[0]))
+	_, other_nonces, err := restored.GenerateDeterministicNonces(sha256.Sum256([]byte("other")), []byte("session"))
+	assert.NoError(t, err)
+	assert.False(t, next_nonces[0].IsEqual(other_nonces[0]))
+	assert.False(t, next_nonces[1].IsEqual(other_nonces[1]))
+	_, extra_nonces, err := participants[1].GenerateDeterministicNonces(message_hash, []byte("session"))
+	assert.NoError(t, err)
+	assert.False(t, nonces[0].IsEqual(extra_nonces[0]))
+
+	_, _, err = testhelper.NewFrostParticipant(&suite, log.Default(), 5, 2, 1, nil).GenerateDeterministicNonces(message_hash, nil)
+	assert.ErrorIs(t, err, testhelper.ErrMissingSigningShare)
+
+	// deterministic nonces sign like random ones, and stay bound to their message
+	honest := []int64{2, 3, 5}
+	signing_indices := make(map[int64]int64)
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		signing_indices[posi], public_nonces[posi], err = participants[posi-1].GenerateDeterministicNonces(message_hash, nil)
+		assert.NoError(t, err)
+	}
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	for _, posi := range honest {
+		_, err = participants[posi-1].CalculatePublicNonceCommitments(signing_indices[posi], honest, message_hash, public_nonces)
+		assert.NoError(t, err)
+		partial_sigs[posi], err = participants[posi-1].PartialSignForSighash(posi, signing_indices[posi], honest, message_hash, public_nonces, signing_shares[posi])
+		assert.NoError(t, err)
+	}
+	sig := testhelper.NewFrostAggregator(&suite, participants[1]).AggregatePartialSignatures(signing_indices[2], partial_sigs)
+	assert.True(t, sig.Verify(message_hash[:], participants[0].GroupPublicKey))
+
+	_, err = participants[1].PartialSignForSighash(2, signing_indices[2], honest, sha256.Sum256([]byte("other")), public_nonces, signing_shares[2])
+	assert.ErrorIs(t, err, testhelper.ErrNonceBoundToOtherSighash)
+}
+
+// go test -v -run ^TestFrostMuSig2Hybrid$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostMuSig2Hybrid(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	// several groups and both key orders, so that the group key is either the first or the second MuSig2 key
+	for run := 0; run < 4; run++ {
+		participants, _ := runFrostDKG(&suite, 5, 2)
+		external, err := btcec.NewPrivateKey()
+		assert.NoError(t, err)
+		keys := []*btcec.PublicKey{participants[0].AsMuSig2Key(), external.PubKey()}
+		if run%2 == 1 {
+			keys = []*btcec.PublicKey{external.PubKey(), participants[0].AsMuSig2Key()}
+		}
+		aggregate_key, _, _, err := musig2.AggregateKeys(keys, false)
+		assert.NoError(t, err)
+		message_hash := suite.RandomMessage()
+
+		// the quorum preprocesses two nonce pairs per signer for the public nonce of the group
+		honest := []int64{1, 3, 5}
+		signing_indices := make(map[int64][2]int64)
+		var public_nonces [2]map[int64][2]*btcec.PublicKey
+		for k := range public_nonces {
+			public_nonces[k] = make(map[int64][2]*btcec.PublicKey)
+		}
+		for _, posi := range honest {
+			var indices [2]int64
+			for k := range indices {
+				indices[k], public_nonces[k][posi] = participants[posi-1].PreprocessNoncesForSighash(message_hash)
+			}
+			signing_indices[posi] = indices
+		}
+		aggregator := testhelper.NewFrostAggregator(&suite, participants[0])
+		group_nonce, err := aggregator.MuSig2PublicNonce(message_hash, honest, public_nonces)
+		assert.NoError(t, err)
+
+		external_nonce, err := musig2.GenNonces(musig2.WithPublicKey(external.PubKey()))
+		assert.NoError(t, err)
+		combined_nonce, err := musig2.AggregateNonces([][musig2.PubNonceSize]byte{group_nonce, external_nonce.PubNonce})
+		assert.NoError(t, err)
+
+		shares := make(map[int64]*btcec.ModNScalar)
+		for _, posi := range honest {
+			shares[posi], err = participants[posi-1].MuSig2SignShare(signing_indices[posi], honest, message_hash, public_nonces, combined_nonce, keys)
+			assert.NoError(t, err)
+		}
+		group_sig, err := aggregator.MuSig2PartialSignature(shares, combined_nonce, keys, message_hash)
+		assert.NoError(t, err)
+		// the group partial signature verifies like the one of a single MuSig2 signer
+		assert.True(t, group_sig.Verify(group_nonce, combined_nonce, keys, participants[0].AsMuSig2Key(), message_hash))
+
+		external_sig, err := musig2.Sign(external_nonce.SecNonce, external, combined_nonce, keys, message_hash)
+		assert.NoError(t, err)
+		sig := musig2.CombineSigs(group_sig.R, []*musig2.PartialSignature{group_sig, external_sig})
+		assert.True(t, sig.Verify(message_hash[:], aggregate_key.FinalKey), "run %d", run)
+		assert.False(t, sig.Verify(message_hash[:], participants[0].GroupPublicKey))
+
+		// below threshold
+		delete(shares, 5)
+		_, err = aggregator.MuSig2PartialSignature(shares, combined_nonce, keys, message_hash)
+		assert.ErrorIs(t, err, testhelper.ErrThresholdNotMet)
+	}
+
+	participants, _ := runFrostDKG(&suite, 5, 2)
+	external, err := btcec.NewPrivateKey()
+	assert.NoError(t, err)
+	external_nonce, err := musig2.GenNonces(musig2.WithPublicKey(external.PubKey()))
+	assert.NoError(t, err)
+	var public_nonces [2]map[int64][2]*btcec.PublicKey
+	_, err = participants[0].MuSig2SignShare([2]int64{0, 1}, []int64{1, 2, 3}, suite.RandomMessage(), public_nonces, external_nonce.PubNonce, []*btcec.PublicKey{external.PubKey()})
+	assert.ErrorIs(t, err, testhelper.ErrGroupKeyNotInKeySet)
+}
+
+// go test -v -run ^TestFrostAggregatorRejectsNonceReuse$ github.com/nghuyenthevinh2000/bitcoin-playground
+func TestFrostAggregatorRejectsNonceReuse(t *testing.T) {
+	suite := testhelper.TestSuite{}
+	suite.SetupStaticSimNetSuite(t, log.Default())
+
+	participants, signing_shares := runFrostDKG(&suite, 5, 2)
+	aggregator := testhelper.NewFrostAggregator(&suite, participants[0])
+	honest := []int64{1, 3, 4}
+	message_hash := sha256.Sum256([]byte("first"))
+
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	nonces := make(map[int64]*testhelper.NonceCommitment)
+	for _, posi := range honest {
+		public_nonces[posi] = participants[posi-1].GenerateSigningNonces(1)[0]
+		nonces[posi] = &testhelper.NonceCommitment{D: public_nonces[posi][0], E: public_nonces[posi][1]}
+	}
+	shares := make(map[int64]*btcec.ModNScalar)
+	for _, posi := range honest {
+		_, err := participants[posi-1].CalculatePublicNonceCommitments(0, honest, message_hash, public_nonces)
+		assert.NoError(t, err)
+		partial_sig := participants[posi-1].PartialSign(posi, 0, honest, message_hash, public_nonces, signing_shares[posi])
+		shares[posi] = new(btcec.ModNScalar)
+		shares[posi].SetByteSlice(partial_sig.Serialize()[32:64])
+	}
+	_, err := aggregator.AggregateSignatureShares(message_hash, shares, nonces)
+	assert.NoError(t, err)
+
+	// the identical session retried is fine
+	assert.NoError(t, aggregator.TrackNonceCommitments(message_hash, nonces))
+
+	// the same message with another signer set changes the binding factors, the shares would leak s_i
+	fresh_signer := participants[1].GenerateSigningNonces(1)[0]
+	other_set := map[int64]*testhelper.NonceCommitment{1: nonces[1], 2: {D: fresh_signer[0], E: fresh_signer[1]}, 3: nonces[3]}
+	var reuse *testhelper.ErrNonceReuse
+	if assert.ErrorAs(t, aggregator.TrackNonceCommitments(message_hash, other_set), &reuse) {
+		assert.Equal(t, int64(1), reuse.Position)
+	}
+	// as does another commitment list over the same signers
+	other_list := map[int64]*testhelper.NonceCommitment{1: nonces[1], 3: nonces[3], 4: {D: nonces[4].E, E: nonces[4].D}}
+	assert.ErrorIs(t, aggregator.TrackNonceCommitments(message_hash, other_list), testhelper.ErrNonceReused)
+
+	// signer 3 presents the same commitment for another message, with fresh commitments of the others
+	other_message := sha256.Sum256([]byte("second"))
+	other_nonces := map[int64]*testhelper.NonceCommitment{3: nonces[3]}
+	for _, posi := range []int64{1, 4} {
+		fresh := participants[posi-1].GenerateSigningNonces(1)[0]
+		other_nonces[posi] = &testhelper.NonceCommitment{D: fresh[0], E: fresh[1]}
+	}
+	_, err = aggregator.AggregateSignatureShares(other_message, shares, other_nonces)
+	if assert.ErrorAs(t, err, &reuse) {
+		assert.Equal(t, int64(3), reuse.Position)
+	}
+	assert.ErrorIs(t, err, testhelper.ErrNonceReused)
+
+	// the rejected session recorded nothing, the fresh commitments of 1 and 4 are still usable
+	fresh := participants[2].GenerateSigningNonces(1)[0]
+	other_nonces[3] = &testhelper.NonceCommitment{D: fresh[0], E: fresh[1]}
+	assert.NoError(t, aggregator.TrackNonceCommitments(other_message, other_nonces))
+
+	// a commitment of a position without a share is not part of the session, it stays usable
+	bystander := participants[4].GenerateSigningNonces(1)[0]
+	bystander_nonce := &testhelper.NonceCommitment{D: bystander[0], E: bystander[1]}
+	with_bystander := map[int64]*testhelper.NonceCommitment{5: bystander_nonce}
+	for posi, nonce := range nonces {
+		with_bystander[posi] = nonce
+	}
+	fresh_aggregator := testhelper.NewFrostAggregator(&suite, participants[0])
+	_, err = fresh_aggregator.AggregateSignatureShares(message_hash, shares, with_bystander)
+	assert.NoError(t, err)
+	bystander_session := map[int64]*testhelper.NonceCommitment{1: other_nonces[1], 4: other_nonces[4], 5: bystander_nonce}
+	assert.NoError(t, fresh_aggregator.TrackNonceCommitments(other_message, bystander_session))
+}
+
+// commits, then never answers the session until released
+type unresponsiveRoastSigner struct {
+	*testhelper.FrostRoastSigner
+	release chan struct{}
+}
+
+func (s *unresponsiveRoastSigner) Sign(session *testhelper.RoastSession) (*btcec.ModNScalar, *testhelper.NonceCommitment, error) {
+	<-s.release
+	return nil, nil, testhelper.ErrRoastSignerFailed
+}
+
+// answers with a share that does not verify
+type faultyRoastSigner struct {
+	*testhelper.FrostRoastSigner
+}
+
+func (s *faultyRoastSigner) Sign(session *testhelper.RoastSession) (*btcec.ModNScalar, *testhelper.NonceCommitment, error) {
+	_, next, err := s.FrostRoastSigner.Sign(session)
+	return new(btcec.ModNScalar).SetInt(42), next, err
+}
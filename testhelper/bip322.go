@@ -0,0 +1,160 @@
+package testhelper
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	TagBIP322SignedMessage = []byte("BIP0322-signed-message")
+
+	ErrBIP322UnsupportedAddress = errors.New("bip322: only taproot addresses are supported")
+	ErrBIP322AddressMismatch    = errors.New("bip322: address is not the group taproot address")
+	ErrBIP322InvalidSignature   = errors.New("bip322: invalid signature")
+)
+
+// BIP322 simple signature of the message by the threshold group
+// the address must be the BIP86 taproot address of the group public key
+// the signature is the serialized witness of the virtual to_sign transaction
+func (s *TestSuite) SignBIP322(participants []*FrostParticipant, signers map[int64]bool, address btcutil.Address, message string) ([]byte, error) {
+	if _, ok := address.(*btcutil.AddressTaproot); !ok {
+		return nil, ErrBIP322UnsupportedAddress
+	}
+	if len(participants) == 0 {
+		return nil, ErrThresholdNotMet
+	}
+	output_key := txscript.ComputeTaprootKeyNoScript(participants[0].GroupPublicKey)
+	if !bytes.Equal(address.ScriptAddress(), schnorr.SerializePubKey(output_key)) {
+		return nil, ErrBIP322AddressMismatch
+	}
+
+	pk_script, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return nil, err
+	}
+	to_sign := bip322ToSign(bip322ToSpend(pk_script, message))
+	sighash, err := bip322Sighash(to_sign, pk_script)
+	if err != nil {
+		return nil, err
+	}
+
+	var sighash_bytes [32]byte
+	copy(sighash_bytes[:], sighash)
+	sig, err := s.frostSignTaprootKeyPath(participants, signers, sighash_bytes, []byte{})
+	if err != nil {
+		return nil, err
+	}
+
+	return serializeWitness(wire.TxWitness{sig.Serialize()})
+}
+
+// verify a BIP322 simple signature by executing the to_sign transaction against the address script
+func VerifyBIP322(address btcutil.Address, message string, signature []byte) error {
+	pk_script, err := txscript.PayToAddrScript(address)
+	if err != nil {
+		return err
+	}
+	witness, err := deserializeWitness(signature)
+	if err != nil {
+		return ErrBIP322InvalidSignature
+	}
+
+	to_sign := bip322ToSign(bip322ToSpend(pk_script, message))
+	to_sign.TxIn[0].Witness = witness
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(pk_script, 0)
+	engine, err := txscript.NewEngine(pk_script, to_sign, 0, txscript.StandardVerifyFlags, nil, txscript.NewTxSigHashes(to_sign, fetcher), 0, fetcher)
+	if err != nil {
+		return err
+	}
+	if err := engine.Execute(); err != nil {
+		return ErrBIP322InvalidSignature
+	}
+
+	return nil
+}
+
+// to_spend commits to the message hash H(message) in its input
+func bip322ToSpend(pk_script []byte, message string) *wire.MsgTx {
+	message_hash := chainhash.TaggedHash(TagBIP322SignedMessage, []byte(message))
+	sig_script, _ := txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(message_hash[:]).Script()
+
+	to_spend := wire.NewMsgTx(0)
+	to_spend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{
+			Hash:  chainhash.Hash{},
+			Index: 0xFFFFFFFF,
+		},
+		SignatureScript: sig_script,
+		Sequence:        0,
+	})
+	to_spend.AddTxOut(wire.NewTxOut(0, pk_script))
+
+	return to_spend
+}
+
+// to_sign spends the output of to_spend into an OP_RETURN output
+func bip322ToSign(to_spend *wire.MsgTx) *wire.MsgTx {
+	to_sign := wire.NewMsgTx(0)
+	to_sign.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{
+			Hash:  to_spend.TxHash(),
+			Index: 0,
+		},
+		Sequence: 0,
+	})
+	to_sign.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+
+	return to_sign
+}
+
+func bip322Sighash(to_sign *wire.MsgTx, pk_script []byte) ([]byte, error) {
+	fetcher := txscript.NewCannedPrevOutputFetcher(pk_script, 0)
+	sig_hashes := txscript.NewTxSigHashes(to_sign, fetcher)
+
+	return txscript.CalcTaprootSignatureHash(sig_hashes, txscript.SigHashDefault, to_sign, 0, fetcher)
+}
+
+func serializeWitness(witness wire.TxWitness) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := wire.WriteVarInt(&buf, 0, uint64(len(witness))); err != nil {
+		return nil, err
+	}
+	for _, item := range witness {
+		if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func deserializeWitness(data []byte) (wire.TxWitness, error) {
+	buf := bytes.NewReader(data)
+	count, err := wire.ReadVarInt(buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(data)) {
+		return nil, ErrBIP322InvalidSignature
+	}
+
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(buf, 0, txscript.MaxScriptSize, "witness item")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if buf.Len() != 0 {
+		return nil, ErrBIP322InvalidSignature
+	}
+
+	return witness, nil
+}
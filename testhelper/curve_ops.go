@@ -0,0 +1,70 @@
+package testhelper
+
+import (
+	"sync/atomic"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// CurveOpCounts is the number of curve operations performed by Frost participants
+// counts are independent of hardware, thus comparable across runs and machines
+type CurveOpCounts struct {
+	// k * P for an arbitrary point P
+	ScalarMults int64
+	// k * G
+	BaseMults int64
+	// P + Q
+	Adds int64
+	// \sum_{i} k_i * P_i, counted once whatever the number of terms
+	MultiScalarMults int64
+}
+
+type curveOpCounter struct {
+	scalar_mults atomic.Int64
+	base_mults   atomic.Int64
+	adds         atomic.Int64
+	multi_mults  atomic.Int64
+}
+
+// curve operations of all Frost participants sharing the suite since the last reset
+func (s *TestSuite) CurveOpCounts() CurveOpCounts {
+	return CurveOpCounts{
+		ScalarMults:      s.curve_ops.scalar_mults.Load(),
+		BaseMults:        s.curve_ops.base_mults.Load(),
+		Adds:             s.curve_ops.adds.Load(),
+		MultiScalarMults: s.curve_ops.multi_mults.Load(),
+	}
+}
+
+func (s *TestSuite) ResetCurveOpCounts() {
+	s.curve_ops.scalar_mults.Store(0)
+	s.curve_ops.base_mults.Store(0)
+	s.curve_ops.adds.Store(0)
+	s.curve_ops.multi_mults.Store(0)
+}
+
+func (s *TestSuite) scalarMult(k *btcec.ModNScalar, point, result *btcec.JacobianPoint) {
+	s.curve_ops.scalar_mults.Add(1)
+	btcec.ScalarMultNonConst(k, point, result)
+}
+
+func (s *TestSuite) scalarBaseMult(k *btcec.ModNScalar, result *btcec.JacobianPoint) {
+	s.curve_ops.base_mults.Add(1)
+	btcec.ScalarBaseMultNonConst(k, result)
+}
+
+func (s *TestSuite) addPoints(p1, p2, result *btcec.JacobianPoint) {
+	s.curve_ops.adds.Add(1)
+	btcec.AddNonConst(p1, p2, result)
+}
+
+func (s *TestSuite) multiScalarMul(scalars []*btcec.ModNScalar, points []*btcec.JacobianPoint, result *btcec.JacobianPoint) error {
+	s.curve_ops.multi_mults.Add(1)
+	sum, err := multiScalarMulJacobian(scalars, points)
+	if err != nil {
+		return err
+	}
+	result.Set(sum)
+
+	return nil
+}
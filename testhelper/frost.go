@@ -1,8 +1,15 @@
 package testhelper
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	btcec "github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -17,7 +24,22 @@ import (
 // TODO: many math operations here probably needs to be optimized and benchmarked
 
 var (
-	TagFROSTChallenge = []byte("FROST/challenge")
+	TagFROSTChallenge          = []byte("FROST/challenge")
+	TagFROSTGroupKeyCommitment = []byte("FROST/group-key-commitment")
+
+	ErrIndexOutOfRange         = errors.New("frost participant: index out of range")
+	ErrNotEnoughPublicShares   = errors.New("group key from public shares: not enough public shares")
+	ErrSharesAlreadyComputed   = errors.New("update participant count: secret shares already computed")
+	ErrInvalidParticipantCount = errors.New("update participant count: invalid participant count")
+	ErrDealerIndexCollision    = errors.New("frost participant: distinct commitments stored under the same dealer index")
+	ErrQWMapDivergence         = errors.New("q w map agreement: participants derived different maps")
+	ErrInvalidSecretShare      = errors.New("verify batch public secret shares: invalid secret share")
+	ErrInvalidSecretProof      = errors.New("verify frost secret proof: invalid proof")
+	ErrInvalidNonce            = errors.New("calculate public nonce commitments: aggregated nonce commitment is the point at infinity")
+	ErrMissingAggrNonce        = errors.New("frost participant: aggregated nonce commitment not calculated for the signing index")
+	ErrMissingProvenKey        = errors.New("verify constant term consistency: no secret proof verified for the dealer")
+	ErrInconsistentConstant    = errors.New("verify constant term consistency: constant term commitment differs from the proven key")
+	ErrInvalidFrostParams      = errors.New("new frost participant: invalid parameters")
 )
 
 // multiple signing usages are meant to sign multiple messages with this Frost setup
@@ -31,15 +53,34 @@ type FrostParticipant struct {
 
 	secretPolynomial []*btcec.ModNScalar
 	secretShares     []*btcec.ModNScalar
+	signingShares    *btcec.ModNScalar
+	// secret shares f_j(i) received from dealers, keyed by dealer position
+	received_shares map[int64]*btcec.ModNScalar
+	// pending proactive refresh, \delta_i(x) and the commitments B_j1, ..., B_jt keyed by dealer
+	refresh_polynomial  []*btcec.ModNScalar
+	refresh_commitments map[int64][]*btcec.PublicKey
 	// nonce commitments for multiples signing usages
 	nonces [][2]*btcec.ModNScalar
+	// calls of GenerateDeterministicNonces, never reset
+	deterministic_nonce_counter uint64
 
 	// caching for faster computation
 	power_map sync.Map
-	q_map     sync.Map
-	w_map     sync.Map
+	// shared read - only powers i^j, rows are referenced by power_map
+	power_table *PowerTable
+	q_map       sync.Map
+	w_map       sync.Map
+	// Lagrange coefficients keyed by sorted signer set and position
+	lagrange_cache sync.Map
+	// A_i0 of each dealer whose secret proof verified, keyed by dealer position
+	proven_keys sync.Map
 
 	PolynomialCommitments map[int64][]*btcec.PublicKey
+	// guards PolynomialCommitments and commitment_collisions against concurrent updates
+	// every read and write of the map goes through it, the commitment lists are never modified in place
+	commitments_mu sync.RWMutex
+	// dealer indices that received distinct commitment lists
+	commitment_collisions map[int64]bool
 	PublicSigningShares   sync.Map
 	GroupPublicKey        *btcec.PublicKey
 	// contains the nonce commitments for multiple signing usages
@@ -47,18 +88,29 @@ type FrostParticipant struct {
 	PublicNonceCommitments map[int64][][2]*btcec.PublicKey
 	// contains the aggregated nonce commitments for multiple signing usages
 	AggrNonceCommitment map[int64]*btcec.JacobianPoint
+	// sighash a nonce pair is bound to, keyed by signing index
+	nonce_bindings map[int64][32]byte
+	// offboarded members, refused in signing sessions
+	revoked map[int64]bool
+	// how VerifyBatchPublicSecretShares checks a batch
+	batch_verify_strategy BatchVerifyStrategy
+	// nonce commitments used in partial signatures, keyed by H(D || E)
+	// the value binds the commitment to the session it signed in, the secret nonces themselves are zeroed
+	nonce_history map[[32]byte][32]byte
+	// long term key attributing partial signatures to this participant
+	identity_key *btcec.PrivateKey
+	// hardware wallet the signing share is exported to by ExportToHWIFormat
+	hwi_encryption_key *btcec.PublicKey
+
+	// active signing sessions keyed by signing index
+	// also guards the per signing index state read by concurrent sessions: AggrNonceCommitment, nonce bindings and history
+	sessions_mu             sync.RWMutex
+	active_sessions         map[int64]time.Time
+	max_concurrent_sessions int
 }
 
 func NewFrostParticipant(suite *TestSuite, logger *log.Logger, n, Threshold, posi int64, secret *btcec.ModNScalar) *FrostParticipant {
-	frost := &FrostParticipant{
-		suite:                 suite,
-		logger:                logger,
-		N:                     n,
-		Threshold:             Threshold,
-		Position:              posi,
-		PolynomialCommitments: make(map[int64][]*btcec.PublicKey),
-		AggrNonceCommitment:   make(map[int64]*secp.JacobianPoint),
-	}
+	frost := newEmptyFrostParticipant(suite, logger, n, Threshold, posi)
 
 	// generate secret polynomial
 	frost.secretPolynomial = suite.GeneratePolynomial(Threshold)
@@ -71,28 +123,158 @@ func NewFrostParticipant(suite *TestSuite, logger *log.Logger, n, Threshold, pos
 	return frost
 }
 
+// same as NewFrostParticipant, but rejects parameters that can only produce a broken group
+// n >= 1, 1 <= threshold < n as threshold + 1 signers are needed, position in [1, n]
+func NewFrostParticipantChecked(suite *TestSuite, logger *log.Logger, n, threshold, posi int64, secret *btcec.ModNScalar) (*FrostParticipant, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("%w: participant count %d", ErrInvalidFrostParams, n)
+	}
+	if threshold < 1 || threshold >= n {
+		return nil, fmt.Errorf("%w: threshold %d not in [1, %d]", ErrInvalidFrostParams, threshold, n-1)
+	}
+	if posi < 1 || posi > n {
+		return nil, fmt.Errorf("%w: %w: %d not in [1, %d]", ErrInvalidFrostParams, ErrIndexOutOfRange, posi, n)
+	}
+
+	return NewFrostParticipant(suite, logger, n, threshold, posi, secret), nil
+}
+
+// participant without secret polynomial, either generated or restored by the caller
+func newEmptyFrostParticipant(suite *TestSuite, logger *log.Logger, n, threshold, posi int64) *FrostParticipant {
+	return &FrostParticipant{
+		suite:                 suite,
+		logger:                logger,
+		N:                     n,
+		Threshold:             threshold,
+		Position:              posi,
+		PolynomialCommitments: make(map[int64][]*btcec.PublicKey),
+		AggrNonceCommitment:   make(map[int64]*secp.JacobianPoint),
+		active_sessions:       make(map[int64]time.Time),
+	}
+}
+
+// keep the secret share f_j(i) received from dealer j until all shares are verified
+// received shares are part of the saved DKG state
+func (p *FrostParticipant) ReceiveSecretShare(dealer int64, share *btcec.ModNScalar) {
+	if !assert.NoError(p.suite.T, p.validateIndex(dealer)) {
+		return
+	}
+	if p.received_shares == nil {
+		p.received_shares = make(map[int64]*btcec.ModNScalar)
+	}
+	p.received_shares[dealer] = new(btcec.ModNScalar).Set(share)
+}
+
+// copy of the received secret shares keyed by dealer, to be verified and summed into the signing share
+func (p *FrostParticipant) ReceivedSecretShares() map[int64]*btcec.ModNScalar {
+	shares := make(map[int64]*btcec.ModNScalar, len(p.received_shares))
+	for dealer, share := range p.received_shares {
+		shares[dealer] = new(btcec.ModNScalar).Set(share)
+	}
+
+	return shares
+}
+
+// s_i = \sum_{j} f_j(i), the long-term secret share of this participant
+// secret shares f_j(i) are received from all dealers j and must be verified before
+func (p *FrostParticipant) CalculateSigningShares(secret_shares map[int64]*btcec.ModNScalar) *btcec.ModNScalar {
+	signing_shares := new(btcec.ModNScalar)
+	for _, secret := range secret_shares {
+		signing_shares.Add(secret)
+	}
+	p.StoreSigningShares(signing_shares)
+
+	return signing_shares
+}
+
+func (p *FrostParticipant) StoreSigningShares(value *btcec.ModNScalar) {
+	p.signingShares = value
+}
+
+func (p *FrostParticipant) GetSigningShares() *btcec.ModNScalar {
+	return p.signingShares
+}
+
+// a participant registered with a stale view of the participant set can catch up
+// internal structures sized by n are only allocated from secret shares computation onwards
+// thus, n can only grow before CalculateSecretShares
+func (p *FrostParticipant) UpdateParticipantCount(newN int64) error {
+	if p.secretShares != nil {
+		return ErrSharesAlreadyComputed
+	}
+	if newN < p.N || newN <= p.Threshold {
+		return ErrInvalidParticipantCount
+	}
+	p.N = newN
+	p.InvalidateLagrangeCache()
+
+	return nil
+}
+
+// participant indices are in [1, n]
+func (p *FrostParticipant) validateIndex(i int64) error {
+	if i < 1 || i > p.N {
+		return fmt.Errorf("%w: %d not in [1, %d]", ErrIndexOutOfRange, i, p.N)
+	}
+
+	return nil
+}
+
 func (p *FrostParticipant) StorePublicSigningShares(key int64, value *btcec.PublicKey) {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return
+	}
 	p.PublicSigningShares.Store(key, value)
 }
 
 func (p *FrostParticipant) GetPublicSigningShares(key int64) *btcec.PublicKey {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return nil
+	}
 	value, ok := p.PublicSigningShares.Load(key)
 	assert.True(p.suite.T, ok)
 	return value.(*btcec.PublicKey)
 }
 
+// Y_1 .. Y_n in position order, errors on the first position without a public signing share
+func (p *FrostParticipant) allPublicSigningShares() ([]*btcec.PublicKey, error) {
+	shares := make([]*btcec.PublicKey, p.N)
+	for posi := int64(1); posi <= p.N; posi++ {
+		value, ok := p.PublicSigningShares.Load(posi)
+		if !ok {
+			return nil, fmt.Errorf("public signing share %d: %w", posi, ErrMissingPublicSigningShare)
+		}
+		shares[posi-1] = value.(*btcec.PublicKey)
+	}
+
+	return shares, nil
+}
+
+// parsed items are deep - copied from the source map
+// thus, many participants can parse the same source map concurrently without sharing any point
 func (p *FrostParticipant) ParseQMap(q_map map[interface{}]interface{}) {
 	for key, value := range q_map {
-		p.q_map.Store(key, value)
+		p.q_map.Store(key, copyJacobianPoints(value.([]*btcec.JacobianPoint)))
 	}
 }
 
+// parsed items are deep - copied from the source map
 func (p *FrostParticipant) ParseWMap(w_map map[interface{}]interface{}) {
 	for key, value := range w_map {
-		p.w_map.Store(key, value)
+		p.w_map.Store(key, copyJacobianPoints(value.([]*btcec.JacobianPoint)))
 	}
 }
 
+func copyJacobianPoints(points []*btcec.JacobianPoint) []*btcec.JacobianPoint {
+	points_copy := make([]*btcec.JacobianPoint, len(points))
+	for i, point := range points {
+		points_copy[i] = new(btcec.JacobianPoint)
+		points_copy[i].Set(point)
+	}
+
+	return points_copy
+}
+
 func (p *FrostParticipant) CopyQMap() map[interface{}]interface{} {
 	q_map_copy := make(map[interface{}]interface{})
 	p.q_map.Range(func(key, value interface{}) bool {
@@ -113,11 +295,59 @@ func (p *FrostParticipant) CopyWMap() map[interface{}]interface{} {
 	return w_map_copy
 }
 
+// each participant independently derives Q and W maps, which must agree with participant 0
+// expensive operation, meant for small n to check the maps that benchmarks copy from participant 0
+// power maps must be derived before
+func AssertQWMapAgreement(participants []*FrostParticipant) error {
+	var wg sync.WaitGroup
+	for _, participant := range participants {
+		wg.Add(1)
+		go func(participant *FrostParticipant) {
+			defer wg.Done()
+			participant.DeriveExternalQMap()
+			participant.DeriveExternalWMap()
+		}(participant)
+	}
+	wg.Wait()
+
+	if len(participants) == 0 {
+		return nil
+	}
+	reference := participants[0]
+	for _, participant := range participants[1:] {
+		for posi := int64(1); posi <= reference.N; posi++ {
+			maps := map[string][2][]*btcec.JacobianPoint{
+				"Q": {reference.GetQMapItem(posi), participant.GetQMapItem(posi)},
+				"W": {reference.GetWMapItem(posi), participant.GetWMapItem(posi)},
+			}
+			for _, name := range []string{"Q", "W"} {
+				expected, derived := maps[name][0], maps[name][1]
+				if len(expected) != len(derived) {
+					return fmt.Errorf("%w: participant %d has %d %s_j(%d) items, participant %d has %d", ErrQWMapDivergence, participant.Position, len(derived), name, posi, reference.Position, len(expected))
+				}
+				for j := range expected {
+					if !equalPoints(expected[j], derived[j]) {
+						return fmt.Errorf("%w: participant %d %s_%d(%d) differs from participant %d", ErrQWMapDivergence, participant.Position, name, j, posi, reference.Position)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func (p *FrostParticipant) StoreWMapItem(key int64, value []*btcec.JacobianPoint) {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return
+	}
 	p.w_map.Store(key, value)
 }
 
 func (p *FrostParticipant) GetWMapItem(key int64) []*btcec.JacobianPoint {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return nil
+	}
 	value, ok := p.w_map.Load(key)
 
 	// check item exists
@@ -127,10 +357,16 @@ func (p *FrostParticipant) GetWMapItem(key int64) []*btcec.JacobianPoint {
 }
 
 func (p *FrostParticipant) StoreQMapItem(key int64, value []*btcec.JacobianPoint) {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return
+	}
 	p.q_map.Store(key, value)
 }
 
 func (p *FrostParticipant) GetQMapItem(key int64) []*btcec.JacobianPoint {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return nil
+	}
 	value, ok := p.q_map.Load(key)
 
 	// check item exists
@@ -140,10 +376,16 @@ func (p *FrostParticipant) GetQMapItem(key int64) []*btcec.JacobianPoint {
 }
 
 func (p *FrostParticipant) StorePowerMapItem(key int64, value []*btcec.ModNScalar) {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return
+	}
 	p.power_map.Store(key, value)
 }
 
 func (p *FrostParticipant) GetPowerMapItem(key int64) []*btcec.ModNScalar {
+	if !assert.NoError(p.suite.T, p.validateIndex(key)) {
+		return nil
+	}
 	value, ok := p.power_map.Load(key)
 	// check item exists
 	assert.True(p.suite.T, ok)
@@ -160,17 +402,141 @@ func (p *FrostParticipant) generatePedersenCommitments() []*btcec.PublicKey {
 	for i := int64(0); i <= p.Threshold; i++ {
 		// g^a_k
 		point := new(btcec.JacobianPoint)
-		btcec.ScalarBaseMultNonConst(p.secretPolynomial[i], point)
+		p.suite.scalarBaseMult(p.secretPolynomial[i], point)
 		point.ToAffine()
 		commitments[i] = btcec.NewPublicKey(&point.X, &point.Y)
 	}
 	return commitments
 }
 
+// the dealer polynomial must have degree Threshold, thus t + 1 commitments
 func (p *FrostParticipant) UpdatePolynomialCommitments(posi int64, commitments []*btcec.PublicKey) {
+	if !assert.NoError(p.suite.T, p.validateIndex(posi)) {
+		return
+	}
+	if !assert.Len(p.suite.T, commitments, int(p.Threshold+1), "polynomial commitments of dealer %d", posi) {
+		return
+	}
+	p.commitments_mu.Lock()
+	defer p.commitments_mu.Unlock()
+
+	if existing, ok := p.PolynomialCommitments[posi]; ok && !equalCommitments(existing, commitments) {
+		p.flagCommitmentCollision(posi)
+	}
 	p.PolynomialCommitments[posi] = commitments
 }
 
+// ingest the commitments of every dealer at once, instead of n calls to UpdatePolynomialCommitments
+// dealers and commitment counts are validated and compared against stored commitments by a worker pool,
+// then all commitments are stored under a single lock
+//
+// readers in this package take commitments_mu, through GetPolynomialCommitments, QualifiedSet or a snapshot,
+// thus e.g. CalculateGroupPublicKey may run concurrently
+func (p *FrostParticipant) UpdateAllPolynomialCommitments(commitments map[int64][]*btcec.PublicKey) {
+	dealers := make([]int64, 0, len(commitments))
+	for dealer := range commitments {
+		dealers = append(dealers, dealer)
+	}
+
+	// worker w checks dealers w, w + workers, ..., each result slot is written by a single worker
+	valid := make([]bool, len(dealers))
+	collision := make([]bool, len(dealers))
+	// GOMAXPROCS rather than NumCPU, thus go test -cpu controls the pool size
+	workers := runtime.GOMAXPROCS(0)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for index := w; index < len(dealers); index += workers {
+				dealer := dealers[index]
+				if !assert.NoError(p.suite.T, p.validateIndex(dealer)) {
+					continue
+				}
+				if !assert.Len(p.suite.T, commitments[dealer], int(p.Threshold+1), "polynomial commitments of dealer %d", dealer) {
+					continue
+				}
+				valid[index] = true
+				existing, ok := p.GetPolynomialCommitments(dealer)
+				collision[index] = ok && !equalCommitments(existing, commitments[dealer])
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	p.commitments_mu.Lock()
+	defer p.commitments_mu.Unlock()
+	for index, dealer := range dealers {
+		if !valid[index] {
+			continue
+		}
+		if collision[index] {
+			p.flagCommitmentCollision(dealer)
+		}
+		p.PolynomialCommitments[dealer] = commitments[dealer]
+	}
+}
+
+// commitments stored for the dealer, safe against concurrent updates
+func (p *FrostParticipant) GetPolynomialCommitments(dealer int64) ([]*btcec.PublicKey, bool) {
+	p.commitments_mu.RLock()
+	defer p.commitments_mu.RUnlock()
+
+	commitments, ok := p.PolynomialCommitments[dealer]
+	return commitments, ok
+}
+
+// copy of the stored commitments, safe to range over while commitments are updated
+// the commitment lists themselves are shared, they are replaced but never modified in place
+func (p *FrostParticipant) snapshotPolynomialCommitments() map[int64][]*btcec.PublicKey {
+	p.commitments_mu.RLock()
+	defer p.commitments_mu.RUnlock()
+
+	snapshot := make(map[int64][]*btcec.PublicKey, len(p.PolynomialCommitments))
+	for dealer, commitments := range p.PolynomialCommitments {
+		snapshot[dealer] = commitments
+	}
+	return snapshot
+}
+
+func (p *FrostParticipant) flagCommitmentCollision(posi int64) {
+	if p.commitment_collisions == nil {
+		p.commitment_collisions = make(map[int64]bool)
+	}
+	p.commitment_collisions[posi] = true
+}
+
+// flag dealer indices that were stored with two distinct commitment lists, e.g. due to a routing bug
+// the later list overwrites the former, so shares from that dealer cannot be trusted
+func (p *FrostParticipant) DetectDealerIndexCollision() ([]int64, error) {
+	p.commitments_mu.RLock()
+	defer p.commitments_mu.RUnlock()
+
+	collisions := make([]int64, 0, len(p.commitment_collisions))
+	for posi := range p.commitment_collisions {
+		collisions = append(collisions, posi)
+	}
+	if len(collisions) == 0 {
+		return nil, nil
+	}
+	sort.Slice(collisions, func(i, j int) bool { return collisions[i] < collisions[j] })
+
+	return collisions, ErrDealerIndexCollision
+}
+
+func equalCommitments(a, b []*btcec.PublicKey) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].IsEqual(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // calculating secret proofs challenge
 // c = H(i, stamp, A_i, R_i)
 func (p *FrostParticipant) CalculateSecretProofsChallenge(context_hash [32]byte, R_x *btcec.FieldVal, position int64, secretCommitments *btcec.PublicKey) *btcec.ModNScalar {
@@ -193,7 +559,7 @@ func (p *FrostParticipant) CalculateSecretProofs(context_hash [32]byte) *schnorr
 	k := new(btcec.ModNScalar)
 	k.SetBytes(&nonce)
 	R := new(btcec.JacobianPoint)
-	btcec.ScalarBaseMultNonConst(k, R)
+	p.suite.scalarBaseMult(k, R)
 	R.ToAffine()
 	// BIP340 requires that Y coordinate is even
 	if R.Y.IsOdd() {
@@ -203,23 +569,26 @@ func (p *FrostParticipant) CalculateSecretProofs(context_hash [32]byte) *schnorr
 	// BIP340 requires that Y coordinate is even
 	// Warning: btcec.ModNScalar is stored as pointer, so we need to create a copy else the original value will be modified
 	secret := new(btcec.ModNScalar).Set(p.secretPolynomial[0])
-	secret_commitment_bytes := p.PolynomialCommitments[p.Position][0].SerializeCompressed()
+	own_commitments, _ := p.GetPolynomialCommitments(p.Position)
+	secret_commitment_bytes := own_commitments[0].SerializeCompressed()
 	if secret_commitment_bytes[0] == secp.PubKeyFormatCompressedOdd {
 		secret.Negate()
 	}
 
-	c := p.CalculateSecretProofsChallenge(context_hash, &R.X, p.Position, p.PolynomialCommitments[p.Position][0])
+	c := p.CalculateSecretProofsChallenge(context_hash, &R.X, p.Position, own_commitments[0])
 
 	s_scalar := new(btcec.ModNScalar).Mul2(secret, c).Add(k)
 	sig := schnorr.NewSignature(&R.X, s_scalar)
 
 	// self verification
-	p.VerifySecretProofs(context_hash, sig, p.Position, p.PolynomialCommitments[p.Position][0])
+	assert.NoError(p.suite.T, p.VerifySecretProofs(context_hash, sig, p.Position, own_commitments[0]))
 
 	return sig
 }
 
-func (p *FrostParticipant) VerifySecretProofs(context_hash [32]byte, secret_proof *schnorr.Signature, position int64, secretCommitments *btcec.PublicKey) {
+// R_i = g^\mu_i * A_i0^-c must have even Y coordinate and match the R_x of the proof
+// failures are returned as ErrInvalidSecretProof so that a coordinator can exclude the dealer and continue
+func (p *FrostParticipant) VerifySecretProofs(context_hash [32]byte, secret_proof *schnorr.Signature, position int64, secretCommitments *btcec.PublicKey) error {
 	// retrive (R, s) from secret Schnorr proof
 	secret_proof_bytes := secret_proof.Serialize()
 	R_bytes := secret_proof_bytes[0:32]
@@ -234,7 +603,9 @@ func (p *FrostParticipant) VerifySecretProofs(context_hash [32]byte, secret_proo
 	// making even the public key Y coordinate
 	secret_commitment_bytes := schnorr.SerializePubKey(secretCommitments)
 	secret_commitment_pubkey, err := schnorr.ParsePubKey(secret_commitment_bytes)
-	assert.Nil(p.suite.T, err)
+	if err != nil {
+		return fmt.Errorf("%w: dealer %d: %v", ErrInvalidSecretProof, position, err)
+	}
 
 	R := new(btcec.JacobianPoint)
 	// A_i0^-c
@@ -242,39 +613,116 @@ func (p *FrostParticipant) VerifySecretProofs(context_hash [32]byte, secret_proo
 	secret_commitment_pubkey.AsJacobian(secret_commitment_point)
 	term := new(btcec.JacobianPoint)
 	c.Negate()
-	btcec.ScalarMultNonConst(c, secret_commitment_point, term)
+	p.suite.scalarMult(c, secret_commitment_point, term)
 	// g^\mu_i
 	term1 := new(btcec.JacobianPoint)
-	btcec.ScalarBaseMultNonConst(s, term1)
+	p.suite.scalarBaseMult(s, term1)
 	// R_i = g^\mu_i * A_i0^-c
-	btcec.AddNonConst(term1, term, R)
+	p.suite.addPoints(term1, term, R)
 
 	// Fail if R is the point at infinity
-	is_infinity := false
 	if (R.X.IsZero() && R.Y.IsZero()) || R.Z.IsZero() {
-		is_infinity = true
+		return fmt.Errorf("%w: dealer %d: R is the point at infinity", ErrInvalidSecretProof, position)
 	}
-	assert.False(p.suite.T, is_infinity, "verify frost secret proof: R is the point at infinity")
 
 	// R_Y cannot be odd
 	R.ToAffine()
-	assert.False(p.suite.T, R.Y.IsOdd(), "verify frost secret proof: R.Y is odd")
+	if R.Y.IsOdd() {
+		return fmt.Errorf("%w: dealer %d: R.Y is odd", ErrInvalidSecretProof, position)
+	}
 
 	// verify R point equals provided R_X
-	assert.Equal(p.suite.T, &R.X, R_x, "verify frost secret proof: R.X does not match provided R_X")
+	if !R.X.Equals(R_x) {
+		return fmt.Errorf("%w: dealer %d: R.X does not match provided R_X", ErrInvalidSecretProof, position)
+	}
+	p.proven_keys.Store(position, secretCommitments)
+
+	return nil
+}
+
+// the stored C_i[0] of the dealer must be the key A_i0 proven in VerifySecretProofs
+// otherwise shares are checked against a constant term nobody proved knowledge of
+func (p *FrostParticipant) VerifyConstantTermConsistency(dealer int64) error {
+	if err := p.validateIndex(dealer); err != nil {
+		return err
+	}
+	value, ok := p.proven_keys.Load(dealer)
+	if !ok {
+		return fmt.Errorf("%w: dealer %d", ErrMissingProvenKey, dealer)
+	}
+	commitments, _ := p.GetPolynomialCommitments(dealer)
+	if len(commitments) == 0 || !commitments[0].IsEqual(value.(*btcec.PublicKey)) {
+		return fmt.Errorf("%w: dealer %d", ErrInconsistentConstant, dealer)
+	}
+
+	return nil
+}
+
+// estimate the duration of the secret proofs phase for n participants
+// where each participant calculates its secret proof and has it verified
+//
+// the estimate is derived from a quick micro - benchmark of a single proof
+// assertions of the suite are collected, thus a failed proof is returned as an error instead of a panic
+func EstimateProofCost(n int64) (time.Duration, error) {
+	collector := &dkgFailureCollector{}
+	suite := &TestSuite{
+		T:      collector,
+		Logger: log.Default(),
+	}
+	// proof cost does not depend on the threshold, the smallest valid group is enough
+	p, err := NewFrostParticipantChecked(suite, suite.Logger, 2, 1, 1, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	samples := 10
+	time_now := time.Now()
+	for i := 0; i < samples; i++ {
+		proof := p.CalculateSecretProofs([32]byte{})
+		if err := p.VerifySecretProofs([32]byte{}, proof, p.Position, p.PolynomialCommitments[p.Position][0]); err != nil {
+			return 0, fmt.Errorf("estimate proof cost: %w", err)
+		}
+	}
+	each := time.Since(time_now) / time.Duration(samples)
+	if len(collector.failures) > 0 {
+		return 0, fmt.Errorf("estimate proof cost: %s", strings.Join(collector.failures, "; "))
+	}
+
+	return each * time.Duration(n), nil
 }
 
 // calculating f(i)
 // calculate secret shares can be parallelized
 func (p *FrostParticipant) CalculateSecretShares() {
-	p.secretShares = make([]*btcec.ModNScalar, p.N)
+	p.CalculateSecretSharesCtx(context.Background())
+}
+
+// same as CalculateSecretShares, ctx is checked before each share
+// on cancellation ctx.Err() is returned and the previous secret shares are kept
+func (p *FrostParticipant) CalculateSecretSharesCtx(ctx context.Context) error {
+	secret_shares := make([]*btcec.ModNScalar, p.N)
 	for j := int64(0); j < p.N; j++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		// evaluate the secret polynomial at the participant index
 		participant_scalar := new(btcec.ModNScalar).SetInt(uint32(j + 1))
 		// secret shares as f(x)
-		shares := p.suite.EvaluatePolynomial(p.secretPolynomial, participant_scalar)
-		p.updateSecretShares(j+1, shares)
+		secret_shares[j] = p.suite.EvaluatePolynomial(p.secretPolynomial, participant_scalar)
 	}
+	p.secretShares = secret_shares
+
+	return nil
+}
+
+// a copy of the secret polynomial f_i, only meant for test oracles
+func (p *FrostParticipant) SecretPolynomial() []*btcec.ModNScalar {
+	polynomial := make([]*btcec.ModNScalar, len(p.secretPolynomial))
+	for i, coeff := range p.secretPolynomial {
+		polynomial[i] = new(btcec.ModNScalar).Set(coeff)
+	}
+
+	return polynomial
 }
 
 func (p *FrostParticipant) AllSecretShares() []*btcec.ModNScalar {
@@ -282,23 +730,20 @@ func (p *FrostParticipant) AllSecretShares() []*btcec.ModNScalar {
 }
 
 func (p *FrostParticipant) GetSecretShares(position int64) *btcec.ModNScalar {
+	if !assert.NoError(p.suite.T, p.validateIndex(position)) {
+		return nil
+	}
 	return p.secretShares[position-1]
 }
 
-func (p *FrostParticipant) updateSecretShares(posi int64, val *btcec.ModNScalar) {
-	// p.secretSharesMutex.Lock()
-	// defer p.secretSharesMutex.Unlock()
-	p.secretShares[posi-1] = val
-}
-
 // verify secret shares
 func (p *FrostParticipant) VerifyPublicSecretShares(secretShares *btcec.ModNScalar, which_participant_poly int64, posi uint32) {
 	posi_scalar := new(btcec.ModNScalar).SetInt(posi)
-	polynomialCommitments := p.PolynomialCommitments[which_participant_poly]
+	polynomialCommitments, _ := p.GetPolynomialCommitments(which_participant_poly)
 
 	// calculate A(i) = g^f(i)
 	expected_a := new(btcec.JacobianPoint)
-	btcec.ScalarBaseMultNonConst(secretShares, expected_a)
+	p.suite.scalarBaseMult(secretShares, expected_a)
 
 	// calculate prod(A_k^i^k)
 	i_power := new(btcec.ModNScalar)
@@ -308,9 +753,9 @@ func (p *FrostParticipant) VerifyPublicSecretShares(secretShares *btcec.ModNScal
 		term := new(btcec.JacobianPoint)
 		polynomialCommitments[i].AsJacobian(term)
 		// calculate term = A_k^i^k = g^(a_k*i^k)
-		btcec.ScalarMultNonConst(i_power, term, term)
+		p.suite.scalarMult(i_power, term, term)
 		// calculate prod(A_k^i^k)
-		btcec.AddNonConst(calculated_a, term, calculated_a)
+		p.suite.addPoints(calculated_a, term, calculated_a)
 		i_power.Mul(posi_scalar)
 	}
 
@@ -326,7 +771,7 @@ func (p *FrostParticipant) VerifyPublicSecretShares(secretShares *btcec.ModNScal
 
 func (p *FrostParticipant) CalculateInternalPublicSigningShares(signingShares *btcec.ModNScalar, posi int64) *btcec.PublicKey {
 	signingPoint := new(btcec.JacobianPoint)
-	btcec.ScalarBaseMultNonConst(signingShares, signingPoint)
+	p.suite.scalarBaseMult(signingShares, signingPoint)
 	signingPoint.ToAffine()
 
 	p.StorePublicSigningShares(posi, btcec.NewPublicKey(&signingPoint.X, &signingPoint.Y))
@@ -372,6 +817,7 @@ func (p *FrostParticipant) CalculatePublicSigningShares(party_num, posi int64) *
 		i_power.Mul(posi_scalar)
 	}
 
+	all_commitments := p.snapshotPolynomialCommitments()
 	for i := int64(1); i <= party_num; i++ {
 		// parallel computation
 		// \prod_{j=0}^{t} A_mj^i^j
@@ -382,11 +828,11 @@ func (p *FrostParticipant) CalculatePublicSigningShares(party_num, posi int64) *
 			go func(j int64) {
 				// A_mj
 				A_ij := new(btcec.JacobianPoint)
-				p.PolynomialCommitments[i][j].AsJacobian(A_ij)
+				all_commitments[i][j].AsJacobian(A_ij)
 
 				// calculate A_mj^i^j
 				term1 := new(btcec.JacobianPoint)
-				btcec.ScalarMultNonConst(i_power_map[j], A_ij, term1)
+				p.suite.scalarMult(i_power_map[j], A_ij, term1)
 
 				term_1_arr[j] = term1
 
@@ -400,13 +846,13 @@ func (p *FrostParticipant) CalculatePublicSigningShares(party_num, posi int64) *
 
 		term := new(btcec.JacobianPoint)
 		for j := int64(0); j <= p.Threshold; j++ {
-			btcec.AddNonConst(term, term_1_arr[j], term)
+			p.suite.addPoints(term, term_1_arr[j], term)
 			// p.suite.Logger.Printf("Calculating w for (participant, external, m) = (%d, %d, %d), A_%d%d%d=%v\n\n", p.Position, posi, i, posi, i, j, term_1_arr[j])
 		}
 
 		// p.suite.Logger.Printf("Calculating w for (participant, external, m) = (%d, %d, %d): %v\n", p.Position, posi, i, term)
 
-		btcec.AddNonConst(Y, term, Y)
+		p.suite.addPoints(Y, term, Y)
 	}
 	Y.ToAffine()
 
@@ -416,49 +862,215 @@ func (p *FrostParticipant) CalculatePublicSigningShares(party_num, posi int64) *
 }
 
 func (p *FrostParticipant) CalculateGroupPublicKey() *btcec.PublicKey {
+	p.GroupPublicKey = p.deriveGroupPublicKey()
+
+	return p.GroupPublicKey
+}
+
+// Y = \sum_{i} A_i0 over the stored polynomial commitments
+func (p *FrostParticipant) deriveGroupPublicKey() *btcec.PublicKey {
 	Y := new(btcec.JacobianPoint)
-	for _, commitments := range p.PolynomialCommitments {
+	for _, commitments := range p.snapshotPolynomialCommitments() {
 		A_0 := new(btcec.JacobianPoint)
 		commitments[0].AsJacobian(A_0)
-		btcec.AddNonConst(Y, A_0, Y)
+		p.suite.addPoints(Y, A_0, Y)
 	}
 	Y.ToAffine()
 
-	p.GroupPublicKey = btcec.NewPublicKey(&Y.X, &Y.Y)
+	return btcec.NewPublicKey(&Y.X, &Y.Y)
+}
 
-	return p.GroupPublicKey
+// whether a participant, e.g. restored from disk, belongs to the group of the expected key
+// the group key is derived again from the stored commitments, thus a stale or tampered GroupPublicKey is rejected as well
+func (p *FrostParticipant) BelongsToGroup(expectedGroupKey *btcec.PublicKey) bool {
+	if expectedGroupKey == nil || len(p.QualifiedSet()) == 0 {
+		return false
+	}
+
+	Y := p.deriveGroupPublicKey()
+	if p.GroupPublicKey != nil && !p.GroupPublicKey.IsEqual(Y) {
+		return false
+	}
+
+	return Y.IsEqual(expectedGroupKey)
+}
+
+// Y_D = \sum_{i \in D} A_i0, D is a subset of dealers
+// Y_D is the key of the sub - quorum of dealers in D, e.g. a group nested in a hierarchical scheme
+// the group public key is left untouched
+//
+// returns nil when no selected dealer has stored commitments
+func (p *FrostParticipant) SubGroupKey(dealers map[int64]bool) *btcec.PublicKey {
+	Y := new(btcec.JacobianPoint)
+	selected := 0
+	for posi, ok := range dealers {
+		if !ok {
+			continue
+		}
+		commitments, found := p.GetPolynomialCommitments(posi)
+		assert.True(p.suite.T, found, "sub group key: missing commitments of dealer %d", posi)
+		if !found {
+			continue
+		}
+
+		A_0 := new(btcec.JacobianPoint)
+		commitments[0].AsJacobian(A_0)
+		p.suite.addPoints(Y, A_0, Y)
+		selected++
+	}
+	if selected == 0 {
+		return nil
+	}
+	Y.ToAffine()
+
+	return btcec.NewPublicKey(&Y.X, &Y.Y)
+}
+
+// A_i0 = g^a_i0, the additive contribution of dealer i to the group public key Y = \sum_{i} A_i0
+// dealers outside the qualified set, e.g. excluded after a complaint, contribute nothing and nil is returned
+func (p *FrostParticipant) DealerContribution(dealer int64) *btcec.PublicKey {
+	commitments, ok := p.GetPolynomialCommitments(dealer)
+	if !ok || len(commitments) == 0 {
+		return nil
+	}
+
+	return commitments[0]
+}
+
+// Lagrange interpolation in the exponent
+// Y = \prod_{i \in S} Y_i^\lambda_i, S is any threshold + 1 subset of public signing shares
+//
+// the lowest threshold + 1 positions are used
+func GroupKeyFromPublicShares(publicShares map[int64]*btcec.PublicKey, threshold int64) (*btcec.PublicKey, error) {
+	if int64(len(publicShares)) <= threshold {
+		return nil, ErrNotEnoughPublicShares
+	}
+
+	set := make([]int64, 0, len(publicShares))
+	for posi := range publicShares {
+		set = append(set, posi)
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+	set = set[:threshold+1]
+
+	// Lagrange coefficients do not depend on suite state
+	suite := &TestSuite{}
+	Y := new(btcec.JacobianPoint)
+	for _, posi := range set {
+		Y_i := new(btcec.JacobianPoint)
+		publicShares[posi].AsJacobian(Y_i)
+		lambda := suite.CalculateLagrangeCoeff(posi, set)
+		term := new(btcec.JacobianPoint)
+		btcec.ScalarMultNonConst(lambda, Y_i, term)
+		btcec.AddNonConst(Y, term, Y)
+	}
+	Y.ToAffine()
+
+	return btcec.NewPublicKey(&Y.X, &Y.Y), nil
+}
+
+// test oracle for the group public key
+// Y = (\sum_{i} a_i0) * G, a_i0 is the secret of dealer i
+func ExpectedGroupKey(secrets map[int64]*btcec.ModNScalar) *btcec.PublicKey {
+	secret := new(btcec.ModNScalar)
+	for _, secret_i := range secrets {
+		secret.Add(secret_i)
+	}
+
+	Y := new(btcec.JacobianPoint)
+	btcec.ScalarBaseMultNonConst(secret, Y)
+	Y.ToAffine()
+
+	return btcec.NewPublicKey(&Y.X, &Y.Y)
+}
+
+// test oracle for the public signing share of any participant
+// Y_i = \sum_{m} \sum_{j=0}^{t} i^j * A_mj, the summed commitment polynomial evaluated at i
+// computed term by term, independently of the Q / W maps used by the protocol
+func ExpectedPublicSigningShare(index int64, dealerCommitments map[int64][]*btcec.JacobianPoint) *btcec.PublicKey {
+	index_scalar := new(btcec.ModNScalar).SetInt(uint32(index))
+
+	Y := new(btcec.JacobianPoint)
+	for _, commitments := range dealerCommitments {
+		i_power := new(btcec.ModNScalar).SetInt(1)
+		for _, A_mj := range commitments {
+			term := new(btcec.JacobianPoint)
+			btcec.ScalarMultNonConst(i_power, A_mj, term)
+			btcec.AddNonConst(Y, term, Y)
+			i_power.Mul(index_scalar)
+		}
+	}
+	Y.ToAffine()
+
+	return btcec.NewPublicKey(&Y.X, &Y.Y)
+}
+
+// the qualified set is the set of dealers whose polynomial commitments are stored
+// returned in ascending order of position
+func (p *FrostParticipant) QualifiedSet() []int64 {
+	p.commitments_mu.RLock()
+	defer p.commitments_mu.RUnlock()
+
+	qualified := make([]int64, 0, len(p.PolynomialCommitments))
+	for posi := range p.PolynomialCommitments {
+		qualified = append(qualified, posi)
+	}
+	sort.Slice(qualified, func(i, j int) bool { return qualified[i] < qualified[j] })
+
+	return qualified
+}
+
+// commit to the group formation so that it can be anchored on - chain in an OP_RETURN
+// commitment = H_tag(Y_x || i_1 || ... || i_q), {i_1, ..., i_q} is the sorted qualified set
+//
+// CalculateGroupPublicKey must be called before
+func (p *FrostParticipant) GroupKeyCommitment() [32]byte {
+	assert.NotNil(p.suite.T, p.GroupPublicKey, "group key commitment: group public key has not been calculated")
+
+	commitment_data := make([]byte, 0)
+	commitment_data = append(commitment_data, schnorr.SerializePubKey(p.GroupPublicKey)...)
+	for _, posi := range p.QualifiedSet() {
+		commitment_data = append(commitment_data, Int64ToBytes(posi)...)
+	}
+
+	return *chainhash.TaggedHash(TagFROSTGroupKeyCommitment, commitment_data)
 }
 
 func (p *FrostParticipant) GenerateSigningNonces(signing_time int64) [][2]*btcec.PublicKey {
 	p.nonces = make([][2]*btcec.ModNScalar, signing_time)
 	p.NonceCommitments = make([][2]*btcec.PublicKey, signing_time)
+	p.nonce_bindings = make(map[int64][32]byte)
 	for i := int64(0); i < signing_time; i++ {
-		// generate nonces (d, e) for each signing
-		// for pi = 1 number of pairs
-		d_seed := p.suite.Generate32BSeed()
-		e_seed := p.suite.Generate32BSeed()
+		p.nonces[i], p.NonceCommitments[i] = p.generateNoncePair()
+	}
 
-		d := new(btcec.ModNScalar)
-		d.SetBytes(&d_seed)
-		D := new(btcec.JacobianPoint)
-		btcec.ScalarBaseMultNonConst(d, D)
+	return p.NonceCommitments
+}
 
-		e := new(btcec.ModNScalar)
-		e.SetBytes(&e_seed)
-		E := new(btcec.JacobianPoint)
-		btcec.ScalarBaseMultNonConst(e, E)
+func (p *FrostParticipant) generateNoncePair() ([2]*btcec.ModNScalar, [2]*btcec.PublicKey) {
+	// generate nonces (d, e) for each signing
+	// for pi = 1 number of pairs
+	d_seed := p.suite.Generate32BSeed()
+	e_seed := p.suite.Generate32BSeed()
 
-		// normalize Z before shipping off (D, E) to other participants
-		D.ToAffine()
-		E.ToAffine()
+	d := new(btcec.ModNScalar)
+	d.SetBytes(&d_seed)
+	D := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(d, D)
 
-		p.nonces[i] = [2]*btcec.ModNScalar{d, e}
-		D_Pub := btcec.NewPublicKey(&D.X, &D.Y)
-		E_Pub := btcec.NewPublicKey(&E.X, &E.Y)
-		p.NonceCommitments[i] = [2]*btcec.PublicKey{D_Pub, E_Pub}
-	}
+	e := new(btcec.ModNScalar)
+	e.SetBytes(&e_seed)
+	E := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(e, E)
 
-	return p.NonceCommitments
+	// normalize Z before shipping off (D, E) to other participants
+	D.ToAffine()
+	E.ToAffine()
+
+	D_Pub := btcec.NewPublicKey(&D.X, &D.Y)
+	E_Pub := btcec.NewPublicKey(&E.X, &E.Y)
+
+	return [2]*btcec.ModNScalar{d, e}, [2]*btcec.PublicKey{D_Pub, E_Pub}
 }
 
 // with provided public nonces from other participants, calculate the aggregated public nonce commitments
@@ -470,34 +1082,25 @@ func (p *FrostParticipant) GenerateSigningNonces(signing_time int64) [][2]*btcec
 // and i is the participant's position
 //
 // honest would be a list of exact position starting from 1
-func (p *FrostParticipant) CalculatePublicNonceCommitments(signing_index int64, honest []int64, nonce_message_hash [32]byte, public_nonces map[int64][2]*btcec.PublicKey) map[int64]*btcec.PublicKey {
-	// calculate p_i for each honest participants
-	p_data := make([]byte, 0)
-	p_data = append(p_data, nonce_message_hash[:]...)
-	for _, j := range honest {
-		D := new(btcec.JacobianPoint)
-		public_nonces[j][0].AsJacobian(D)
-		E := new(btcec.JacobianPoint)
-		public_nonces[j][1].AsJacobian(E)
-
-		p_data = append(p_data, D.X.Bytes()[:]...)
-		p_data = append(p_data, E.X.Bytes()[:]...)
+//
+// ErrInvalidNonce is returned when the aggregated nonce commitment R is the point at infinity
+// the signature can never be valid, thus nonces must be regenerated
+// ErrRevokedParticipant is returned when a revoked member is part of the signers
+func (p *FrostParticipant) CalculatePublicNonceCommitments(signing_index int64, honest []int64, nonce_message_hash [32]byte, public_nonces map[int64][2]*btcec.PublicKey) (map[int64]*btcec.PublicKey, error) {
+	for _, i := range honest {
+		if p.revoked[i] {
+			return nil, fmt.Errorf("%w: %d", ErrRevokedParticipant, i)
+		}
 	}
 
+	// calculate p_i for each honest participants
 	p_list := make(map[int64]*btcec.ModNScalar)
 	for _, i := range honest {
-		p_i_data := append([]byte{byte(i)}, p_data...)
-		p := chainhash.HashB(p_i_data)
-		p_scalar := new(btcec.ModNScalar)
-		p_scalar.SetByteSlice(p)
-
-		p_list[i] = p_scalar
+		p_list[i] = bindingFactor(i, nonce_message_hash, honest, public_nonces)
 	}
 
-	// calculate R and R_i
+	// calculate R_i
 	nonce_commitments := make(map[int64]*btcec.PublicKey)
-	aggrNonceCommitment := new(btcec.JacobianPoint)
-
 	for _, i := range honest {
 		D_i := new(btcec.JacobianPoint)
 		public_nonces[i][0].AsJacobian(D_i)
@@ -506,68 +1109,130 @@ func (p *FrostParticipant) CalculatePublicNonceCommitments(signing_index int64,
 
 		// E_i ^ p_i
 		term := new(btcec.JacobianPoint)
-		btcec.ScalarMultNonConst(p_list[i], E_i, term)
+		p.suite.scalarMult(p_list[i], E_i, term)
 		// R_i = D_i * E_i ^ p_i
 		R_i := new(btcec.JacobianPoint)
-		btcec.AddNonConst(D_i, term, R_i)
+		p.suite.addPoints(D_i, term, R_i)
 		R_i.ToAffine()
 
 		nonce_commitments[i] = btcec.NewPublicKey(&R_i.X, &R_i.Y)
-		btcec.AddNonConst(aggrNonceCommitment, R_i, aggrNonceCommitment)
 	}
-	aggrNonceCommitment.ToAffine()
+
+	// calculate R
+	aggrNonceCommitment, err := AggregateNonceCommitments(nonce_commitments)
+	if err != nil {
+		return nil, err
+	}
+	p.sessions_mu.Lock()
 	p.AggrNonceCommitment[signing_index] = aggrNonceCommitment
+	p.sessions_mu.Unlock()
 
-	return nonce_commitments
+	return nonce_commitments, nil
+}
+
+// R of the signing index, safe to call while other signing sessions run
+func (p *FrostParticipant) aggrNonceCommitment(signing_index int64) (*btcec.JacobianPoint, bool) {
+	p.sessions_mu.RLock()
+	defer p.sessions_mu.RUnlock()
+
+	R, ok := p.AggrNonceCommitment[signing_index]
+	return R, ok
+}
+
+// R = \prod_{i} R_i
+// R is the point at infinity when nonce commitments cancel out, the signature would be invalid
+func AggregateNonceCommitments(nonce_commitments map[int64]*btcec.PublicKey) (*btcec.JacobianPoint, error) {
+	aggrNonceCommitment := new(btcec.JacobianPoint)
+	for _, R_i := range nonce_commitments {
+		R_i_point := new(btcec.JacobianPoint)
+		R_i.AsJacobian(R_i_point)
+		btcec.AddNonConst(aggrNonceCommitment, R_i_point, aggrNonceCommitment)
+	}
+	if (aggrNonceCommitment.X.IsZero() && aggrNonceCommitment.Y.IsZero()) || aggrNonceCommitment.Z.IsZero() {
+		return nil, ErrInvalidNonce
+	}
+	aggrNonceCommitment.ToAffine()
+
+	return aggrNonceCommitment, nil
+}
+
+// c = H(R, Y, m), tagged with the BIP340 challenge tag, only the x coordinate of R is committed
+func bip340Challenge(R *btcec.JacobianPoint, Y *btcec.PublicKey, msg [32]byte) *btcec.ModNScalar {
+	commitment_data := make([]byte, 0)
+	commitment_data = append(commitment_data, R.X.Bytes()[:]...)
+	commitment_data = append(commitment_data, schnorr.SerializePubKey(Y)...)
+	commitment_data = append(commitment_data, msg[:]...)
+	commitment_hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, commitment_data)
+	c := new(btcec.ModNScalar)
+	c.SetByteSlice(commitment_hash[:])
+
+	return c
 }
 
 // construct z_i = d_i + e_i * p_i + \lambda_i * s_i * c
 // \lambda_i is the Lagrange coefficient for the participant i over the honest participants
 // s_i is the long-term secret share of participant i
 // c = H(R, Y, m)
-// TODO: have not checked for even or odd Y - coordinates
+// d_i and e_i are negated when R has odd Y coordinate, s_i is negated when Y has odd Y coordinate
 func (p *FrostParticipant) PartialSign(position, signing_index int64, honest_party []int64, message_hash [32]byte, public_nonces map[int64][2]*btcec.PublicKey, signing_shares *btcec.ModNScalar) *schnorr.Signature {
+	sig, err := p.partialSign(position, signing_index, honest_party, message_hash, public_nonces, signing_shares)
+	if !assert.NoError(p.suite.T, err) {
+		return nil
+	}
+
+	return sig
+}
+
+// the checks of the session state error instead of asserting, nonces are only consumed once all of them pass
+func (p *FrostParticipant) partialSign(position, signing_index int64, honest_party []int64, message_hash [32]byte, public_nonces map[int64][2]*btcec.PublicKey, signing_shares *btcec.ModNScalar) (*schnorr.Signature, error) {
+	// the session state of the signing index is read under the sessions lock, concurrent sessions only contend here
+	p.sessions_mu.Lock()
+	var err error
+	if signing_index < 0 || signing_index >= int64(len(p.nonces)) {
+		err = fmt.Errorf("%w: signing index %d has no nonce pair", ErrIndexOutOfRange, signing_index)
+	}
+	if err == nil {
+		err = p.checkNonceBinding(signing_index, message_hash)
+	}
+	if err == nil {
+		err = p.checkNonceFreshness(signing_index)
+	}
+	R, ok := p.AggrNonceCommitment[signing_index]
+	if err == nil && (!ok || R == nil) {
+		err = fmt.Errorf("%w: partial sign, signing index %d", ErrMissingAggrNonce, signing_index)
+	}
+	var nonces [2]*btcec.ModNScalar
+	if err == nil {
+		nonces = p.takeNonces(signing_index, nonceSessionBinding(message_hash, honest_party, public_nonces))
+	}
+	p.sessions_mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer zeroNonces(nonces)
+
 	// calculate c
-	commitment_data := make([]byte, 0)
-	commitment_data = append(commitment_data, p.AggrNonceCommitment[signing_index].X.Bytes()[:]...)
-	commitment_data = append(commitment_data, schnorr.SerializePubKey(p.GroupPublicKey)...)
-	commitment_data = append(commitment_data, message_hash[:]...)
-	commitment_hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, commitment_data)
-	c := new(btcec.ModNScalar)
-	c.SetByteSlice(commitment_hash[:])
+	c := bip340Challenge(R, p.GroupPublicKey, message_hash)
 
 	// calculate p_i
-	p_i_data := make([]byte, 0)
-	p_i_data = append(p_i_data, byte(position))
-	p_i_data = append(p_i_data, message_hash[:]...)
-	for _, i := range honest_party {
-		D := new(btcec.JacobianPoint)
-		public_nonces[i][0].AsJacobian(D)
-		E := new(btcec.JacobianPoint)
-		public_nonces[i][1].AsJacobian(E)
-		p_i_data = append(p_i_data, D.X.Bytes()[:]...)
-		p_i_data = append(p_i_data, E.X.Bytes()[:]...)
-	}
-	p_i := chainhash.HashB(p_i_data)
-	p_i_scalar := new(btcec.ModNScalar)
-	p_i_scalar.SetByteSlice(p_i)
+	p_i_scalar := bindingFactor(position, message_hash, honest_party, public_nonces)
 
-	// d_i, e_i: create new instances to avoid modifying the original values
-	d_i := new(btcec.ModNScalar).Set(p.nonces[signing_index][0])
-	e_i := new(btcec.ModNScalar).Set(p.nonces[signing_index][1])
+	// d_i, e_i: the copy handed out by takeNonces, zeroed on return
+	d_i := nonces[0]
+	e_i := nonces[1]
 	// e_i * p_i
 	term := new(btcec.ModNScalar).Mul2(e_i, p_i_scalar)
 	// d_i + e_i * p_i
 	term1 := new(btcec.ModNScalar).Add2(d_i, term)
 	R_i := new(btcec.JacobianPoint)
-	btcec.ScalarBaseMultNonConst(term1, R_i)
+	p.suite.scalarBaseMult(term1, R_i)
 	R_i.ToAffine()
 
 	// some R_i might have even Y coordinate, but total R can have odd Y coordinate
 	// thus, we need to negate all d_i and e_i to satisfy even Y coordinate for R
 	// this will conflict with any even Y coordinate in R_i
 	// this is such dilema that we should not check for oddness in R_i
-	if p.AggrNonceCommitment[signing_index].Y.IsOdd() {
+	if R.Y.IsOdd() {
 		d_i.Negate()
 		e_i.Negate()
 	}
@@ -590,7 +1255,7 @@ func (p *FrostParticipant) PartialSign(position, signing_index int64, honest_par
 
 	sig := schnorr.NewSignature(&R_i.X, z_i)
 
-	return sig
+	return sig, nil
 }
 
 // construct z_i = d_i + e_i * p_i + \sum_{K_i} \lambda_{ik} * s_{ik} * c, K_i is the threshold set of honest keys of participant i
@@ -602,13 +1267,7 @@ func (p *FrostParticipant) PartialSign(position, signing_index int64, honest_par
 // a different variant of partial sign for wsts
 func (p *FrostParticipant) WeightedPartialSign(position, signing_index int64, honest_party, honest_keys []int64, message_hash [32]byte, public_nonces map[int64][2]*btcec.PublicKey, signing_shares map[int64]*btcec.ModNScalar) *schnorr.Signature {
 	// calculate c
-	commitment_data := make([]byte, 0)
-	commitment_data = append(commitment_data, p.AggrNonceCommitment[signing_index].X.Bytes()[:]...)
-	commitment_data = append(commitment_data, schnorr.SerializePubKey(p.GroupPublicKey)...)
-	commitment_data = append(commitment_data, message_hash[:]...)
-	commitment_hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, commitment_data)
-	c := new(btcec.ModNScalar)
-	c.SetByteSlice(commitment_hash[:])
+	c := bip340Challenge(p.AggrNonceCommitment[signing_index], p.GroupPublicKey, message_hash)
 
 	p.logger.Printf("sign c: %v, group pubkey: %v, aggr nonce commitments: %v\n", c, p.GroupPublicKey, p.AggrNonceCommitment[signing_index].X)
 
@@ -636,7 +1295,7 @@ func (p *FrostParticipant) WeightedPartialSign(position, signing_index int64, ho
 	// d_i + e_i * p_i
 	term1 := new(btcec.ModNScalar).Add2(d_i, term)
 	R_i := new(btcec.JacobianPoint)
-	btcec.ScalarBaseMultNonConst(term1, R_i)
+	p.suite.scalarBaseMult(term1, R_i)
 	R_i.ToAffine()
 
 	// some R_i might have even Y coordinate, but total R can have odd Y coordinate
@@ -664,7 +1323,7 @@ func (p *FrostParticipant) WeightedPartialSign(position, signing_index int64, ho
 
 		// check shares
 		temp := new(btcec.JacobianPoint)
-		btcec.ScalarBaseMultNonConst(s_i, temp)
+		p.suite.scalarBaseMult(s_i, temp)
 		temp.ToAffine()
 		p.logger.Printf("sign key_index %d: signing shares verification %v\n", key_index, temp)
 
@@ -700,13 +1359,7 @@ func (p *FrostParticipant) WeightedPartialVerification(sig *schnorr.Signature, s
 	z.SetByteSlice(z_bytes)
 
 	// calculate c = H(R, Y, m)
-	commitment_data := make([]byte, 0)
-	commitment_data = append(commitment_data, p.AggrNonceCommitment[signing_index].X.Bytes()[:]...)
-	commitment_data = append(commitment_data, schnorr.SerializePubKey(p.GroupPublicKey)...)
-	commitment_data = append(commitment_data, message_hash[:]...)
-	commitment_hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, commitment_data)
-	c := new(btcec.ModNScalar)
-	c.SetByteSlice(commitment_hash[:])
+	c := bip340Challenge(p.AggrNonceCommitment[signing_index], p.GroupPublicKey, message_hash)
 
 	p.logger.Printf("message_hash: %v\n", message_hash)
 	p.logger.Printf("verify c: %v\n", c)
@@ -734,17 +1387,17 @@ func (p *FrostParticipant) WeightedPartialVerification(sig *schnorr.Signature, s
 
 		// Y_{ik}^-(\lambda_{ik} * c)
 		term1 := new(btcec.JacobianPoint)
-		btcec.ScalarMultNonConst(term, Y_i, term1)
+		p.suite.scalarMult(term, Y_i, term1)
 
-		btcec.AddNonConst(prod, term1, prod)
+		p.suite.addPoints(prod, term1, prod)
 	}
 
 	// g^z_i
 	term2 := new(btcec.JacobianPoint)
-	btcec.ScalarBaseMultNonConst(z, term2)
+	p.suite.scalarBaseMult(z, term2)
 	// R_i = g^z_i * \prod_{K_i} Y_{ik}^-(\lambda_{ik} * c)
 	R := new(btcec.JacobianPoint)
-	btcec.AddNonConst(term2, prod, R)
+	p.suite.addPoints(term2, prod, R)
 
 	// Fail if R is the point at infinity
 	is_infinity := false
@@ -766,34 +1419,72 @@ func (p *FrostParticipant) WeightedPartialVerification(sig *schnorr.Signature, s
 // BATCH CALCULATION
 
 // derive power map for future calculation
+// the rows come from the attached power table, a table is built and attached first when none matches the group
 func (p *FrostParticipant) DerivePowerMap() {
-	var wg sync.WaitGroup
-	// time_now := time.Now()
+	p.DerivePowerMapCtx(context.Background())
+}
+
+// same as DerivePowerMap, ctx is checked while the power table is built and before each row is stored
+// on cancellation ctx.Err() is returned, a table built in part is never attached
+func (p *FrostParticipant) DerivePowerMapCtx(ctx context.Context) error {
+	if !p.power_table.matches(p.N, p.Threshold) {
+		table, err := newPowerTableCtx(ctx, p.N, p.Threshold)
+		if err != nil {
+			return err
+		}
+		p.power_table = table
+	}
 	for posi := int64(1); posi <= p.N; posi++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p.StorePowerMapItem(posi, p.power_table.Row(posi))
+	}
+
+	return nil
+}
+
+// derive power map only for the indices of a sparse signer set
+// other indices are left untouched, thus must not be read afterwards
+func (p *FrostParticipant) DerivePowerMapForSet(indices map[int64]bool) {
+	var wg sync.WaitGroup
+	for posi, ok := range indices {
+		if !ok {
+			continue
+		}
+
 		wg.Add(1)
 		go func(posi int64) {
 			defer wg.Done()
-			posi_scalar := new(btcec.ModNScalar)
-			posi_scalar.SetInt(uint32(posi))
-
-			i_power_arr := make([]*btcec.ModNScalar, p.Threshold+1)
-			i_power := new(btcec.ModNScalar)
-			i_power.SetInt(1)
-			for j := int64(0); j <= p.Threshold; j++ {
-				i_power_arr[j] = new(btcec.ModNScalar).Set(i_power)
-				i_power.Mul(posi_scalar)
-			}
-			p.StorePowerMapItem(posi, i_power_arr)
+			p.derivePowerMapItem(posi)
 		}(posi)
 	}
 	wg.Wait()
-	if p.suite.B != nil {
-		// p.suite.LogBenchmarkThreadSafeReport(fmt.Sprintf("ms/derive-power-map-%d", p.Position), float64(time.Since(time_now).Milliseconds()), true)
+}
+
+func (p *FrostParticipant) derivePowerMapItem(posi int64) {
+	p.StorePowerMapItem(posi, powers(posi, p.Threshold))
+}
+
+// i^j, j \in [0,t]
+func powers(posi, threshold int64) []*btcec.ModNScalar {
+	posi_scalar := new(btcec.ModNScalar)
+	posi_scalar.SetInt(uint32(posi))
+
+	i_power_arr := make([]*btcec.ModNScalar, threshold+1)
+	i_power := new(btcec.ModNScalar)
+	i_power.SetInt(1)
+	for j := int64(0); j <= threshold; j++ {
+		i_power_arr[j] = new(btcec.ModNScalar).Set(i_power)
+		i_power.Mul(posi_scalar)
 	}
+
+	return i_power_arr
 }
 
 // derive Q_j(i) = \prod_{m=1}^{n_p} A_mj, j \in [0,t]  Q_mj map for calculation of public signing shares
 func (p *FrostParticipant) DeriveExternalQMap() {
+	all_commitments := p.snapshotPolynomialCommitments()
 	var wg sync.WaitGroup
 	// time_now := time.Now()
 	for posi := int64(1); posi <= p.N; posi++ {
@@ -804,12 +1495,12 @@ func (p *FrostParticipant) DeriveExternalQMap() {
 			for j := int64(0); j <= p.Threshold; j++ {
 				// calculate \prod_{m=1}^{n_p} A_mj
 				term := new(btcec.JacobianPoint)
-				for _, commitments := range p.PolynomialCommitments {
+				for _, commitments := range all_commitments {
 					A_mj_point := new(btcec.JacobianPoint)
 					A_mj := commitments[j]
 					A_mj.AsJacobian(A_mj_point)
 
-					btcec.AddNonConst(term, A_mj_point, term)
+					p.suite.addPoints(term, A_mj_point, term)
 
 					// p.suite.Logger.Printf("Participant %d, calculating batch public shares for %d: A_%d%d%d = %v\n", p.Position, posi, posi, m, j, term1)
 					// p.suite.Logger.Printf("A_mj: %v, i_power_arr: %v\n", A_mj_point, i_power_arr[j])
@@ -842,7 +1533,7 @@ func (p *FrostParticipant) DeriveExternalWMap() {
 				go func(j int64) {
 					defer wg1.Done()
 					term := new(btcec.JacobianPoint)
-					btcec.ScalarMultNonConst(i_j_arr[j], Q_j_arr[j], term)
+					p.suite.scalarMult(i_j_arr[j], Q_j_arr[j], term)
 					W_j_arr[j] = term
 				}(j)
 			}
@@ -857,73 +1548,135 @@ func (p *FrostParticipant) DeriveExternalWMap() {
 }
 
 // verify batch public secret shares for a participant secret shares
+// all shares are checked at once against a random linear combination of C_j = \prod_{k} A_jk^i^k
+// only a failing subset is bisected down to single shares, thus honest dealers cost a single check
+// C_j and the weighted sums are computed with MultiScalarMul
+// with BatchVerifyMSM, the whole batch is first checked by a single multi scalar multiplication
 //
+// the dealers of invalid shares are returned with ErrInvalidSecretShare, a complaint can name them
 // expensive operation
-func (p *FrostParticipant) VerifyBatchPublicSecretShares(secret_shares map[int64]*btcec.ModNScalar, posi uint32) {
-	var wg sync.WaitGroup
-	// time_now := time.Now()
-
-	// calculate expected A_i for all parties
-	// make(map[int64]*btcec.JacobianPoint)
-	// s_ji = f_j(i)
-	all_expected_A := make(map[int64]*btcec.JacobianPoint)
-	for index, shares := range secret_shares {
-		expected_A := new(btcec.JacobianPoint)
-		btcec.ScalarBaseMultNonConst(shares, expected_A)
-		expected_A.ToAffine()
-		all_expected_A[index] = expected_A
-	}
+func (p *FrostParticipant) VerifyBatchPublicSecretShares(secret_shares map[int64]*btcec.ModNScalar, posi uint32) ([]int64, error) {
+	return p.VerifyBatchPublicSecretSharesCtx(context.Background(), secret_shares, posi)
+}
 
+// same as VerifyBatchPublicSecretShares, ctx is checked before each commitment C_j and each batch check
+// on cancellation no dealer is blamed and ctx.Err() is returned
+func (p *FrostParticipant) VerifyBatchPublicSecretSharesCtx(ctx context.Context, secret_shares map[int64]*btcec.ModNScalar, posi uint32) ([]int64, error) {
 	i_power_arr := p.GetPowerMapItem(int64(posi))
 
-	// calculate map of A_k^i^k for all parties
-	// make(map[int64][]*btcec.JacobianPoint)
-	all_term := sync.Map{}
-	for posi, poly_commitments := range p.PolynomialCommitments {
+	// dealers without commitments or without share are blamed right away
+	all_commitments := p.snapshotPolynomialCommitments()
+	bad_dealers := make([]int64, 0)
+	dealers := make([]int64, 0, len(secret_shares))
+	for dealer := range secret_shares {
+		if _, ok := all_commitments[dealer]; !ok {
+			bad_dealers = append(bad_dealers, dealer)
+			continue
+		}
+		dealers = append(dealers, dealer)
+	}
+	for dealer := range all_commitments {
+		if _, ok := secret_shares[dealer]; !ok {
+			bad_dealers = append(bad_dealers, dealer)
+		}
+	}
+	sort.Slice(dealers, func(i, j int) bool { return dealers[i] < dealers[j] })
+	if p.batch_verify_strategy == BatchVerifyMSM && ctx.Err() == nil && p.verifySharesSingleMSM(secret_shares, dealers, i_power_arr) {
+		// every received share is valid, only the dealers blamed above remain
+		dealers = nil
+	}
+
+	// C_j = \prod_{k} A_jk^i^k, the commitment of f_j(i), a multi scalar multiplication over the t + 1 commitments
+	expected_commitments := make([]*btcec.JacobianPoint, len(dealers))
+	var wg sync.WaitGroup
+	for index, dealer := range dealers {
 		wg.Add(1)
-		go func(posi int64, poly_commitments []*btcec.PublicKey) {
-			term_arr := make([]*secp.JacobianPoint, p.Threshold+1)
-			var wg1 sync.WaitGroup
-			for i := int64(0); i <= p.Threshold; i++ {
-				wg1.Add(1)
-				go func(i int64) {
-					term := new(btcec.JacobianPoint)
-					poly_commitments[i].AsJacobian(term)
-					btcec.ScalarMultNonConst(i_power_arr[i], term, term)
-					term_arr[i] = term
-					wg1.Done()
-				}(i)
+		go func(index int, poly_commitments []*btcec.PublicKey) {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return
 			}
-			wg1.Wait()
-			all_term.Store(posi, term_arr)
-			wg.Done()
-		}(posi, poly_commitments)
+
+			points := make([]*btcec.JacobianPoint, len(poly_commitments))
+			for k, commitment := range poly_commitments {
+				points[k] = new(btcec.JacobianPoint)
+				commitment.AsJacobian(points[k])
+			}
+			// a commitment list of another degree leaves C_j nil, the dealer is blamed below
+			C_j := new(btcec.JacobianPoint)
+			if err := p.suite.multiScalarMul(i_power_arr, points, C_j); err != nil {
+				return
+			}
+			expected_commitments[index] = C_j
+		}(index, all_commitments[dealer])
 	}
 	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	// calculate prod(A_k^i^k) for all parties
-	// then verify against expected values
-	all_term.Range(func(key any, value any) bool {
-		index := key.(int64)
-		term := value.([]*btcec.JacobianPoint)
-		wg.Add(1)
-		go func(index int64, term []*btcec.JacobianPoint) {
-			calculated_A := new(btcec.JacobianPoint)
-			for _, val := range term {
-				btcec.AddNonConst(calculated_A, val, calculated_A)
+	// random weights a_j keep dealers from cancelling out each other's invalid shares
+	weights := make([]*btcec.ModNScalar, len(dealers))
+	for index := range dealers {
+		seed := p.suite.Generate32BSeed()
+		weights[index] = new(btcec.ModNScalar)
+		weights[index].SetBytes(&seed)
+	}
+
+	// g^(\sum_{j \in S} a_j * s_ji) = \prod_{j \in S} C_j^a_j, a single dealer is checked without weight
+	var verify func(indices []int)
+	verify = func(indices []int) {
+		if len(indices) == 0 || ctx.Err() != nil {
+			return
+		}
+		if len(indices) == 1 {
+			actual := new(btcec.JacobianPoint)
+			p.suite.scalarBaseMult(secret_shares[dealers[indices[0]]], actual)
+			if !equalPoints(actual, expected_commitments[indices[0]]) {
+				bad_dealers = append(bad_dealers, dealers[indices[0]])
 			}
-			calculated_A.ToAffine()
-			expected_A := all_expected_A[index]
-			assert.Equal(p.suite.T, expected_A, calculated_A)
-			wg.Done()
-		}(index, term)
+			return
+		}
 
-		return true
-	})
-	wg.Wait()
-	if p.suite.B != nil {
-		// p.suite.LogBenchmarkThreadSafeReport(fmt.Sprintf("ms/verify-batch-secret-shares-%d", p.Position), float64(time.Since(time_now).Milliseconds()), true)
+		lhs_scalar := new(btcec.ModNScalar)
+		subset_weights := make([]*btcec.ModNScalar, len(indices))
+		subset_commitments := make([]*btcec.JacobianPoint, len(indices))
+		for k, index := range indices {
+			lhs_scalar.Add(new(btcec.ModNScalar).Mul2(weights[index], secret_shares[dealers[index]]))
+			subset_weights[k] = weights[index]
+			subset_commitments[k] = expected_commitments[index]
+		}
+		rhs := new(btcec.JacobianPoint)
+		msm_err := p.suite.multiScalarMul(subset_weights, subset_commitments, rhs)
+		lhs := new(btcec.JacobianPoint)
+		p.suite.scalarBaseMult(lhs_scalar, lhs)
+		if msm_err == nil && equalPoints(lhs, rhs) {
+			return
+		}
+
+		// bisect the failing subset
+		verify(indices[:len(indices)/2])
+		verify(indices[len(indices)/2:])
+	}
+	indices := make([]int, 0, len(dealers))
+	for index, dealer := range dealers {
+		if expected_commitments[index] == nil {
+			bad_dealers = append(bad_dealers, dealer)
+			continue
+		}
+		indices = append(indices, index)
+	}
+	verify(indices)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(bad_dealers) == 0 {
+		return nil, nil
 	}
+	sort.Slice(bad_dealers, func(i, j int) bool { return bad_dealers[i] < bad_dealers[j] })
+
+	return bad_dealers, fmt.Errorf("%w: dealers %v", ErrInvalidSecretShare, bad_dealers)
 }
 
 // CalculateBatchPublicSigningShares calculates the public signing shares for other participants
@@ -947,7 +1700,7 @@ func (p *FrostParticipant) CalculateBatchPublicSigningShares(skip_positions map[
 			W_m := p.GetWMapItem(posi)
 			Y := new(btcec.JacobianPoint)
 			for _, W := range W_m {
-				btcec.AddNonConst(Y, W, Y)
+				p.suite.addPoints(Y, W, Y)
 			}
 			Y.ToAffine()
 			p.StorePublicSigningShares(posi, btcec.NewPublicKey(&Y.X, &Y.Y))
@@ -958,3 +1711,47 @@ func (p *FrostParticipant) CalculateBatchPublicSigningShares(skip_positions map[
 		// p.suite.LogBenchmarkThreadSafeReport(fmt.Sprintf("ms/calculate-batch-public-signing-shares-%d", p.Position), float64(time.Since(time_now).Milliseconds()), true)
 	}
 }
+
+// only the public signing shares of the active signer set are needed for signing
+// Q_j = \prod_{m=1}^{n_p} A_mj is the same for all signers, thus derived once
+// Y_i = \prod_{j=0}^{t} Q_j^i^j, i \in signers
+//
+// computation: O(n*t) for Q_j, O(t) for each signer
+func (p *FrostParticipant) CalculatePublicSigningSharesForSet(signers map[int64]bool) {
+	all_commitments := p.snapshotPolynomialCommitments()
+	Q_j_arr := make([]*btcec.JacobianPoint, p.Threshold+1)
+	for j := int64(0); j <= p.Threshold; j++ {
+		term := new(btcec.JacobianPoint)
+		for _, commitments := range all_commitments {
+			A_mj_point := new(btcec.JacobianPoint)
+			commitments[j].AsJacobian(A_mj_point)
+			p.suite.addPoints(term, A_mj_point, term)
+		}
+		Q_j_arr[j] = term
+	}
+
+	var wg sync.WaitGroup
+	for posi, ok := range signers {
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(posi int64) {
+			defer wg.Done()
+			posi_scalar := new(btcec.ModNScalar).SetInt(uint32(posi))
+			i_power := new(btcec.ModNScalar).SetInt(1)
+			Y := new(btcec.JacobianPoint)
+			for j := int64(0); j <= p.Threshold; j++ {
+				// Q_j^i^j
+				term := new(btcec.JacobianPoint)
+				p.suite.scalarMult(i_power, Q_j_arr[j], term)
+				p.suite.addPoints(Y, term, Y)
+				i_power.Mul(posi_scalar)
+			}
+			Y.ToAffine()
+			p.StorePublicSigningShares(posi, btcec.NewPublicKey(&Y.X, &Y.Y))
+		}(posi)
+	}
+	wg.Wait()
+}
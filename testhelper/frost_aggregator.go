@@ -0,0 +1,206 @@
+package testhelper
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"time"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ErrThresholdNotMet     = errors.New("aggregate partial signatures: threshold not met")
+	ErrConflictingPartials = errors.New("frost aggregator: signer submitted conflicting partial signatures")
+)
+
+// FrostAggregator is the signing coordinator role
+// it collects partial signatures from honest participants and aggregates them into a BIP340 Schnorr signature
+//
+// the aggregator is also a Frost participant, it shares the same group public key and aggregated nonce commitments
+type FrostAggregator struct {
+	suite *TestSuite
+
+	Frost *FrostParticipant
+
+	// signers whose partial signatures were used in the last aggregation
+	included map[int64]bool
+	// identity public keys of the signers, keyed by position
+	identity_keys map[int64]*btcec.PublicKey
+	// first partial signature received from each signer, keyed by signing index then position
+	received_partials map[int64]map[int64]*schnorr.Signature
+	// distinct partial signatures received after the first one
+	conflicts []ConflictingPartials
+	// session binding of each nonce commitment H(D || E) seen, keyed by position
+	nonce_sessions map[int64]map[[32]byte][32]byte
+}
+
+// ConflictingPartials is the evidence of a signer submitting two distinct partial signatures in one session
+type ConflictingPartials struct {
+	SigningIndex int64
+	Position     int64
+	First        *schnorr.Signature
+	Second       *schnorr.Signature
+}
+
+func NewFrostAggregator(suite *TestSuite, frost *FrostParticipant) *FrostAggregator {
+	aggregator := &FrostAggregator{
+		suite:             suite,
+		Frost:             frost,
+		included:          make(map[int64]bool),
+		identity_keys:     make(map[int64]*btcec.PublicKey),
+		received_partials: make(map[int64]map[int64]*schnorr.Signature),
+		nonce_sessions:    make(map[int64]map[[32]byte][32]byte),
+	}
+
+	return aggregator
+}
+
+// z = \sum_{i \in S} z_i, S is the set of honest participants
+// the aggregated signature is (R, z), R is the aggregated nonce commitment for the signing index
+//
+// partial signatures are expected to be verified before aggregation
+func (a *FrostAggregator) AggregatePartialSignatures(signing_index int64, partial_sigs map[int64]*schnorr.Signature) *schnorr.Signature {
+	R, ok := a.Frost.aggrNonceCommitment(signing_index)
+	assert.True(a.suite.T, ok, "aggregate partial signatures: missing aggregated nonce commitment")
+
+	a.included = make(map[int64]bool)
+	z := new(btcec.ModNScalar)
+	for posi, p_sig := range partial_sigs {
+		a.included[posi] = true
+
+		// extract z_i from the partial signature
+		z_i := new(btcec.ModNScalar)
+		z_i.SetByteSlice(p_sig.Serialize()[32:64])
+		z.Add(z_i)
+	}
+
+	return schnorr.NewSignature(&R.X, z)
+}
+
+// record a partial signature received from the signer for the signing index
+// aggregation does not record partials, thus the collecting layer submits every received partial
+// an honest signer sends a single partial per session, a distinct one is kept as conflict evidence
+func (a *FrostAggregator) SubmitPartial(signing_index, posi int64, partial_sig *schnorr.Signature) {
+	session, ok := a.received_partials[signing_index]
+	if !ok {
+		session = make(map[int64]*schnorr.Signature)
+		a.received_partials[signing_index] = session
+	}
+
+	first, ok := session[posi]
+	if !ok {
+		session[posi] = partial_sig
+		return
+	}
+	if bytes.Equal(first.Serialize(), partial_sig.Serialize()) {
+		return
+	}
+	for _, conflict := range a.conflicts {
+		if conflict.SigningIndex == signing_index && conflict.Position == posi && bytes.Equal(conflict.Second.Serialize(), partial_sig.Serialize()) {
+			return
+		}
+	}
+	a.conflicts = append(a.conflicts, ConflictingPartials{
+		SigningIndex: signing_index,
+		Position:     posi,
+		First:        first,
+		Second:       partial_sig,
+	})
+}
+
+// flag signers that submitted distinct partial signatures in one session, sorted by signing index then position
+// either partial may be valid, thus both are returned as evidence
+func (a *FrostAggregator) DetectConflictingPartials() ([]ConflictingPartials, error) {
+	if len(a.conflicts) == 0 {
+		return nil, nil
+	}
+
+	conflicts := make([]ConflictingPartials, len(a.conflicts))
+	copy(conflicts, a.conflicts)
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		if conflicts[i].SigningIndex != conflicts[j].SigningIndex {
+			return conflicts[i].SigningIndex < conflicts[j].SigningIndex
+		}
+		return conflicts[i].Position < conflicts[j].Position
+	})
+
+	return conflicts, ErrConflictingPartials
+}
+
+// whether the partial signature of the signer was used in the last aggregation
+// an honest signer can check its contribution was counted in accountability disputes
+func (a *FrostAggregator) ContributionIncluded(signer_index int64) bool {
+	return a.included[signer_index]
+}
+
+// the secret polynomial has degree Threshold, thus at least Threshold + 1 partial signatures are needed
+// refuse to aggregate otherwise since the result can never verify under the group public key
+func (a *FrostAggregator) AggregateStrict(signing_index int64, partial_sigs map[int64]*schnorr.Signature) (*schnorr.Signature, error) {
+	if int64(len(partial_sigs)) <= a.Frost.Threshold {
+		return nil, ErrThresholdNotMet
+	}
+
+	return a.AggregatePartialSignatures(signing_index, partial_sigs), nil
+}
+
+// B = {D_1, E_1, ..., D_t, E_t}, sorted by signer index
+// B is the commitment list hashed into binding factors p_i = H(i, m, B)
+//
+// signers can compare their encodings to diagnose disagreements on the binding factors
+func (a *FrostAggregator) EncodeCommitmentList(commitments map[int64][2]*btcec.JacobianPoint) []byte {
+	indices := make([]int64, 0, len(commitments))
+	for i := range commitments {
+		indices = append(indices, i)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	encoding := make([]byte, 0, len(indices)*64)
+	for _, i := range indices {
+		// copy before normalizing to avoid modifying the provided commitments
+		D := new(btcec.JacobianPoint)
+		D.Set(commitments[i][0])
+		D.ToAffine()
+		E := new(btcec.JacobianPoint)
+		E.Set(commitments[i][1])
+		E.ToAffine()
+
+		encoding = append(encoding, D.X.Bytes()[:]...)
+		encoding = append(encoding, E.X.Bytes()[:]...)
+	}
+
+	return encoding
+}
+
+// \lambda_i = \prod_{j \in S, j != i} j / (j - i), S is the signer set
+// coefficients interpolate f(0), thus \sum_{i \in S} \lambda_i = 1 mod N
+// all denominators are inverted at once, see CalculateLagrangeCoeffs
+func (a *FrostAggregator) LagrangeCoefficients(signers map[int64]bool) map[int64]*btcec.ModNScalar {
+	set := make([]int64, 0, len(signers))
+	for posi, ok := range signers {
+		if ok {
+			set = append(set, posi)
+		}
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+
+	return a.suite.CalculateLagrangeCoeffs(set)
+}
+
+// signing waits for the slowest signer, thus the latency is the max latency among the chosen signers
+// helps picking a fast signer subset
+func (a *FrostAggregator) SigningLatency(signer_latencies map[int64]time.Duration, signers map[int64]bool) time.Duration {
+	latency := time.Duration(0)
+	for posi, ok := range signers {
+		if !ok {
+			continue
+		}
+		if signer_latencies[posi] > latency {
+			latency = signer_latencies[posi]
+		}
+	}
+
+	return latency
+}
@@ -0,0 +1,116 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	TagFROSTAttribution = []byte("FROST/attribution")
+
+	ErrMissingIdentityKey   = errors.New("attributable partial: identity key not set")
+	ErrUnknownIdentity      = errors.New("attributable partial: signer identity key not registered")
+	ErrInvalidAttribution   = errors.New("attributable partial: attribution signature does not verify")
+	ErrAttributionMalformed = errors.New("attributable partial: missing partial or attribution signature")
+)
+
+// AttributablePartial is a partial signature bound to the identity of its signer
+// a signer cannot later deny a partial it attributed, e.g. in misbehaviour disputes
+type AttributablePartial struct {
+	Position     int64
+	SigningIndex int64
+	Partial      *schnorr.Signature
+	// BIP340 signature over AttributionHash by the identity key of the signer
+	Attribution *schnorr.Signature
+}
+
+// generate the identity keypair of the participant, the public key is registered with the aggregator
+// the identity key is independent of the signing share
+func (p *FrostParticipant) GenerateIdentityKey() *btcec.PublicKey {
+	seed := p.suite.Generate32BSeed()
+	p.identity_key, _ = btcec.PrivKeyFromBytes(seed[:])
+
+	return p.identity_key.PubKey()
+}
+
+func (p *FrostParticipant) IdentityPublicKey() *btcec.PublicKey {
+	if p.identity_key == nil {
+		return nil
+	}
+	return p.identity_key.PubKey()
+}
+
+// h = H(i || signing_index || m || R_i || z_i)
+func AttributionHash(position, signing_index int64, message_hash [32]byte, partial *schnorr.Signature) [32]byte {
+	data := make([]byte, 0)
+	data = append(data, Int64ToBytes(position)...)
+	data = append(data, Int64ToBytes(signing_index)...)
+	data = append(data, message_hash[:]...)
+	data = append(data, partial.Serialize()...)
+
+	return *chainhash.TaggedHash(TagFROSTAttribution, data)
+}
+
+// partial sign, then attribute the partial signature with the identity key
+func (p *FrostParticipant) PartialSignAttributable(position, signing_index int64, honest_party []int64, message_hash [32]byte, public_nonces map[int64][2]*btcec.PublicKey, signing_shares *btcec.ModNScalar) (*AttributablePartial, error) {
+	if p.identity_key == nil {
+		return nil, ErrMissingIdentityKey
+	}
+
+	partial := p.PartialSign(position, signing_index, honest_party, message_hash, public_nonces, signing_shares)
+	attribution_hash := AttributionHash(position, signing_index, message_hash, partial)
+	attribution, err := schnorr.Sign(p.identity_key, attribution_hash[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttributablePartial{
+		Position:     position,
+		SigningIndex: signing_index,
+		Partial:      partial,
+		Attribution:  attribution,
+	}, nil
+}
+
+func (a *FrostAggregator) RegisterIdentityKey(position int64, identity *btcec.PublicKey) {
+	a.identity_keys[position] = identity
+}
+
+// the attribution must verify under the registered identity key of the claimed signer
+func (a *FrostAggregator) VerifyAttribution(message_hash [32]byte, attributable *AttributablePartial) error {
+	if attributable == nil || attributable.Partial == nil || attributable.Attribution == nil {
+		return ErrAttributionMalformed
+	}
+	identity, ok := a.identity_keys[attributable.Position]
+	if !ok {
+		return ErrUnknownIdentity
+	}
+
+	attribution_hash := AttributionHash(attributable.Position, attributable.SigningIndex, message_hash, attributable.Partial)
+	if !attributable.Attribution.Verify(attribution_hash[:], identity) {
+		return ErrInvalidAttribution
+	}
+
+	return nil
+}
+
+// verify the attribution of every partial before aggregating
+// a single unattributed partial aborts the aggregation
+func (a *FrostAggregator) AggregateAttributable(signing_index int64, message_hash [32]byte, partials map[int64]*AttributablePartial) (*schnorr.Signature, error) {
+	partial_sigs := make(map[int64]*schnorr.Signature, len(partials))
+	for posi, attributable := range partials {
+		if err := a.VerifyAttribution(message_hash, attributable); err != nil {
+			return nil, fmt.Errorf("signer %d: %w", posi, err)
+		}
+		if attributable.Position != posi || attributable.SigningIndex != signing_index {
+			return nil, fmt.Errorf("signer %d: %w", posi, ErrInvalidAttribution)
+		}
+		partial_sigs[posi] = attributable.Partial
+	}
+
+	return a.AggregateStrict(signing_index, partial_sigs)
+}
@@ -0,0 +1,146 @@
+package testhelper
+
+import (
+	"sort"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+// SecretProof is a proof of knowledge of the secret a_i0 for commitment A_i0
+// produced by CalculateSecretProofs over the context hash
+type SecretProof struct {
+	ContextHash [32]byte
+	Signature   *schnorr.Signature
+}
+
+// terms of the verification equation g^\mu_i = R_i * A_i0^c_i
+type secretProofTerms struct {
+	mu *btcec.ModNScalar
+	c  *btcec.ModNScalar
+	R  *btcec.JacobianPoint
+	A  *btcec.JacobianPoint
+}
+
+func (p *FrostParticipant) parseSecretProof(position int64, proof *SecretProof, commitment *btcec.JacobianPoint) (*secretProofTerms, bool) {
+	proof_bytes := proof.Signature.Serialize()
+
+	// R_i is lifted with even Y coordinate
+	R_pubkey, err := schnorr.ParsePubKey(proof_bytes[0:32])
+	if err != nil {
+		return nil, false
+	}
+	mu := new(btcec.ModNScalar)
+	if overflow := mu.SetByteSlice(proof_bytes[32:64]); overflow {
+		return nil, false
+	}
+
+	// making even the commitment Y coordinate, negating the affine point avoids lifting x again
+	A := new(btcec.JacobianPoint)
+	A.Set(commitment)
+	A.ToAffine()
+	if A.X.IsZero() && A.Y.IsZero() {
+		return nil, false
+	}
+	if A.Y.IsOdd() {
+		A.Y.Negate(1).Normalize()
+	}
+
+	terms := &secretProofTerms{
+		mu: mu,
+		R:  new(btcec.JacobianPoint),
+		A:  A,
+	}
+	R_pubkey.AsJacobian(terms.R)
+	terms.c = p.CalculateSecretProofsChallenge(proof.ContextHash, &terms.R.X, position, btcec.NewPublicKey(&A.X, &A.Y))
+
+	return terms, true
+}
+
+// verify all secret proofs at once with a random linear combination
+// g^{\sum a_i * \mu_i} = \sum_{i} a_i * R_i + (a_i * c_i) * A_i0, a_i is random
+// the right hand side is a single multi scalar multiplication over 2n points instead of 2n scalar multiplications
+//
+// when the batch fails, each proof is verified on its own to find the failing indices
+func (p *FrostParticipant) VerifyBatchSecretProofs(proofs map[int64]*SecretProof, commitments map[int64]*btcec.JacobianPoint) (bool, []int64) {
+	positions := make([]int64, 0, len(proofs))
+	for posi := range proofs {
+		positions = append(positions, posi)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+
+	failed := make([]int64, 0)
+	all_terms := make(map[int64]*secretProofTerms)
+	for _, posi := range positions {
+		commitment, ok := commitments[posi]
+		if !ok {
+			failed = append(failed, posi)
+			continue
+		}
+		terms, ok := p.parseSecretProof(posi, proofs[posi], commitment)
+		if !ok {
+			failed = append(failed, posi)
+			continue
+		}
+		all_terms[posi] = terms
+	}
+
+	lhs_scalar := new(btcec.ModNScalar)
+	scalars := make([]*btcec.ModNScalar, 0, 2*len(all_terms))
+	points := make([]*btcec.JacobianPoint, 0, 2*len(all_terms))
+	for _, posi := range positions {
+		terms, ok := all_terms[posi]
+		if !ok {
+			continue
+		}
+
+		seed := p.suite.Generate32BSeed()
+		a := new(btcec.ModNScalar)
+		a.SetBytes(&seed)
+
+		// a_i * \mu_i
+		lhs_scalar.Add(new(btcec.ModNScalar).Mul2(a, terms.mu))
+		// a_i * R_i + (a_i * c_i) * A_i0
+		scalars = append(scalars, a, new(btcec.ModNScalar).Mul2(a, terms.c))
+		points = append(points, terms.R, terms.A)
+	}
+	lhs := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(lhs_scalar, lhs)
+	rhs := new(btcec.JacobianPoint)
+	msm_err := p.suite.multiScalarMul(scalars, points, rhs)
+
+	if len(failed) == 0 && msm_err == nil && equalPoints(lhs, rhs) {
+		return true, nil
+	}
+
+	// find the failing indices
+	for _, posi := range positions {
+		terms, ok := all_terms[posi]
+		if !ok {
+			continue
+		}
+
+		lhs := new(btcec.JacobianPoint)
+		p.suite.scalarBaseMult(terms.mu, lhs)
+		rhs := new(btcec.JacobianPoint)
+		p.suite.scalarMult(terms.c, terms.A, rhs)
+		p.suite.addPoints(rhs, terms.R, rhs)
+		if !equalPoints(lhs, rhs) {
+			failed = append(failed, posi)
+		}
+	}
+	sort.Slice(failed, func(i, j int) bool { return failed[i] < failed[j] })
+
+	return false, failed
+}
+
+func equalPoints(a, b *btcec.JacobianPoint) bool {
+	a_affine := new(btcec.JacobianPoint)
+	a_affine.Set(a)
+	a_affine.ToAffine()
+	b_affine := new(btcec.JacobianPoint)
+	b_affine.Set(b)
+	b_affine.ToAffine()
+
+	return a_affine.X.Equals(&b_affine.X) && a_affine.Y.Equals(&b_affine.Y)
+}
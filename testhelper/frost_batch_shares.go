@@ -0,0 +1,57 @@
+package testhelper
+
+import (
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// BatchVerifyStrategy selects how VerifyBatchPublicSecretShares checks the shares of a batch
+type BatchVerifyStrategy int
+
+const (
+	// C_j = \prod_{k} A_jk^i^k per dealer, then a weighted check over the C_j, failing subsets are bisected
+	BatchVerifyPerDealer BatchVerifyStrategy = iota
+	// a single multi scalar multiplication over every A_jk of the batch, see verifySharesSingleMSM
+	// a failing batch falls back to BatchVerifyPerDealer to blame the dealers
+	BatchVerifyMSM
+)
+
+func (p *FrostParticipant) SetBatchVerifyStrategy(strategy BatchVerifyStrategy) {
+	p.batch_verify_strategy = strategy
+}
+
+// g^(\sum_{j} a_j * s_ji) = \prod_{j} \prod_{k} A_jk^(a_j * i^k)
+// the per dealer evaluations C_j are folded into a single multi scalar multiplication of n_p * (t + 1) terms
+//
+// the weights a_j are random and unknown to the dealers, thus a batch with an invalid share s_ji
+// only passes when the dealers guess a_j, with probability 1 / |n|
+func (p *FrostParticipant) verifySharesSingleMSM(secret_shares map[int64]*btcec.ModNScalar, dealers []int64, i_power_arr []*btcec.ModNScalar) bool {
+	scalars := make([]*btcec.ModNScalar, 0, len(dealers)*len(i_power_arr))
+	points := make([]*btcec.JacobianPoint, 0, len(dealers)*len(i_power_arr))
+	lhs_scalar := new(btcec.ModNScalar)
+	for _, dealer := range dealers {
+		seed := p.suite.Generate32BSeed()
+		a_j := new(btcec.ModNScalar)
+		a_j.SetBytes(&seed)
+		lhs_scalar.Add(new(btcec.ModNScalar).Mul2(a_j, secret_shares[dealer]))
+
+		poly_commitments, _ := p.GetPolynomialCommitments(dealer)
+		if len(poly_commitments) != len(i_power_arr) {
+			return false
+		}
+		for k := range poly_commitments {
+			A_jk := new(btcec.JacobianPoint)
+			poly_commitments[k].AsJacobian(A_jk)
+			scalars = append(scalars, new(btcec.ModNScalar).Mul2(a_j, i_power_arr[k]))
+			points = append(points, A_jk)
+		}
+	}
+
+	rhs := new(btcec.JacobianPoint)
+	if err := p.suite.multiScalarMul(scalars, points, rhs); err != nil {
+		return false
+	}
+	lhs := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(lhs_scalar, lhs)
+
+	return equalPoints(lhs, rhs)
+}
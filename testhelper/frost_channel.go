@@ -0,0 +1,118 @@
+package testhelper
+
+import (
+	"bytes"
+	"errors"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var (
+	ErrNotChannelCommitment  = errors.New("sign channel commitment: commitment must spend the single funding input")
+	ErrInvalidChannelWitness = errors.New("sign channel commitment: funding witness must end with the funding script and control block")
+	ErrFundingScriptMismatch = errors.New("sign channel commitment: funding script is not committed by the funding output or does not require the group")
+)
+
+// <Y> OP_CHECKSIGVERIFY <P_c> OP_CHECKSIG, the 2 - of - 2 between the group and the channel counterparty
+// the counterparty is not a FROST participant, thus both keys are checked separately in a tapscript leaf
+// instead of being aggregated into a single key
+func (p *FrostParticipant) ChannelFundingScript(counterparty *btcec.PublicKey) ([]byte, error) {
+	if p.GroupPublicKey == nil {
+		return nil, ErrMissingGroupKey
+	}
+
+	return txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(p.GroupPublicKey)).
+		AddOp(txscript.OP_CHECKSIGVERIFY).
+		AddData(schnorr.SerializePubKey(counterparty)).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+}
+
+// the funding output commits the funding script as its only leaf under the NUMS internal key
+// the returned witness [script, control block] is the template of the funding input witness,
+// signatures are inserted in front of it
+func (p *FrostParticipant) ChannelFundingOutput(counterparty *btcec.PublicKey, amount int64) (*wire.TxOut, wire.TxWitness, error) {
+	script, err := p.ChannelFundingScript(counterparty)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree := txscript.AssembleTaprootScriptTree(txscript.NewBaseTapLeaf(script))
+	merkle_root := tree.RootNode.TapHash()
+	output_key, err := p.ScriptOnlyTaprootKey(merkle_root[:])
+	if err != nil {
+		return nil, nil, err
+	}
+	pk_script, err := txscript.PayToTaprootScript(output_key)
+	if err != nil {
+		return nil, nil, err
+	}
+	control_block := tree.LeafMerkleProofs[0].ToControlBlock(NUMSInternalKey())
+	control_block_bytes, err := control_block.ToBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return wire.NewTxOut(amount, pk_script), wire.TxWitness{script, control_block_bytes}, nil
+}
+
+// threshold sign the funding input of a channel commitment transaction with the group key
+// the funding input witness must already hold the template from ChannelFundingOutput, optionally preceded by the counterparty signature
+// the group signature is inserted right before the funding script, so that
+// [counterparty signature, group signature, script, control block] is complete
+func (s *TestSuite) SignChannelCommitment(commitTx *wire.MsgTx, fundingOut *wire.TxOut, participants []*FrostParticipant, signers map[int64]bool) error {
+	if len(commitTx.TxIn) != 1 {
+		return ErrNotChannelCommitment
+	}
+	if len(participants) == 0 {
+		return ErrThresholdNotMet
+	}
+	witness := commitTx.TxIn[0].Witness
+	if len(witness) < 2 {
+		return ErrInvalidChannelWitness
+	}
+	script := witness[len(witness)-2]
+	control_block_bytes := witness[len(witness)-1]
+
+	// the leaf must be committed by the funding output and start with the group key check
+	control_block, err := txscript.ParseControlBlock(control_block_bytes)
+	if err != nil {
+		return ErrInvalidChannelWitness
+	}
+	if !txscript.IsPayToTaproot(fundingOut.PkScript) {
+		return ErrFundingScriptMismatch
+	}
+	if err := txscript.VerifyTaprootLeafCommitment(control_block, fundingOut.PkScript[2:], script); err != nil {
+		return ErrFundingScriptMismatch
+	}
+	group_prefix, err := txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(participants[0].GroupPublicKey)).
+		AddOp(txscript.OP_CHECKSIGVERIFY).
+		Script()
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(script, group_prefix) {
+		return ErrFundingScriptMismatch
+	}
+
+	fetcher := txscript.NewCannedPrevOutputFetcher(fundingOut.PkScript, fundingOut.Value)
+	sighash, err := txscript.CalcTapscriptSignaturehash(txscript.NewTxSigHashes(commitTx, fetcher), txscript.SigHashDefault, commitTx, 0, fetcher, txscript.NewBaseTapLeaf(script))
+	if err != nil {
+		return err
+	}
+	sig, err := s.frostSign(participants, signers, ([32]byte)(sighash), nil)
+	if err != nil {
+		return err
+	}
+
+	signed := make(wire.TxWitness, 0, len(witness)+1)
+	signed = append(signed, witness[:len(witness)-2]...)
+	signed = append(signed, sig.Serialize(), script, control_block_bytes)
+	commitTx.TxIn[0].Witness = signed
+
+	return nil
+}
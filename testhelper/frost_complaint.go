@@ -0,0 +1,80 @@
+package testhelper
+
+import (
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// Complaint is published by participant i when the secret share f_j(i) of dealer j
+// does not match the polynomial commitments of j, e.g. as reported by VerifyBatchPublicSecretShares
+type Complaint struct {
+	Accuser int64
+	Accused int64
+}
+
+// Justification is the answer of the accused dealer j, f_j(i) revealed to everyone
+// an honest dealer loses nothing, the accuser already was entitled to the share
+type Justification struct {
+	Complaint
+	Share *btcec.ModNScalar
+}
+
+func (p *FrostParticipant) RaiseComplaint(dealer int64) Complaint {
+	return Complaint{Accuser: p.Position, Accused: dealer}
+}
+
+// the accused dealer reveals f_j(i), the share it dealt to the accuser
+func (p *FrostParticipant) Justify(complaint Complaint) Justification {
+	return Justification{Complaint: complaint, Share: p.GetSecretShares(complaint.Accuser)}
+}
+
+// drop a dealer from the local state, its commitments and its received share
+// the remaining dealers form the qualified set, signing shares and public signing shares are derived from them only
+func (p *FrostParticipant) ExcludeDealer(dealer int64) {
+	p.commitments_mu.Lock()
+	defer p.commitments_mu.Unlock()
+	delete(p.PolynomialCommitments, dealer)
+	delete(p.received_shares, dealer)
+}
+
+// g^f_j(i) = \prod_{k=0}^{t} A_jk^i^k
+func (p *FrostParticipant) verifySecretShare(share *btcec.ModNScalar, dealer, posi int64) bool {
+	commitments, ok := p.GetPolynomialCommitments(dealer)
+	if !ok || share == nil {
+		return false
+	}
+	points := make([]*btcec.JacobianPoint, len(commitments))
+	for k, commitment := range commitments {
+		points[k] = new(btcec.JacobianPoint)
+		commitment.AsJacobian(points[k])
+	}
+	expected := new(btcec.JacobianPoint)
+	if err := p.suite.multiScalarMul(powers(posi, p.Threshold), points, expected); err != nil {
+		return false
+	}
+
+	actual := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(share, actual)
+
+	return equalPoints(actual, expected)
+}
+
+// collect the justification of an accused dealer
+func (c *FrostCoordinator) SubmitJustification(justification Justification) {
+	c.justifications[justification.Complaint] = justification.Share
+}
+
+// a complaint is valid when the accused dealer did not justify, or revealed a share that does not match its commitments
+// the dealer of a valid complaint is disqualified, the DKG continues with the remaining qualified set
+// otherwise the accuser takes the revealed share and the dealer stays qualified
+func (c *FrostCoordinator) ResolveComplaint(complaint Complaint) (valid bool) {
+	if c.disqualified[complaint.Accused] {
+		return true
+	}
+	share, ok := c.justifications[complaint]
+	if ok && c.Frost.verifySecretShare(share, complaint.Accused, complaint.Accuser) {
+		return false
+	}
+	c.Disqualify(complaint.Accused)
+
+	return true
+}
@@ -0,0 +1,110 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+var (
+	ErrConcurrentDKGFailed     = errors.New("concurrent dkg: participant reported a failure")
+	ErrConcurrentDKGDisagreed  = errors.New("concurrent dkg: participants derived different outputs")
+	ErrInvalidConcurrentParams = errors.New("concurrent dkg: invalid participant count or threshold")
+)
+
+// collects assertion failures of all participants goroutines
+type dkgFailureCollector struct {
+	mu       sync.Mutex
+	failures []string
+}
+
+func (c *dkgFailureCollector) Errorf(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures = append(c.failures, fmt.Sprintf(format, args...))
+}
+
+// run a DKG where each participant is a goroutine exchanging messages over a ChannelTransport
+// every participant owns its state, nothing is shared except the immutable messages
+//
+// the DKG result of participant 1 is returned once all participants agree on it
+func RunDKGConcurrent(n, threshold int64) (*DKGResult, error) {
+	if n <= 0 || threshold < 0 || threshold >= n {
+		return nil, ErrInvalidConcurrentParams
+	}
+
+	collector := &dkgFailureCollector{}
+	suite := &TestSuite{
+		T:      collector,
+		Logger: log.Default(),
+	}
+
+	transports := NewChannelTransports(n)
+	results := make([]*DKGResult, n)
+	var wg sync.WaitGroup
+	for i := int64(0); i < n; i++ {
+		wg.Add(1)
+		go func(posi int64) {
+			defer wg.Done()
+			participant := NewFrostParticipant(suite, suite.Logger, n, threshold, posi, nil)
+			if err := participant.RunDKG(transports[posi-1], [32]byte{}); err != nil {
+				suite.T.Errorf("participant %d: %v", posi, err)
+				return
+			}
+			result, err := participant.DKGResult()
+			if err != nil {
+				suite.T.Errorf("participant %d: %v", posi, err)
+				return
+			}
+			results[posi-1] = result
+		}(i + 1)
+	}
+	wg.Wait()
+
+	if len(collector.failures) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrConcurrentDKGFailed, strings.Join(collector.failures, "; "))
+	}
+	for posi := int64(2); posi <= n; posi++ {
+		if !DKGOutputsEquivalent(results[0], results[posi-1]) {
+			return nil, fmt.Errorf("%w: participant %d", ErrConcurrentDKGDisagreed, posi)
+		}
+	}
+
+	return results[0], nil
+}
+
+// construct n participants, then store the commitments of every dealer at every participant
+// the setup shared by the FROST and WSTS benchmarks, both steps run a goroutine per participant
+// the commitment lists are shared read - only between participants, as UpdateAllPolynomialCommitments does not copy them
+func SetupAndExchangeCommitments(suite *TestSuite, logger *log.Logger, n, threshold int64) []*FrostParticipant {
+	participants := make([]*FrostParticipant, n)
+	var wg sync.WaitGroup
+	for i := int64(0); i < n; i++ {
+		wg.Add(1)
+		go func(i int64) {
+			defer wg.Done()
+			participants[i] = NewFrostParticipant(suite, logger, n, threshold, i+1, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	all_commitments := make(map[int64][]*btcec.PublicKey, n)
+	for i := int64(0); i < n; i++ {
+		all_commitments[i+1] = participants[i].PolynomialCommitments[i+1]
+	}
+	for i := int64(0); i < n; i++ {
+		wg.Add(1)
+		go func(participant *FrostParticipant) {
+			defer wg.Done()
+			participant.UpdateAllPolynomialCommitments(all_commitments)
+		}(participants[i])
+	}
+	wg.Wait()
+
+	return participants
+}
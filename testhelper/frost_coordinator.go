@@ -0,0 +1,324 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	TagFROSTDKGCommitments = []byte("FROST/dkg-commitments")
+	TagFROSTDKGCertificate = []byte("FROST/dkg-certificate")
+	TagFROSTConfig         = []byte("FROST/config")
+
+	ErrInvalidDKGCertificate        = errors.New("verify dkg certificate: invalid certificate")
+	ErrInvalidDKGParameters         = errors.New("dkg dry run: invalid parameters")
+	ErrInsufficientQualifiedDealers = errors.New("dkg coordinator: qualified set below threshold")
+)
+
+// secp256k1 group, SHA256 hashes, BIP340 challenges for taproot
+const FrostCiphersuite = "FROST-secp256k1-SHA256-TR-v1"
+
+// rough cost model of elliptic curve operations for planning
+// measured on a single core, a real run should be used to calibrate
+const (
+	dryRunScalarMultCost = 60 * time.Microsecond
+	dryRunPointAddCost   = 2 * time.Microsecond
+	// in - memory size of a jacobian point and a scalar
+	dryRunPointSize  = 120
+	dryRunScalarSize = 32
+)
+
+// DKGPlan is the planned cost of a DKG, messages are point - to - point deliveries between distinct participants
+type DKGPlan struct {
+	N         int64
+	Threshold int64
+
+	// each participant sends its t + 1 polynomial commitments to n - 1 others
+	CommitmentMessages int64
+	// each participant sends its secret proof to n - 1 others
+	ProofMessages int64
+	// each participant sends f_i(j) to n - 1 others
+	ShareMessages int64
+	TotalMessages int64
+	// serialized bytes over all deliveries
+	BandwidthBytes int64
+
+	// memory held by a single participant
+	MemoryBytes int64
+	// time for a single participant, participants run in parallel
+	EstimatedTime time.Duration
+}
+
+// FrostCoordinator is the DKG coordinator role
+// after the DKG, it publishes a compact certificate that the group was formed correctly
+//
+// certificate = Y || |Q| || Q || H(commitments) || sig
+// Y is the x - only group public key, Q is the qualified set
+// sig is a threshold signature of the group over H(Y || Q || H(commitments))
+type FrostCoordinator struct {
+	suite *TestSuite
+
+	Frost      *FrostParticipant
+	Aggregator *FrostAggregator
+	// signing index used by signers for the certificate signing
+	CertificateSigningIndex int64
+	// positions of the participants in the group, 1 to n by default
+	Positions []int64
+
+	certificate_sigs map[int64]*schnorr.Signature
+	disqualified     map[int64]bool
+	justifications   map[Complaint]*btcec.ModNScalar
+}
+
+func NewFrostCoordinator(suite *TestSuite, frost *FrostParticipant) *FrostCoordinator {
+	coordinator := &FrostCoordinator{
+		suite:            suite,
+		Frost:            frost,
+		Aggregator:       NewFrostAggregator(suite, frost),
+		certificate_sigs: make(map[int64]*schnorr.Signature),
+		disqualified:     make(map[int64]bool),
+		justifications:   make(map[Complaint]*btcec.ModNScalar),
+		Positions:        make([]int64, 0, frost.N),
+	}
+	for posi := int64(1); posi <= frost.N; posi++ {
+		coordinator.Positions = append(coordinator.Positions, posi)
+	}
+
+	return coordinator
+}
+
+// H(n || t || ciphersuite || sorted positions)
+// all nodes must agree on the group configuration before starting the DKG
+func (c *FrostCoordinator) ConfigHash() [32]byte {
+	positions := make([]int64, len(c.Positions))
+	copy(positions, c.Positions)
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+
+	config_data := make([]byte, 0)
+	config_data = append(config_data, Int64ToBytes(c.Frost.N)...)
+	config_data = append(config_data, Int64ToBytes(c.Frost.Threshold)...)
+	config_data = append(config_data, Int64ToBytes(int64(len(FrostCiphersuite)))...)
+	config_data = append(config_data, []byte(FrostCiphersuite)...)
+	for _, posi := range positions {
+		config_data = append(config_data, Int64ToBytes(posi)...)
+	}
+
+	return *chainhash.TaggedHash(TagFROSTConfig, config_data)
+}
+
+// H(commitments) = H(i || A_i0 || ... || A_it), i \in Q
+func (c *FrostCoordinator) CommitmentsHash() [32]byte {
+	commitments_data := make([]byte, 0)
+	for _, posi := range c.Frost.QualifiedSet() {
+		commitments_data = append(commitments_data, Int64ToBytes(posi)...)
+		commitments, _ := c.Frost.GetPolynomialCommitments(posi)
+		for _, commitment := range commitments {
+			commitments_data = append(commitments_data, commitment.SerializeCompressed()...)
+		}
+	}
+
+	return *chainhash.TaggedHash(TagFROSTDKGCommitments, commitments_data)
+}
+
+// the message signed by a threshold of participants
+func (c *FrostCoordinator) CertificateMessage() [32]byte {
+	assert.NotNil(c.suite.T, c.Frost.GroupPublicKey, "dkg certificate: group public key not calculated")
+
+	commitments_hash := c.CommitmentsHash()
+	return dkgCertificateMessage(schnorr.SerializePubKey(c.Frost.GroupPublicKey), c.Frost.QualifiedSet(), commitments_hash[:])
+}
+
+// collect a partial signature of participant posi over the certificate message
+func (c *FrostCoordinator) SubmitCertificateSignature(posi int64, partial_sig *schnorr.Signature) {
+	c.certificate_sigs[posi] = partial_sig
+}
+
+// exclude a dealer from the qualified set, e.g. after an invalid secret proof or missing shares
+// its commitments are dropped, thus it contributes neither to the group public key nor to the certificate
+func (c *FrostCoordinator) Disqualify(dealer int64) {
+	c.disqualified[dealer] = true
+	c.Frost.ExcludeDealer(dealer)
+}
+
+// dealers excluded so far, in ascending order of position
+func (c *FrostCoordinator) Disqualified() []int64 {
+	disqualified := make([]int64, 0, len(c.disqualified))
+	for dealer := range c.disqualified {
+		disqualified = append(disqualified, dealer)
+	}
+	sort.Slice(disqualified, func(i, j int) bool { return disqualified[i] < disqualified[j] })
+
+	return disqualified
+}
+
+// verify the secret proof of every dealer with stored commitments
+// dealers with a missing or invalid proof are disqualified, then the qualified set is checked
+func (c *FrostCoordinator) QualifyDealers(context_hash [32]byte, proofs map[int64]*schnorr.Signature) error {
+	for _, dealer := range c.Frost.QualifiedSet() {
+		proof, ok := proofs[dealer]
+		if !ok {
+			c.Disqualify(dealer)
+			continue
+		}
+		commitments, _ := c.Frost.GetPolynomialCommitments(dealer)
+		if err := c.Frost.VerifySecretProofs(context_hash, proof, dealer, commitments[0]); err != nil {
+			c.Disqualify(dealer)
+		}
+	}
+
+	return c.CheckQualifiedSet()
+}
+
+// the DKG must abort when fewer than threshold + 1 dealers remain qualified
+// threshold colluding dealers would otherwise know every contribution to the group secret
+func (c *FrostCoordinator) CheckQualifiedSet() error {
+	qualified := int64(len(c.Frost.QualifiedSet()))
+	if qualified <= c.Frost.Threshold {
+		return fmt.Errorf("%w: %d qualified dealers, %d disqualified, threshold %d", ErrInsufficientQualifiedDealers, qualified, len(c.disqualified), c.Frost.Threshold)
+	}
+
+	return nil
+}
+
+func (c *FrostCoordinator) GenerateDKGCertificate() ([]byte, error) {
+	if err := c.CheckQualifiedSet(); err != nil {
+		return nil, err
+	}
+	sig, err := c.Aggregator.AggregateStrict(c.CertificateSigningIndex, c.certificate_sigs)
+	if err != nil {
+		return nil, err
+	}
+
+	qualified_set := c.Frost.QualifiedSet()
+	commitments_hash := c.CommitmentsHash()
+
+	certificate := make([]byte, 0)
+	certificate = append(certificate, schnorr.SerializePubKey(c.Frost.GroupPublicKey)...)
+	certificate = append(certificate, Int64ToBytes(int64(len(qualified_set)))...)
+	for _, posi := range qualified_set {
+		certificate = append(certificate, Int64ToBytes(posi)...)
+	}
+	certificate = append(certificate, commitments_hash[:]...)
+	certificate = append(certificate, sig.Serialize()...)
+
+	return certificate, nil
+}
+
+// anyone can verify the certificate signature against the group public key in the certificate
+// verifiers holding the commitments can also compare H(commitments)
+func VerifyDKGCertificate(certificate []byte) error {
+	if len(certificate) < 32+8 {
+		return ErrInvalidDKGCertificate
+	}
+	group_key_bytes := certificate[0:32]
+	count := BytesToInt64(certificate[32:40])
+	if count < 0 || count > int64(len(certificate))/8 || int64(len(certificate)) != 32+8+count*8+32+64 {
+		return ErrInvalidDKGCertificate
+	}
+
+	offset := int64(40)
+	qualified_set := make([]int64, count)
+	for i := int64(0); i < count; i++ {
+		qualified_set[i] = BytesToInt64(certificate[offset : offset+8])
+		offset += 8
+	}
+	commitments_hash := certificate[offset : offset+32]
+	offset += 32
+
+	group_key, err := schnorr.ParsePubKey(group_key_bytes)
+	if err != nil {
+		return ErrInvalidDKGCertificate
+	}
+	sig, err := schnorr.ParseSignature(certificate[offset:])
+	if err != nil {
+		return ErrInvalidDKGCertificate
+	}
+
+	message := dkgCertificateMessage(group_key_bytes, qualified_set, commitments_hash)
+	if !sig.Verify(message[:], group_key) {
+		return ErrInvalidDKGCertificate
+	}
+
+	return nil
+}
+
+// H(Y || Q || H(commitments))
+func dkgCertificateMessage(group_key_bytes []byte, qualified_set []int64, commitments_hash []byte) [32]byte {
+	message_data := make([]byte, 0)
+	message_data = append(message_data, group_key_bytes...)
+	for _, posi := range qualified_set {
+		message_data = append(message_data, Int64ToBytes(posi)...)
+	}
+	message_data = append(message_data, commitments_hash...)
+
+	return *chainhash.TaggedHash(TagFROSTDKGCertificate, message_data)
+}
+
+// validate a configuration and plan its cost without performing any elliptic curve operation
+func (c *FrostCoordinator) DryRun(n, threshold int64) (*DKGPlan, error) {
+	// a quorum of threshold + 1 participants must exist
+	if n < 2 || threshold < 1 || threshold >= n {
+		return nil, ErrInvalidDKGParameters
+	}
+
+	deliveries := n * (n - 1)
+	plan := &DKGPlan{
+		N:                  n,
+		Threshold:          threshold,
+		CommitmentMessages: deliveries,
+		ProofMessages:      deliveries,
+		ShareMessages:      deliveries,
+	}
+	plan.TotalMessages = plan.CommitmentMessages + plan.ProofMessages + plan.ShareMessages
+	// compressed points, 64 bytes schnorr proofs, 32 bytes scalars
+	plan.BandwidthBytes = deliveries * ((threshold+1)*33 + 64 + 32)
+
+	// commitments of all participants, Q and W maps, secret shares
+	plan.MemoryBytes = n*(threshold+1)*dryRunPointSize*3 + n*dryRunScalarSize
+
+	// commitments: t + 1, secret proofs: 1 + 2 * n, secret shares verification: n
+	// public signing shares: n * (t + 1) scalar multiplications in W map
+	scalar_mults := (threshold + 1) + 1 + 2*n + n + n*(threshold+1)
+	// Q map and public signing shares: 2 * n * (t + 1) point additions
+	point_adds := 2 * n * (threshold + 1)
+	plan.EstimatedTime = time.Duration(scalar_mults)*dryRunScalarMultCost + time.Duration(point_adds)*dryRunPointAddCost
+
+	return plan, nil
+}
+
+// SecurityLevel is the effective security of a (n, threshold) configuration
+// the secret polynomial has degree threshold, thus threshold + 1 participants are needed to sign
+type SecurityLevel struct {
+	N         int64
+	Threshold int64
+
+	// participants that must collude to forge a signature or recover the group secret
+	CollusionToForge int64
+	// participants that can be offline while the rest can still sign
+	MaxOffline int64
+	// a forging coalition must be a strict majority of participants
+	MajorityHonest bool
+}
+
+func SecuritySummary(n, threshold int64) SecurityLevel {
+	quorum := threshold + 1
+	summary := SecurityLevel{
+		N:                n,
+		Threshold:        threshold,
+		CollusionToForge: quorum,
+		MaxOffline:       n - quorum,
+		MajorityHonest:   2*quorum > n,
+	}
+	if summary.MaxOffline < 0 {
+		summary.MaxOffline = 0
+	}
+
+	return summary
+}
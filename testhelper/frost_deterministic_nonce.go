@@ -0,0 +1,61 @@
+package testhelper
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	TagFROSTDeterministicNonce = []byte("FROST/deterministic-nonce")
+)
+
+// derive the nonce pair of a new signing index from s_i, the message and a counter, in the spirit of RFC6979
+// d = H(s_i || m || counter || 0 || extra), e = H(s_i || m || counter || 1 || extra), H is tagged with TagFROSTDeterministicNonce
+// extra is optional auxiliary data, e.g. a session id or fresh randomness
+// the counter starts at 0 and is incremented on each call, thus a participant restored with the same share reproduces the same sequence
+//
+// the nonce pair is bound to msg like PreprocessNoncesForSighash, it must NOT be reused across different messages
+// even for the same message, it must not sign twice with different signer sets or commitment lists,
+// the binding factors change and the two partial signatures leak s_i
+// checkNonceFreshness refuses such a replay within a participant, but not after a restore from backup,
+// the counter then starts again at 0, thus a restored participant must pass fresh extra data, e.g. random bytes
+func (p *FrostParticipant) GenerateDeterministicNonces(msg [32]byte, extra []byte) (int64, [2]*btcec.PublicKey, error) {
+	s_i := p.GetSigningShares()
+	if s_i == nil {
+		return -1, [2]*btcec.PublicKey{}, fmt.Errorf("generate deterministic nonces: %w", ErrMissingSigningShare)
+	}
+	counter := make([]byte, 8)
+	p.sessions_mu.Lock()
+	binary.BigEndian.PutUint64(counter, p.deterministic_nonce_counter)
+	p.deterministic_nonce_counter++
+	p.sessions_mu.Unlock()
+
+	share_bytes := s_i.Bytes()
+	var nonces [2]*btcec.ModNScalar
+	var nonce_commitments [2]*btcec.PublicKey
+	for k := range nonces {
+		nonce_hash := chainhash.TaggedHash(TagFROSTDeterministicNonce, share_bytes[:], msg[:], counter, []byte{byte(k)}, extra)
+		nonces[k] = new(btcec.ModNScalar)
+		nonces[k].SetBytes((*[32]byte)(nonce_hash))
+
+		commitment := new(btcec.JacobianPoint)
+		p.suite.scalarBaseMult(nonces[k], commitment)
+		commitment.ToAffine()
+		nonce_commitments[k] = btcec.NewPublicKey(&commitment.X, &commitment.Y)
+	}
+
+	p.sessions_mu.Lock()
+	defer p.sessions_mu.Unlock()
+	p.nonces = append(p.nonces, nonces)
+	p.NonceCommitments = append(p.NonceCommitments, nonce_commitments)
+	signing_index := int64(len(p.nonces) - 1)
+	if p.nonce_bindings == nil {
+		p.nonce_bindings = make(map[int64][32]byte)
+	}
+	p.nonce_bindings[signing_index] = msg
+
+	return signing_index, nonce_commitments, nil
+}
@@ -0,0 +1,66 @@
+package testhelper
+
+import (
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// a participant can split its signing share s_i across two devices with a 2-of-2 additive sharing
+// s_i = s_a + s_b, s_a is random
+//
+// neither device alone learns s_i
+func (p *FrostParticipant) SplitShareForDevices() (shareA, shareB *btcec.ModNScalar) {
+	assert.NotNil(p.suite.T, p.signingShares, "split share for devices: signing shares not stored")
+
+	seed := p.suite.Generate32BSeed()
+	shareA = new(btcec.ModNScalar)
+	shareA.SetBytes(&seed)
+
+	// s_b = s_i - s_a
+	shareB = new(btcec.ModNScalar).Set(shareA)
+	shareB.Negate().Add(p.signingShares)
+
+	return shareA, shareB
+}
+
+// each device contributes \lambda_i * s_d * c for its half s_d
+// c = H(R, Y, m)
+func (p *FrostParticipant) DeviceSignContribution(position, signing_index int64, honest_party []int64, message_hash [32]byte, share_half *btcec.ModNScalar) (*btcec.ModNScalar, error) {
+	R, ok := p.aggrNonceCommitment(signing_index)
+	if !ok || R == nil {
+		return nil, fmt.Errorf("%w: device sign contribution, signing index %d", ErrMissingAggrNonce, signing_index)
+	}
+
+	// calculate c
+	c := bip340Challenge(R, p.GroupPublicKey, message_hash)
+
+	s_d := new(btcec.ModNScalar).Set(share_half)
+	if p.GroupPublicKey.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd {
+		s_d.Negate()
+	}
+
+	lamba := p.suite.CalculateLagrangeCoeff(position, honest_party)
+	return new(btcec.ModNScalar).Mul2(lamba, s_d).Mul(c), nil
+}
+
+// combine device contributions into a partial signature
+// z_i = d_i + e_i * p_i + \lambda_i * s_a * c + \lambda_i * s_b * c
+//
+// the nonce part d_i + e_i * p_i is the partial signature with a zero signing share
+func (p *FrostParticipant) PartialSignFromHalves(position, signing_index int64, honest_party []int64, message_hash [32]byte, public_nonces map[int64][2]*btcec.PublicKey, contributionA, contributionB *btcec.ModNScalar) *schnorr.Signature {
+	nonce_sig := p.PartialSign(position, signing_index, honest_party, message_hash, public_nonces, new(btcec.ModNScalar))
+	nonce_sig_bytes := nonce_sig.Serialize()
+
+	z_i := new(btcec.ModNScalar)
+	z_i.SetByteSlice(nonce_sig_bytes[32:64])
+	z_i.Add(contributionA).Add(contributionB)
+
+	R_i_x := new(btcec.FieldVal)
+	R_i_x.SetByteSlice(nonce_sig_bytes[0:32])
+
+	return schnorr.NewSignature(R_i_x, z_i)
+}
@@ -0,0 +1,348 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const encryptedShareBits = 256
+
+var (
+	TagFROSTEncryptedShareBit = []byte("FROST/encrypted-share-bit")
+	TagFROSTEncryptedShareSum = []byte("FROST/encrypted-share-sum")
+
+	ErrInvalidEncryptedShare  = errors.New("verify encrypted share: invalid ciphertext or proof")
+	ErrEncryptedShareMismatch = errors.New("decrypt share: decrypted share does not match the dealer commitments")
+)
+
+// in an optimistic DKG, dealt shares are published encrypted to recipients and anyone checks them against the commitments
+// s = f_i(j) = \sum_{k} b_k * 2^k is encrypted bit by bit to recipient j with encryption key X_j = x_j * G
+// C1_k = r_k * G
+// C2_k = b_k * G + r_k * X_j
+//
+// each bit carries an OR proof that (C1_k, C2_k) encrypts 0 or 1
+// the sum proof shows log_G(\sum_{k} 2^k * C1_k) = log_{X_j}(\sum_{k} 2^k * C2_k - S_j), S_j = \sum_{k} A_k * j^k
+// thus the bits encrypt s with s * G = S_j, and the recipient decrypts each bit from C2_k - x_j * C1_k, the point at infinity or G
+type EncryptedShare struct {
+	Recipient int64
+	C1        []*btcec.PublicKey
+	C2        []*btcec.PublicKey
+	BitProofs []*EncryptedBitProof
+	SumProof  *DLEQProof
+}
+
+// Chaum - Pedersen proof of log_G(A) = log_X(B) = r
+// T_1 = k * G, T_2 = k * X, c = H(T_1, T_2, ...), z = k + c * r
+// the verifier recomputes T_1 = z * G - c * A, T_2 = z * X - c * B
+type DLEQProof struct {
+	Challenge *btcec.ModNScalar
+	Response  *btcec.ModNScalar
+}
+
+// disjunction of two Chaum - Pedersen proofs, log_G(C1) = log_X(C2) or log_G(C1) = log_X(C2 - G)
+// the branch of the other bit is simulated, its challenge is fixed before c, thus c_0 + c_1 = c
+type EncryptedBitProof struct {
+	Challenges [2]*btcec.ModNScalar
+	Responses  [2]*btcec.ModNScalar
+}
+
+// deal f_i(j) to all recipients j, encrypted under their encryption keys
+func (p *FrostParticipant) DealVerifiablyEncrypted(encryption_keys map[int64]*btcec.PublicKey) (map[int64]*EncryptedShare, error) {
+	encrypted_shares := make(map[int64]*EncryptedShare)
+	for posi, encryption_key := range encryption_keys {
+		if err := p.validateIndex(posi); err != nil {
+			return nil, err
+		}
+		posi_scalar := new(btcec.ModNScalar).SetInt(uint32(posi))
+		share := p.suite.EvaluatePolynomial(p.secretPolynomial, posi_scalar)
+
+		encrypted_shares[posi] = p.encryptShare(posi, share, encryption_key)
+	}
+
+	return encrypted_shares, nil
+}
+
+func (p *FrostParticipant) encryptShare(posi int64, share *btcec.ModNScalar, encryption_key *btcec.PublicKey) *EncryptedShare {
+	X := new(btcec.JacobianPoint)
+	encryption_key.AsJacobian(X)
+	context := encryptedShareContext(p.Position, posi, encryption_key)
+
+	encrypted_share := &EncryptedShare{
+		Recipient: posi,
+		C1:        make([]*btcec.PublicKey, encryptedShareBits),
+		C2:        make([]*btcec.PublicKey, encryptedShareBits),
+		BitProofs: make([]*EncryptedBitProof, encryptedShareBits),
+	}
+	// R = \sum_{k} 2^k * r_k
+	R := new(btcec.ModNScalar)
+	weight := new(btcec.ModNScalar).SetInt(1)
+	share_bytes := share.Bytes()
+	for k := 0; k < encryptedShareBits; k++ {
+		bit := int(share_bytes[31-k/8]>>(k%8)) & 1
+		r := p.suite.randomScalar()
+
+		// C1_k = r_k * G, C2_k = b_k * G + r_k * X_j
+		C_1 := new(btcec.JacobianPoint)
+		p.suite.scalarBaseMult(r, C_1)
+		C_2 := new(btcec.JacobianPoint)
+		p.suite.scalarMult(r, X, C_2)
+		if bit == 1 {
+			G := new(btcec.JacobianPoint)
+			p.suite.scalarBaseMult(new(btcec.ModNScalar).SetInt(1), G)
+			p.suite.addPoints(C_2, G, C_2)
+		}
+		encrypted_share.C1[k] = jacobianToPublicKey(C_1)
+		encrypted_share.C2[k] = jacobianToPublicKey(C_2)
+		encrypted_share.BitProofs[k] = p.proveEncryptedBit(bitContext(context, k), bit, r, X, C_1, C_2)
+
+		R.Add(new(btcec.ModNScalar).Mul2(weight, r))
+		weight.Add(weight)
+	}
+
+	// A = R * G, B = R * X_j
+	A := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(R, A)
+	B := new(btcec.JacobianPoint)
+	p.suite.scalarMult(R, X, B)
+	encrypted_share.SumProof = p.proveDLEQ(context, R, X, A, B)
+
+	return encrypted_share
+}
+
+// anyone holding the commitments of the dealer checks an encrypted share without decrypting it
+// every bit proof and the sum proof against S_j must hold
+func (p *FrostParticipant) VerifyEncryptedShare(dealer int64, encrypted_share *EncryptedShare, encryption_key *btcec.PublicKey) error {
+	if err := p.validateIndex(encrypted_share.Recipient); err != nil {
+		return fmt.Errorf("%w: recipient: %w", ErrInvalidEncryptedShare, err)
+	}
+	if len(encrypted_share.C1) != encryptedShareBits || len(encrypted_share.C2) != encryptedShareBits ||
+		len(encrypted_share.BitProofs) != encryptedShareBits || encrypted_share.SumProof == nil {
+		return fmt.Errorf("%w: expected %d encrypted bits", ErrInvalidEncryptedShare, encryptedShareBits)
+	}
+	commitments, ok := p.GetPolynomialCommitments(dealer)
+	if !ok {
+		return fmt.Errorf("%w: no commitments of dealer %d", ErrInvalidEncryptedShare, dealer)
+	}
+	X := new(btcec.JacobianPoint)
+	encryption_key.AsJacobian(X)
+	context := encryptedShareContext(dealer, encrypted_share.Recipient, encryption_key)
+
+	weights := make([]*btcec.ModNScalar, encryptedShareBits)
+	C1_points := make([]*btcec.JacobianPoint, encryptedShareBits)
+	C2_points := make([]*btcec.JacobianPoint, encryptedShareBits)
+	weight := new(btcec.ModNScalar).SetInt(1)
+	for k := 0; k < encryptedShareBits; k++ {
+		if encrypted_share.C1[k] == nil || encrypted_share.C2[k] == nil || encrypted_share.BitProofs[k] == nil {
+			return fmt.Errorf("%w: bit %d", ErrInvalidEncryptedShare, k)
+		}
+		C1_points[k] = new(btcec.JacobianPoint)
+		encrypted_share.C1[k].AsJacobian(C1_points[k])
+		C2_points[k] = new(btcec.JacobianPoint)
+		encrypted_share.C2[k].AsJacobian(C2_points[k])
+		if !p.verifyEncryptedBit(bitContext(context, k), encrypted_share.BitProofs[k], X, C1_points[k], C2_points[k]) {
+			return fmt.Errorf("%w: bit %d", ErrInvalidEncryptedShare, k)
+		}
+		weights[k] = new(btcec.ModNScalar).Set(weight)
+		weight.Add(weight)
+	}
+
+	// A = \sum_{k} 2^k * C1_k, B = \sum_{k} 2^k * C2_k - S_j
+	A := new(btcec.JacobianPoint)
+	if err := p.suite.multiScalarMul(weights, C1_points, A); err != nil {
+		return err
+	}
+	B := new(btcec.JacobianPoint)
+	if err := p.suite.multiScalarMul(weights, C2_points, B); err != nil {
+		return err
+	}
+	commitment_points := make([]*btcec.JacobianPoint, len(commitments))
+	for k, commitment := range commitments {
+		commitment_points[k] = new(btcec.JacobianPoint)
+		commitment.AsJacobian(commitment_points[k])
+	}
+	S_j := new(btcec.JacobianPoint)
+	if err := p.suite.multiScalarMul(powers(encrypted_share.Recipient, int64(len(commitments)-1)), commitment_points, S_j); err != nil {
+		return err
+	}
+	p.suite.addPoints(B, negatePoint(S_j), B)
+	if !p.verifyDLEQ(context, encrypted_share.SumProof, X, A, B) {
+		return fmt.Errorf("%w: share does not match the commitments of dealer %d", ErrInvalidEncryptedShare, dealer)
+	}
+
+	return nil
+}
+
+// recipient recovers each bit b_k from C2_k - x_j * C1_k and checks s against the polynomial commitments of the dealer
+// a share that passed VerifyEncryptedShare always decrypts, otherwise the recipient raises a complaint
+func (p *FrostParticipant) DecryptShare(dealer int64, encrypted_share *EncryptedShare, encryption_secret *btcec.PrivateKey) (*btcec.ModNScalar, error) {
+	if encrypted_share.Recipient != p.Position {
+		return nil, fmt.Errorf("%w: share encrypted to %d", ErrEncryptedShareMismatch, encrypted_share.Recipient)
+	}
+	if len(encrypted_share.C1) != encryptedShareBits || len(encrypted_share.C2) != encryptedShareBits {
+		return nil, fmt.Errorf("%w: expected %d encrypted bits", ErrEncryptedShareMismatch, encryptedShareBits)
+	}
+
+	G := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(new(btcec.ModNScalar).SetInt(1), G)
+	var share_bytes [32]byte
+	for k := 0; k < encryptedShareBits; k++ {
+		if encrypted_share.C1[k] == nil || encrypted_share.C2[k] == nil {
+			return nil, fmt.Errorf("%w: bit %d", ErrEncryptedShareMismatch, k)
+		}
+		C_1 := new(btcec.JacobianPoint)
+		encrypted_share.C1[k].AsJacobian(C_1)
+		shared_point := new(btcec.JacobianPoint)
+		p.suite.scalarMult(&encryption_secret.Key, C_1, shared_point)
+		M := new(btcec.JacobianPoint)
+		encrypted_share.C2[k].AsJacobian(M)
+		p.suite.addPoints(M, negatePoint(shared_point), M)
+		M.ToAffine()
+
+		switch {
+		case (M.X.IsZero() && M.Y.IsZero()) || M.Z.IsZero():
+		case equalPoints(M, G):
+			share_bytes[31-k/8] |= 1 << (k % 8)
+		default:
+			return nil, fmt.Errorf("%w: bit %d is neither 0 nor 1", ErrEncryptedShareMismatch, k)
+		}
+	}
+
+	share := new(btcec.ModNScalar)
+	share.SetByteSlice(share_bytes[:])
+	if !p.verifySecretShare(share, dealer, p.Position) {
+		return nil, fmt.Errorf("%w: dealer %d", ErrEncryptedShareMismatch, dealer)
+	}
+
+	return share, nil
+}
+
+// the real branch b is proven with k, the other branch is simulated with a random challenge and response
+func (p *FrostParticipant) proveEncryptedBit(context []byte, bit int, r *btcec.ModNScalar, X, C_1, C_2 *btcec.JacobianPoint) *EncryptedBitProof {
+	targets := encryptedBitTargets(p.suite, C_2)
+	proof := &EncryptedBitProof{}
+	commitments := [2][2]*btcec.JacobianPoint{}
+
+	simulated := 1 - bit
+	proof.Challenges[simulated] = p.suite.randomScalar()
+	proof.Responses[simulated] = p.suite.randomScalar()
+	commitments[simulated] = dleqCommitments(p.suite, proof.Challenges[simulated], proof.Responses[simulated], X, C_1, targets[simulated])
+
+	k := p.suite.randomScalar()
+	T_1 := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(k, T_1)
+	T_2 := new(btcec.JacobianPoint)
+	p.suite.scalarMult(k, X, T_2)
+	commitments[bit] = [2]*btcec.JacobianPoint{T_1, T_2}
+
+	// c_b = c - c_{1 - b}, z_b = k + c_b * r
+	c := dleqChallenge(TagFROSTEncryptedShareBit, context, C_1, C_2, commitments[0][0], commitments[0][1], commitments[1][0], commitments[1][1])
+	proof.Challenges[bit] = c.Add(new(btcec.ModNScalar).NegateVal(proof.Challenges[simulated]))
+	proof.Responses[bit] = new(btcec.ModNScalar).Mul2(proof.Challenges[bit], r).Add(k)
+
+	return proof
+}
+
+func (p *FrostParticipant) verifyEncryptedBit(context []byte, proof *EncryptedBitProof, X, C_1, C_2 *btcec.JacobianPoint) bool {
+	for branch := 0; branch < 2; branch++ {
+		if proof.Challenges[branch] == nil || proof.Responses[branch] == nil {
+			return false
+		}
+	}
+	targets := encryptedBitTargets(p.suite, C_2)
+	T_0 := dleqCommitments(p.suite, proof.Challenges[0], proof.Responses[0], X, C_1, targets[0])
+	T_1 := dleqCommitments(p.suite, proof.Challenges[1], proof.Responses[1], X, C_1, targets[1])
+
+	c := dleqChallenge(TagFROSTEncryptedShareBit, context, C_1, C_2, T_0[0], T_0[1], T_1[0], T_1[1])
+
+	return c.Equals(new(btcec.ModNScalar).Add2(proof.Challenges[0], proof.Challenges[1]))
+}
+
+// proof of log_G(A) = log_X(B) = r
+func (p *FrostParticipant) proveDLEQ(context []byte, r *btcec.ModNScalar, X, A, B *btcec.JacobianPoint) *DLEQProof {
+	k := p.suite.randomScalar()
+	T_1 := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(k, T_1)
+	T_2 := new(btcec.JacobianPoint)
+	p.suite.scalarMult(k, X, T_2)
+
+	c := dleqChallenge(TagFROSTEncryptedShareSum, context, A, B, T_1, T_2)
+	z := new(btcec.ModNScalar).Mul2(c, r).Add(k)
+
+	return &DLEQProof{Challenge: c, Response: z}
+}
+
+func (p *FrostParticipant) verifyDLEQ(context []byte, proof *DLEQProof, X, A, B *btcec.JacobianPoint) bool {
+	if proof.Challenge == nil || proof.Response == nil {
+		return false
+	}
+	T := dleqCommitments(p.suite, proof.Challenge, proof.Response, X, A, B)
+	c := dleqChallenge(TagFROSTEncryptedShareSum, context, A, B, T[0], T[1])
+
+	return c.Equals(proof.Challenge)
+}
+
+// (C2_k, C2_k - G), the ciphertext encrypts 0 under the first or the second
+func encryptedBitTargets(suite *TestSuite, C_2 *btcec.JacobianPoint) [2]*btcec.JacobianPoint {
+	G := new(btcec.JacobianPoint)
+	suite.scalarBaseMult(new(btcec.ModNScalar).SetInt(1), G)
+	C_2_minus_G := new(btcec.JacobianPoint)
+	suite.addPoints(C_2, negatePoint(G), C_2_minus_G)
+
+	return [2]*btcec.JacobianPoint{C_2, C_2_minus_G}
+}
+
+// T_1 = z * G - c * A, T_2 = z * X - c * B
+func dleqCommitments(suite *TestSuite, c, z *btcec.ModNScalar, X, A, B *btcec.JacobianPoint) [2]*btcec.JacobianPoint {
+	neg_c := new(btcec.ModNScalar).NegateVal(c)
+
+	T_1 := new(btcec.JacobianPoint)
+	suite.scalarBaseMult(z, T_1)
+	term := new(btcec.JacobianPoint)
+	suite.scalarMult(neg_c, A, term)
+	suite.addPoints(T_1, term, T_1)
+
+	T_2 := new(btcec.JacobianPoint)
+	suite.scalarMult(z, X, T_2)
+	term = new(btcec.JacobianPoint)
+	suite.scalarMult(neg_c, B, term)
+	suite.addPoints(T_2, term, T_2)
+
+	return [2]*btcec.JacobianPoint{T_1, T_2}
+}
+
+func dleqChallenge(tag, context []byte, points ...*btcec.JacobianPoint) *btcec.ModNScalar {
+	data := append([]byte{}, context...)
+	for _, point := range points {
+		data = append(data, serializePoint(point)...)
+	}
+	challenge_hash := chainhash.TaggedHash(tag, data)
+	c := new(btcec.ModNScalar)
+	c.SetByteSlice(challenge_hash[:])
+
+	return c
+}
+
+// proofs are bound to the dealer, the recipient and its encryption key, thus cannot be replayed for another share
+func encryptedShareContext(dealer, recipient int64, encryption_key *btcec.PublicKey) []byte {
+	context := make([]byte, 0, 16+33)
+	context = append(context, Int64ToBytes(dealer)...)
+	context = append(context, Int64ToBytes(recipient)...)
+	context = append(context, encryption_key.SerializeCompressed()...)
+
+	return context
+}
+
+func bitContext(context []byte, k int) []byte {
+	return append(append([]byte{}, context...), Int64ToBytes(int64(k))...)
+}
+
+func jacobianToPublicKey(point *btcec.JacobianPoint) *btcec.PublicKey {
+	affine := new(btcec.JacobianPoint)
+	affine.Set(point)
+	affine.ToAffine()
+
+	return btcec.NewPublicKey(&affine.X, &affine.Y)
+}
@@ -0,0 +1,164 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+var (
+	ErrInconsistentSharing  = errors.New("prove same group key: public shares do not lie on a polynomial of degree threshold")
+	ErrGroupKeyChanged      = errors.New("prove same group key: sharings interpolate to different group keys")
+	ErrInvalidEqualityProof = errors.New("verify same group key: invalid equality proof")
+)
+
+// EqualityProof shows that two sharings, e.g. before and after a reshare, have the same group public key
+// all inputs are public signing shares Y_i = g^s_i, thus no secret is involved
+// the proof only names the group key and a threshold + 1 interpolation set of each sharing,
+// the verifier interpolates in the exponent over the public signing shares it already knows
+//
+// Y = \sum_{i \in S} \lambda_i * Y_i = \sum_{j \in S'} \lambda_j * Y'_j
+type EqualityProof struct {
+	GroupKey *btcec.PublicKey
+	OldSet   []int64
+	NewSet   []int64
+}
+
+// both sharings must be of degree Threshold
+// every public share beyond the interpolation set must lie on the polynomial of the set, so that a sharing
+// with an inconsistent share cannot be proven equal by choosing a convenient subset
+func (p *FrostParticipant) ProveSameGroupKey(oldShares, newShares map[int64]*btcec.JacobianPoint) (*EqualityProof, error) {
+	old_set, err := checkSharingConsistency(p.suite, p.Threshold, oldShares, nil)
+	if err != nil {
+		return nil, fmt.Errorf("old sharing: %w", err)
+	}
+	new_set, err := checkSharingConsistency(p.suite, p.Threshold, newShares, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new sharing: %w", err)
+	}
+
+	Y_old, err := interpolateAtZero(oldShares, old_set)
+	if err != nil {
+		return nil, fmt.Errorf("old sharing: %w", err)
+	}
+	Y_new, err := interpolateAtZero(newShares, new_set)
+	if err != nil {
+		return nil, fmt.Errorf("new sharing: %w", err)
+	}
+	if !equalPoints(Y_old, Y_new) {
+		return nil, ErrGroupKeyChanged
+	}
+	Y_old.ToAffine()
+
+	return &EqualityProof{
+		GroupKey: btcec.NewPublicKey(&Y_old.X, &Y_old.Y),
+		OldSet:   old_set,
+		NewSet:   new_set,
+	}, nil
+}
+
+// the threshold and both sharings are known to the verifier, only the group key and the interpolation sets
+// come from the prover, thus a prover cannot bring shares that interpolate to a key of its choice
+// both sharings are checked for consistency over the sets of the proof, then interpolated
+func VerifySameGroupKey(suite *TestSuite, threshold int64, oldShares, newShares map[int64]*btcec.JacobianPoint, proof *EqualityProof) error {
+	if proof == nil || proof.GroupKey == nil {
+		return ErrInvalidEqualityProof
+	}
+	expected := new(btcec.JacobianPoint)
+	proof.GroupKey.AsJacobian(expected)
+	sharings := []struct {
+		shares map[int64]*btcec.JacobianPoint
+		set    []int64
+	}{
+		{oldShares, proof.OldSet},
+		{newShares, proof.NewSet},
+	}
+	for _, sharing := range sharings {
+		if _, err := checkSharingConsistency(suite, threshold, sharing.shares, sharing.set); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEqualityProof, err)
+		}
+		Y, err := interpolateAtZero(sharing.shares, sharing.set)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidEqualityProof, err)
+		}
+		if !equalPoints(Y, expected) {
+			return ErrInvalidEqualityProof
+		}
+	}
+
+	return nil
+}
+
+// returns the interpolation set, the lowest threshold + 1 positions when set is nil
+// Y_j = \sum_{i \in S} \lambda_i(j) * Y_i for every other position j
+func checkSharingConsistency(suite *TestSuite, threshold int64, shares map[int64]*btcec.JacobianPoint, set []int64) ([]int64, error) {
+	if int64(len(shares)) <= threshold {
+		return nil, ErrNotEnoughPublicShares
+	}
+	positions := sortedPositions(shares)
+	for _, posi := range positions {
+		if posi < 1 {
+			return nil, fmt.Errorf("%w: %d", ErrIndexOutOfRange, posi)
+		}
+	}
+	if set == nil {
+		set = positions[:threshold+1]
+	}
+	if int64(len(set)) != threshold+1 {
+		return nil, fmt.Errorf("%w: interpolation set of %d positions", ErrInconsistentSharing, len(set))
+	}
+	in_set := make(map[int64]bool, len(set))
+	points := make([]*btcec.JacobianPoint, len(set))
+	for k, posi := range set {
+		if in_set[posi] || shares[posi] == nil {
+			return nil, fmt.Errorf("%w: interpolation position %d", ErrInconsistentSharing, posi)
+		}
+		in_set[posi] = true
+		points[k] = shares[posi]
+	}
+
+	for _, posi := range positions {
+		if in_set[posi] {
+			continue
+		}
+		lambdas := make([]*btcec.ModNScalar, len(set))
+		for k, i := range set {
+			lambdas[k] = suite.CalculateLagrangeCoeffAt(i, posi, set)
+		}
+		expected := new(btcec.JacobianPoint)
+		if err := suite.multiScalarMul(lambdas, points, expected); err != nil {
+			return nil, err
+		}
+		if !equalPoints(expected, shares[posi]) {
+			return nil, fmt.Errorf("%w: position %d", ErrInconsistentSharing, posi)
+		}
+	}
+
+	return set, nil
+}
+
+// Lagrange interpolation in the exponent at 0
+func interpolateAtZero(shares map[int64]*btcec.JacobianPoint, set []int64) (*btcec.JacobianPoint, error) {
+	// Lagrange coefficients do not depend on suite state
+	lambdas := (&TestSuite{}).CalculateLagrangeCoeffs(set)
+	scalars := make([]*btcec.ModNScalar, len(set))
+	points := make([]*btcec.JacobianPoint, len(set))
+	for k, posi := range set {
+		scalars[k] = lambdas[posi]
+		points[k] = shares[posi]
+	}
+
+	return multiScalarMulJacobian(scalars, points)
+}
+
+func sortedPositions(shares map[int64]*btcec.JacobianPoint) []int64 {
+	positions := make([]int64, 0, len(shares))
+	for posi := range shares {
+		positions = append(positions, posi)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+
+	return positions
+}
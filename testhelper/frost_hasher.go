@@ -0,0 +1,50 @@
+package testhelper
+
+import (
+	"crypto/sha256"
+	"hash"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	TagFROSTCommitmentSet = []byte("FROST/commitment-set")
+)
+
+// CommitmentHasher hashes commitments as they stream in, without buffering the full set
+// the result equals HashCommitments over the same points in the same order
+type CommitmentHasher struct {
+	hasher hash.Hash
+}
+
+// tagged hash H_tag(m) = SHA256(SHA256(tag) || SHA256(tag) || m)
+// the tag prefix is written once, then each commitment is appended
+func (p *FrostParticipant) CommitmentHasher() *CommitmentHasher {
+	tag_hash := sha256.Sum256(TagFROSTCommitmentSet)
+	hasher := sha256.New()
+	hasher.Write(tag_hash[:])
+	hasher.Write(tag_hash[:])
+
+	return &CommitmentHasher{hasher: hasher}
+}
+
+func (h *CommitmentHasher) Write(point *btcec.JacobianPoint) {
+	h.hasher.Write(serializePoint(point))
+}
+
+func (h *CommitmentHasher) Sum() [32]byte {
+	var sum [32]byte
+	copy(sum[:], h.hasher.Sum(nil))
+	return sum
+}
+
+// one - shot hash of the full commitment set
+func HashCommitments(points []*btcec.JacobianPoint) [32]byte {
+	commitments_data := make([]byte, 0, len(points)*33)
+	for _, point := range points {
+		commitments_data = append(commitments_data, serializePoint(point)...)
+	}
+
+	return *chainhash.TaggedHash(TagFROSTCommitmentSet, commitments_data)
+}
@@ -0,0 +1,225 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	HWIFormatVersion = 0x01
+
+	hwiPointSize  = 33
+	hwiScalarSize = 32
+	// C_1 || e
+	hwiEncryptedShareSize = hwiPointSize + hwiScalarSize
+	// version || position || threshold || Y || encrypted share || n
+	hwiHeaderSize = 1 + 8 + 8 + hwiPointSize + hwiEncryptedShareSize + 8
+)
+
+var (
+	TagFROSTEncryptedSharePad = []byte("FROST/encrypted-share-pad")
+
+	ErrMissingHWIEncryptionKey = errors.New("export hwi format: device encryption key not set")
+	ErrInvalidHWIFormat        = errors.New("parse hwi format: invalid blob")
+	ErrInvalidHWIShare         = errors.New("decrypt hwi share: decrypted share does not match the public signing share")
+)
+
+// HWIExport is the DKG result of a participant as imported by an air - gapped hardware wallet
+// the signing share s_i is masked to the device key X = x * G, see MaskedShare
+// the device checks the decrypted share against Y_i, thus a corrupted blob is caught on import
+//
+// byte layout, integers are 8 bytes big endian, points are 33 bytes compressed, scalars are 32 bytes big endian
+//
+//	offset  size     field
+//	0       1        version, HWIFormatVersion
+//	1       8        position i
+//	9       8        threshold t
+//	17      33       group public key Y
+//	50      33       C_1 = r * G
+//	83      32       e = s_i + H(r * X)
+//	115     8        n, number of public signing shares
+//	123     33 * n   public signing shares Y_1 .. Y_n
+type HWIExport struct {
+	Position            int64
+	Threshold           int64
+	GroupPublicKey      *btcec.PublicKey
+	MaskedShare         *MaskedShare
+	PublicSigningShares map[int64]*btcec.PublicKey
+}
+
+// hashed ElGamal encryption of a scalar s to X = x * G
+// C_1 = r * G
+// e = s + H(r * X), the masked share for the holder of x to recover s
+//
+// e can only be checked by the holder of x, e.g. against a public signing share
+type MaskedShare struct {
+	C1     *btcec.PublicKey
+	Masked *btcec.ModNScalar
+}
+
+// key of the hardware wallet the signing share is exported to
+func (p *FrostParticipant) SetHWIEncryptionKey(key *btcec.PublicKey) {
+	p.hwi_encryption_key = key
+}
+
+// errors with ErrMissingPublicSigningShare when the public signing share of any of the n participants is missing
+func (p *FrostParticipant) ExportToHWIFormat() ([]byte, error) {
+	if p.signingShares == nil {
+		return nil, fmt.Errorf("export hwi format: %w", ErrMissingSigningShare)
+	}
+	if p.hwi_encryption_key == nil {
+		return nil, ErrMissingHWIEncryptionKey
+	}
+	if p.GroupPublicKey == nil {
+		return nil, ErrMissingGroupKey
+	}
+	public_shares, err := p.allPublicSigningShares()
+	if err != nil {
+		return nil, fmt.Errorf("export hwi format: %w", err)
+	}
+	masked_share := p.maskShare(p.signingShares, p.hwi_encryption_key)
+
+	blob := make([]byte, 0, hwiHeaderSize+int(p.N)*hwiPointSize)
+	blob = append(blob, HWIFormatVersion)
+	blob = append(blob, Int64ToBytes(p.Position)...)
+	blob = append(blob, Int64ToBytes(p.Threshold)...)
+	blob = append(blob, p.GroupPublicKey.SerializeCompressed()...)
+	blob = append(blob, masked_share.C1.SerializeCompressed()...)
+	masked_bytes := masked_share.Masked.Bytes()
+	blob = append(blob, masked_bytes[:]...)
+	blob = append(blob, Int64ToBytes(p.N)...)
+	for _, share := range public_shares {
+		blob = append(blob, share.SerializeCompressed()...)
+	}
+
+	return blob, nil
+}
+
+// parse a blob produced by ExportToHWIFormat, the share stays encrypted
+func ParseHWIFormat(data []byte) (*HWIExport, error) {
+	if len(data) < hwiHeaderSize || data[0] != HWIFormatVersion {
+		return nil, ErrInvalidHWIFormat
+	}
+	offset := 1
+	readInt64 := func() int64 {
+		value := BytesToInt64(data[offset : offset+8])
+		offset += 8
+		return value
+	}
+	readPoint := func() (*btcec.PublicKey, error) {
+		point, err := btcec.ParsePubKey(data[offset : offset+hwiPointSize])
+		offset += hwiPointSize
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidHWIFormat, err)
+		}
+		return point, nil
+	}
+	readScalar := func() (*btcec.ModNScalar, error) {
+		scalar := new(btcec.ModNScalar)
+		overflow := scalar.SetByteSlice(data[offset : offset+hwiScalarSize])
+		offset += hwiScalarSize
+		if overflow {
+			return nil, ErrInvalidHWIFormat
+		}
+		return scalar, nil
+	}
+
+	export := &HWIExport{
+		Position:            readInt64(),
+		Threshold:           readInt64(),
+		MaskedShare:         &MaskedShare{},
+		PublicSigningShares: make(map[int64]*btcec.PublicKey),
+	}
+	var err error
+	if export.GroupPublicKey, err = readPoint(); err != nil {
+		return nil, err
+	}
+	if export.MaskedShare.C1, err = readPoint(); err != nil {
+		return nil, err
+	}
+	if export.MaskedShare.Masked, err = readScalar(); err != nil {
+		return nil, err
+	}
+
+	n := readInt64()
+	if n < 1 || export.Position < 1 || export.Position > n || export.Threshold < 0 || export.Threshold >= n {
+		return nil, ErrInvalidHWIFormat
+	}
+	if int64(len(data)-offset) != n*hwiPointSize {
+		return nil, fmt.Errorf("%w: %d bytes of public signing shares, expected %d", ErrInvalidHWIFormat, len(data)-offset, n*hwiPointSize)
+	}
+	for posi := int64(1); posi <= n; posi++ {
+		if export.PublicSigningShares[posi], err = readPoint(); err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
+
+// the device recovers s_i = e - H(x * C_1) and checks s_i * G = Y_i
+func (e *HWIExport) DecryptShare(encryption_secret *btcec.PrivateKey) (*btcec.ModNScalar, error) {
+	Y_i := e.PublicSigningShares[e.Position]
+	if Y_i == nil {
+		return nil, ErrInvalidHWIShare
+	}
+	share := unmaskShare(e.MaskedShare, encryption_secret)
+	actual := new(btcec.JacobianPoint)
+	btcec.ScalarBaseMultNonConst(share, actual)
+	expected := new(btcec.JacobianPoint)
+	Y_i.AsJacobian(expected)
+	if !equalPoints(actual, expected) {
+		return nil, ErrInvalidHWIShare
+	}
+
+	return share, nil
+}
+
+func (p *FrostParticipant) maskShare(share *btcec.ModNScalar, encryption_key *btcec.PublicKey) *MaskedShare {
+	r := p.suite.randomScalar()
+	X := new(btcec.JacobianPoint)
+	encryption_key.AsJacobian(X)
+
+	// C_1 = r * G
+	C_1 := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(r, C_1)
+	C_1.ToAffine()
+
+	// r * X
+	shared_point := new(btcec.JacobianPoint)
+	p.suite.scalarMult(r, X, shared_point)
+
+	// e = s + H(r * X)
+	masked := maskedSharePad(shared_point)
+	masked.Add(share)
+
+	return &MaskedShare{
+		C1:     btcec.NewPublicKey(&C_1.X, &C_1.Y),
+		Masked: masked,
+	}
+}
+
+// s = e - H(x * C_1), x * C_1 = r * X
+func unmaskShare(masked_share *MaskedShare, encryption_secret *btcec.PrivateKey) *btcec.ModNScalar {
+	C_1 := new(btcec.JacobianPoint)
+	masked_share.C1.AsJacobian(C_1)
+
+	shared_point := new(btcec.JacobianPoint)
+	btcec.ScalarMultNonConst(&encryption_secret.Key, C_1, shared_point)
+
+	share := maskedSharePad(shared_point)
+	share.Negate().Add(masked_share.Masked)
+
+	return share
+}
+
+func maskedSharePad(shared_point *btcec.JacobianPoint) *btcec.ModNScalar {
+	pad_hash := chainhash.TaggedHash(TagFROSTEncryptedSharePad, serializePoint(shared_point))
+	pad := new(btcec.ModNScalar)
+	pad.SetByteSlice(pad_hash[:])
+
+	return pad
+}
@@ -0,0 +1,54 @@
+package testhelper
+
+import (
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// \lambda_i = \prod_{j \in S, j != i} j / (j - i) mod N, S is the signer set
+// results are cached by the sorted signer set, repeated signings with the same quorum skip the inversions
+// the returned scalar is a copy, callers can modify it
+func (p *FrostParticipant) CalculateLagrangeCoeff(position int64, signers map[int64]bool) *btcec.ModNScalar {
+	set := signerSet(signers)
+	assert.Contains(p.suite.T, set, position, "calculate lagrange coeff: position %d not in signer set", position)
+
+	key := lagrangeCacheKey(position, set)
+	if value, ok := p.lagrange_cache.Load(key); ok {
+		return new(btcec.ModNScalar).Set(value.(*btcec.ModNScalar))
+	}
+
+	lambda := p.suite.CalculateLagrangeCoeff(position, set)
+	p.lagrange_cache.Store(key, lambda)
+
+	return new(btcec.ModNScalar).Set(lambda)
+}
+
+// drop all cached Lagrange coefficients, e.g. when the participant set changes
+func (p *FrostParticipant) InvalidateLagrangeCache() {
+	p.lagrange_cache.Range(func(key, _ interface{}) bool {
+		p.lagrange_cache.Delete(key)
+		return true
+	})
+}
+
+// number of cached Lagrange coefficients
+func (p *FrostParticipant) LagrangeCacheLen() int {
+	count := 0
+	p.lagrange_cache.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+
+	return count
+}
+
+// i || j_1 || ... || j_k, {j_1, ..., j_k} is the sorted signer set
+func lagrangeCacheKey(position int64, set []int64) string {
+	key := make([]byte, 0, 8*(len(set)+1))
+	key = append(key, Int64ToBytes(position)...)
+	for _, j := range set {
+		key = append(key, Int64ToBytes(j)...)
+	}
+
+	return string(key)
+}
@@ -0,0 +1,138 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+var (
+	ErrInvalidEnrollmentPosition = errors.New("enroll participant: new position must follow the current participants")
+	ErrMissingEnrollmentMask     = errors.New("provide enrollment share: missing mask for the participant")
+	ErrRevokedParticipant        = errors.New("frost participant: participant has been revoked")
+)
+
+// EnrollmentShares is the contribution of helper h to the enrollment of a new position v
+// Masks holds g_h(j) for every active member j, g_h is random of degree t with g_h(v) = 0
+type EnrollmentShares struct {
+	Helper      int64
+	NewPosition int64
+	Masks       map[int64]*btcec.ModNScalar
+}
+
+// onboarding a new position v after the DKG, the newcomer receives s_v = f(v) on the existing polynomial
+// thus the group public key is unchanged and the newcomer signs like any other member
+//
+// it follows the share recovery, see DealRecoveryMasks
+// r_j = s_j + \sum_{h \in H} g_h(j) is sent to the newcomer, which interpolates at v with CompleteEnrollment
+// the masks of a single honest helper hide every s_j from the newcomer
+func (p *FrostParticipant) EnrollParticipant(newPos int64) (EnrollmentShares, error) {
+	if newPos != p.N+1 {
+		return EnrollmentShares{}, fmt.Errorf("%w: %d with %d participants", ErrInvalidEnrollmentPosition, newPos, p.N)
+	}
+	members := p.ActiveMembers()
+	p.admit(newPos)
+
+	// g_h(x) = (x - v) * k(x), k has degree t - 1
+	return EnrollmentShares{
+		Helper:      p.Position,
+		NewPosition: newPos,
+		Masks:       p.zeroMasks(newPos, members),
+	}, nil
+}
+
+// r_j = s_j + \sum_{h \in H} g_h(j), sent privately to the newcomer
+// every member sending r_j must use the masks of the same helpers H
+func (p *FrostParticipant) ProvideEnrollmentShare(enrollments []EnrollmentShares) (*btcec.ModNScalar, error) {
+	share := new(btcec.ModNScalar).Set(p.GetSigningShares())
+	for _, enrollment := range enrollments {
+		mask, ok := enrollment.Masks[p.Position]
+		if !ok || enrollment.NewPosition != enrollments[0].NewPosition {
+			return nil, fmt.Errorf("%w: helper %d", ErrMissingEnrollmentMask, enrollment.Helper)
+		}
+		share.Add(mask)
+	}
+	if len(enrollments) > 0 {
+		p.admit(enrollments[0].NewPosition)
+	}
+
+	return share, nil
+}
+
+// the newcomer only knows the group polynomial commitments, its own secret polynomial is never dealt
+// the group public key is derived from the commitments right away
+func NewEnrollee(suite *TestSuite, logger *log.Logger, n, threshold, posi int64, commitments map[int64][]*btcec.PublicKey) *FrostParticipant {
+	enrollee := newEmptyFrostParticipant(suite, logger, n, threshold, posi)
+	enrollee.UpdateAllPolynomialCommitments(commitments)
+	enrollee.CalculateGroupPublicKey()
+
+	return enrollee
+}
+
+// s_v = \sum_{j} \lambda_j(v) * r_j over at least t + 1 enrollment shares
+// s_v is checked against Y_v = \sum_{k=0}^{t} v^k * Q_k from the group polynomial commitments
+func (p *FrostParticipant) CompleteEnrollment(enrollment_shares map[int64]*btcec.ModNScalar) (*btcec.ModNScalar, error) {
+	Q_k := make([]*btcec.JacobianPoint, p.Threshold+1)
+	for k := range Q_k {
+		Q_k[k] = new(btcec.JacobianPoint)
+	}
+	for _, dealer := range p.QualifiedSet() {
+		commitments, _ := p.GetPolynomialCommitments(dealer)
+		for k, commitment := range commitments {
+			A_k := new(btcec.JacobianPoint)
+			commitment.AsJacobian(A_k)
+			p.suite.addPoints(Q_k[k], A_k, Q_k[k])
+		}
+	}
+	Y_v := hornerSmall(Q_k, uint32(p.Position))
+	Y_v.ToAffine()
+	p.StorePublicSigningShares(p.Position, btcec.NewPublicKey(&Y_v.X, &Y_v.Y))
+
+	return p.CompleteShareRecovery(enrollment_shares)
+}
+
+// offboard member pos, it can no longer take part in signing sessions of this participant
+// its share s_pos stays on the group polynomial until the remaining members refresh their shares,
+// thus the member also deals a refresh over the remaining members
+// once every remaining member applied the transcripts with ApplyReshare, s_pos is useless
+func (p *FrostParticipant) RevokeParticipant(pos int64) (*ReshareTranscript, error) {
+	if err := p.validateIndex(pos); err != nil {
+		return nil, err
+	}
+	if p.revoked[pos] {
+		return nil, fmt.Errorf("%w: %d", ErrRevokedParticipant, pos)
+	}
+	if p.revoked == nil {
+		p.revoked = make(map[int64]bool)
+	}
+	p.revoked[pos] = true
+	transcript, err := p.Reshare(p.Threshold, p.ActiveMembers())
+	if err != nil {
+		// too few members would remain to sign
+		delete(p.revoked, pos)
+		return nil, err
+	}
+
+	return transcript, nil
+}
+
+// positions in [1, n] that have not been revoked, in ascending order
+func (p *FrostParticipant) ActiveMembers() []int64 {
+	members := make([]int64, 0, p.N)
+	for posi := int64(1); posi <= p.N; posi++ {
+		if !p.revoked[posi] {
+			members = append(members, posi)
+		}
+	}
+
+	return members
+}
+
+func (p *FrostParticipant) admit(newPos int64) {
+	if newPos == p.N+1 {
+		p.N = newPos
+		p.InvalidateLagrangeCache()
+	}
+}
@@ -0,0 +1,119 @@
+package testhelper
+
+import (
+	"bytes"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	TagFROSTPublicShareLeaf   = []byte("FROST/public-share-leaf")
+	TagFROSTPublicShareBranch = []byte("FROST/public-share-branch")
+)
+
+// light clients can verify a single public signing share Y_i against a committed root
+// without downloading all n public signing shares
+//
+// leaf_i = H_leaf(i || Y_i), i \in [1, n]
+// branch = H_branch(left || right)
+// similar to bitcoin merkle tree, the last node is duplicated on a level with odd number of nodes
+func publicShareLeaf(posi int64, share *btcec.PublicKey) []byte {
+	leaf_data := make([]byte, 0)
+	leaf_data = append(leaf_data, Int64ToBytes(posi)...)
+	leaf_data = append(leaf_data, share.SerializeCompressed()...)
+
+	return chainhash.TaggedHash(TagFROSTPublicShareLeaf, leaf_data)[:]
+}
+
+func publicShareBranch(left, right []byte) []byte {
+	branch_data := make([]byte, 0)
+	branch_data = append(branch_data, left...)
+	branch_data = append(branch_data, right...)
+
+	return chainhash.TaggedHash(TagFROSTPublicShareBranch, branch_data)[:]
+}
+
+// build all levels of the tree, level 0 are leaves, last level is the root
+func (p *FrostParticipant) publicShareMerkleTree() ([][][]byte, error) {
+	shares, err := p.allPublicSigningShares()
+	if err != nil {
+		return nil, fmt.Errorf("public share merkle tree: %w", err)
+	}
+
+	level := make([][]byte, p.N)
+	for i, share := range shares {
+		level[i] = publicShareLeaf(int64(i+1), share)
+	}
+
+	tree := [][][]byte{level}
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+
+		next := make([][]byte, len(level)/2)
+		for i := 0; i < len(level); i += 2 {
+			next[i/2] = publicShareBranch(level[i], level[i+1])
+		}
+		tree = append(tree, next)
+		level = next
+	}
+
+	return tree, nil
+}
+
+// errors when the public signing share of any of the n participants is missing, the root would commit to a partial set
+func (p *FrostParticipant) PublicShareMerkleRoot() ([32]byte, error) {
+	var root [32]byte
+	tree, err := p.publicShareMerkleTree()
+	if err != nil {
+		return root, err
+	}
+
+	copy(root[:], tree[len(tree)-1][0])
+	return root, nil
+}
+
+// proof is the list of sibling hashes from the leaf up to the root
+func (p *FrostParticipant) PublicShareMerkleProof(index int64) ([][]byte, error) {
+	if err := p.validateIndex(index); err != nil {
+		return nil, err
+	}
+
+	tree, err := p.publicShareMerkleTree()
+	if err != nil {
+		return nil, err
+	}
+
+	proof := make([][]byte, 0, len(tree)-1)
+	node := index - 1
+	for _, level := range tree[:len(tree)-1] {
+		sibling := node ^ 1
+		if sibling >= int64(len(level)) {
+			// the last node is paired with itself
+			sibling = node
+		}
+		proof = append(proof, level[sibling])
+		node /= 2
+	}
+
+	return proof, nil
+}
+
+// verify a public signing share of participant at index against the committed root
+func VerifyPublicShareMerkleProof(root [32]byte, index int64, share *btcec.PublicKey, proof [][]byte) bool {
+	hash := publicShareLeaf(index, share)
+	node := index - 1
+	for _, sibling := range proof {
+		if node%2 == 0 {
+			hash = publicShareBranch(hash, sibling)
+		} else {
+			hash = publicShareBranch(sibling, hash)
+		}
+		node /= 2
+	}
+
+	return bytes.Equal(hash, root[:])
+}
@@ -0,0 +1,200 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr/musig2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var (
+	ErrGroupKeyNotInKeySet = errors.New("musig2 sign share: group key is not in the key set")
+)
+
+// the group public key as one key of a MuSig2 key aggregation, e.g. with external single signers
+// MuSig2 aggregates plain keys, thus P keeps its Y parity, unlike the x - only key of BIP340
+// the quorum then signs as a single MuSig2 signer, see MuSig2PublicNonce and MuSig2SignShare
+func (p *FrostParticipant) AsMuSig2Key() *btcec.PublicKey {
+	return p.GroupPublicKey
+}
+
+// the public nonce (R_1, R_2) of the group as a MuSig2 signer, each signer of the quorum S preprocesses two nonce pairs
+// R_k = \sum_{i \in S} D_ik + p_ik * E_ik, p_ik = H(i, m, B_k), B_k is the commitment list of the k - th pairs
+func (a *FrostAggregator) MuSig2PublicNonce(msg [32]byte, honest []int64, public_nonces [2]map[int64][2]*btcec.PublicKey) ([musig2.PubNonceSize]byte, error) {
+	var pub_nonce [musig2.PubNonceSize]byte
+	for k := range public_nonces {
+		nonce_commitments := make(map[int64]*btcec.PublicKey, len(honest))
+		for _, posi := range honest {
+			nonces, ok := public_nonces[k][posi]
+			if !ok {
+				return pub_nonce, fmt.Errorf("signer %d: %w", posi, ErrMissingNonceCommitment)
+			}
+			D_i := new(btcec.JacobianPoint)
+			nonces[0].AsJacobian(D_i)
+			E_i := new(btcec.JacobianPoint)
+			nonces[1].AsJacobian(E_i)
+
+			R_i := new(btcec.JacobianPoint)
+			a.suite.scalarMult(bindingFactor(posi, msg, honest, public_nonces[k]), E_i, R_i)
+			a.suite.addPoints(D_i, R_i, R_i)
+			R_i.ToAffine()
+			nonce_commitments[posi] = btcec.NewPublicKey(&R_i.X, &R_i.Y)
+		}
+		R_k, err := AggregateNonceCommitments(nonce_commitments)
+		if err != nil {
+			return pub_nonce, err
+		}
+		copy(pub_nonce[k*btcec.PubKeyBytesLenCompressed:], btcec.NewPublicKey(&R_k.X, &R_k.Y).SerializeCompressed())
+	}
+
+	return pub_nonce, nil
+}
+
+// the share of signer i of the MuSig2 partial signature of the group, keys is the MuSig2 key set in signing order
+// z_i = k_i1 + b * k_i2 + e * a * g * \lambda_i * s_i, k_ik = d_ik + p_ik * e_ik
+// b = H_noncecoef(R_1 || R_2 || Q || m), R = R_1 + b * R_2 over the MuSig2 aggregated nonce, e = H(R, Q, m)
+// a is the key aggregation coefficient of the group key, g = -1 when Q has odd Y coordinate
+// k_i1 and k_i2 are negated when R has odd Y coordinate
+func (p *FrostParticipant) MuSig2SignShare(signing_indices [2]int64, honest []int64, msg [32]byte, public_nonces [2]map[int64][2]*btcec.PublicKey, combinedNonce [musig2.PubNonceSize]byte, keys []*btcec.PublicKey) (*btcec.ModNScalar, error) {
+	R, b, e, Q, err := musig2SigningContext(combinedNonce, keys, msg)
+	if err != nil {
+		return nil, err
+	}
+	a, err := musig2KeyAggCoeff(keys, p.AsMuSig2Key())
+	if err != nil {
+		return nil, err
+	}
+
+	var nonces [2][2]*btcec.ModNScalar
+	p.sessions_mu.Lock()
+	for _, signing_index := range signing_indices {
+		err = p.checkNonceBinding(signing_index, msg)
+		if err == nil {
+			err = p.checkNonceFreshness(signing_index)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		for k, signing_index := range signing_indices {
+			nonces[k] = p.takeNonces(signing_index, nonceSessionBinding(msg, honest, public_nonces[k]))
+		}
+	}
+	p.sessions_mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer zeroNonces(nonces[0])
+	defer zeroNonces(nonces[1])
+
+	z_i := new(btcec.ModNScalar)
+	for k := range nonces {
+		// k_ik = d_ik + p_ik * e_ik
+		k_ik := new(btcec.ModNScalar).Mul2(nonces[k][1], bindingFactor(p.Position, msg, honest, public_nonces[k]))
+		k_ik.Add(nonces[k][0])
+		if R.Y.IsOdd() {
+			k_ik.Negate()
+		}
+		if k == 1 {
+			k_ik.Mul(b)
+		}
+		z_i.Add(k_ik)
+	}
+
+	// e * a * g * \lambda_i * s_i
+	term := new(btcec.ModNScalar).Mul2(e, a).Mul(p.suite.CalculateLagrangeCoeff(p.Position, honest)).Mul(p.GetSigningShares())
+	if Q.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd {
+		term.Negate()
+	}
+	z_i.Add(term)
+
+	return z_i, nil
+}
+
+// the MuSig2 partial signature (s, R) of the group, s = \sum_{i \in S} z_i
+// it is combined with the partial signatures of the other MuSig2 signers by musig2.CombineSigs
+func (a *FrostAggregator) MuSig2PartialSignature(shares map[int64]*btcec.ModNScalar, combinedNonce [musig2.PubNonceSize]byte, keys []*btcec.PublicKey, msg [32]byte) (*musig2.PartialSignature, error) {
+	if int64(len(shares)) <= a.Frost.Threshold {
+		return nil, ErrThresholdNotMet
+	}
+	R, _, _, _, err := musig2SigningContext(combinedNonce, keys, msg)
+	if err != nil {
+		return nil, err
+	}
+	s := new(btcec.ModNScalar)
+	for _, z_i := range shares {
+		s.Add(z_i)
+	}
+	partial_sig := musig2.NewPartialSignature(s, btcec.NewPublicKey(&R.X, &R.Y))
+
+	return &partial_sig, nil
+}
+
+// R = R_1 + b * R_2, b and e as in BIP327, Q is the MuSig2 aggregated key of the unsorted key set
+func musig2SigningContext(combinedNonce [musig2.PubNonceSize]byte, keys []*btcec.PublicKey, msg [32]byte) (*btcec.JacobianPoint, *btcec.ModNScalar, *btcec.ModNScalar, *btcec.PublicKey, error) {
+	aggregate_key, _, _, err := musig2.AggregateKeys(keys, false)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	Q := aggregate_key.FinalKey
+
+	b_hash := chainhash.TaggedHash(musig2.NonceBlindTag, combinedNonce[:], schnorr.SerializePubKey(Q), msg[:])
+	b := new(btcec.ModNScalar)
+	b.SetByteSlice(b_hash[:])
+
+	R_1, err := btcec.ParseJacobian(combinedNonce[:btcec.PubKeyBytesLenCompressed])
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	R_2, err := btcec.ParseJacobian(combinedNonce[btcec.PubKeyBytesLenCompressed:])
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	R := new(btcec.JacobianPoint)
+	btcec.ScalarMultNonConst(b, &R_2, R)
+	btcec.AddNonConst(&R_1, R, R)
+	if (R.X.IsZero() && R.Y.IsZero()) || R.Z.IsZero() {
+		// BIP327 replaces an aggregated nonce at infinity by G
+		btcec.GeneratorJacobian(R)
+	}
+	R.ToAffine()
+
+	e := bip340Challenge(R, Q, msg)
+
+	return R, b, e, Q, nil
+}
+
+// a = H_KeyAgg_coefficient(L || X), L = H_KeyAgg_list(X_1 || ... || X_n)
+// the first key different from X_1 has coefficient 1
+func musig2KeyAggCoeff(keys []*btcec.PublicKey, key *btcec.PublicKey) (*btcec.ModNScalar, error) {
+	key_bytes := key.SerializeCompressed()
+	found := false
+	list := make([]byte, 0, len(keys)*btcec.PubKeyBytesLenCompressed)
+	for _, k := range keys {
+		list = append(list, k.SerializeCompressed()...)
+		found = found || k.IsEqual(key)
+	}
+	if !found {
+		return nil, ErrGroupKeyNotInKeySet
+	}
+	for _, k := range keys {
+		if !k.IsEqual(keys[0]) {
+			if k.IsEqual(key) {
+				return new(btcec.ModNScalar).SetInt(1), nil
+			}
+			break
+		}
+	}
+
+	list_hash := chainhash.TaggedHash(musig2.KeyAggTagList, list)
+	coeff_hash := chainhash.TaggedHash(musig2.KeyAggTagCoeff, list_hash[:], key_bytes)
+	a := new(btcec.ModNScalar)
+	a.SetByteSlice(coeff_hash[:])
+
+	return a, nil
+}
@@ -0,0 +1,48 @@
+package testhelper
+
+import (
+	"errors"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+var (
+	ErrNonceBoundToOtherSighash = errors.New("partial sign: nonce is bound to a different sighash")
+)
+
+// preprocess a nonce pair bound to a single sighash, e.g. of a transaction spending a specific UTXO
+// the nonce pair is appended under a new signing index
+// a partial signature with this nonce pair can only be completed for the sighash,
+// thus a signed partial cannot be reused on a different transaction
+func (p *FrostParticipant) PreprocessNoncesForSighash(sighash [32]byte) (int64, [2]*btcec.PublicKey) {
+	nonces, nonce_commitments := p.generateNoncePair()
+
+	p.sessions_mu.Lock()
+	defer p.sessions_mu.Unlock()
+	p.nonces = append(p.nonces, nonces)
+	p.NonceCommitments = append(p.NonceCommitments, nonce_commitments)
+
+	signing_index := int64(len(p.nonces) - 1)
+	if p.nonce_bindings == nil {
+		p.nonce_bindings = make(map[int64][32]byte)
+	}
+	p.nonce_bindings[signing_index] = sighash
+
+	return signing_index, nonce_commitments
+}
+
+// nonce pairs without binding can sign any message
+func (p *FrostParticipant) checkNonceBinding(signing_index int64, message_hash [32]byte) error {
+	sighash, ok := p.nonce_bindings[signing_index]
+	if ok && sighash != message_hash {
+		return ErrNonceBoundToOtherSighash
+	}
+
+	return nil
+}
+
+// same as PartialSign, but errors when the nonce pair is bound to a different sighash or reused
+func (p *FrostParticipant) PartialSignForSighash(position, signing_index int64, honest_party []int64, sighash [32]byte, public_nonces map[int64][2]*btcec.PublicKey, signing_shares *btcec.ModNScalar) (*schnorr.Signature, error) {
+	return p.partialSign(position, signing_index, honest_party, sighash, public_nonces, signing_shares)
+}
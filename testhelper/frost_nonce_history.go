@@ -0,0 +1,89 @@
+package testhelper
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	TagFROSTNonceHistory = []byte("FROST/nonce-history")
+
+	ErrNonceReused = errors.New("partial sign: nonce commitment was used in a previous session")
+)
+
+// hashes H(D || E) of the nonce commitments used in partial signatures, sorted
+func (p *FrostParticipant) NonceHistory() [][32]byte {
+	history := make([][32]byte, 0, len(p.nonce_history))
+	for hash := range p.nonce_history {
+		history = append(history, hash)
+	}
+	sort.Slice(history, func(i, j int) bool { return bytes.Compare(history[i][:], history[j][:]) < 0 })
+
+	return history
+}
+
+func (p *FrostParticipant) nonceCommitmentHash(signing_index int64) [32]byte {
+	return nonceCommitmentKey(p.NonceCommitments[signing_index][0], p.NonceCommitments[signing_index][1])
+}
+
+// H(D || E)
+func nonceCommitmentKey(D, E *btcec.PublicKey) [32]byte {
+	data := make([]byte, 0, 66)
+	data = append(data, D.SerializeCompressed()...)
+	data = append(data, E.SerializeCompressed()...)
+
+	return *chainhash.TaggedHash(TagFROSTNonceHistory, data)
+}
+
+// the session a nonce pair signed in, H(m || signers || D_j || E_j of every signer)
+func nonceSessionBinding(msg [32]byte, signers []int64, public_nonces map[int64][2]*btcec.PublicKey) [32]byte {
+	data := make([]byte, 0, 32+len(signers)*(8+66))
+	data = append(data, msg[:]...)
+	for _, posi := range signers {
+		data = append(data, Int64ToBytes(posi)...)
+		if nonces, ok := public_nonces[posi]; ok && nonces[0] != nil && nonces[1] != nil {
+			data = append(data, nonces[0].SerializeCompressed()...)
+			data = append(data, nonces[1].SerializeCompressed()...)
+		}
+	}
+
+	return *chainhash.TaggedHash(TagFROSTNonceHistory, data)
+}
+
+// a nonce pair signs a single time, a used commitment showing up again means the randomness was replayed,
+// e.g. a restored snapshot or a reseeded source, and signing two messages with it leaks s_i
+// commitments are compared by value, thus a regenerated pair is caught as well as a second use of the signing index
+func (p *FrostParticipant) checkNonceFreshness(signing_index int64) error {
+	if _, ok := p.nonce_history[p.nonceCommitmentHash(signing_index)]; ok {
+		return ErrNonceReused
+	}
+
+	return nil
+}
+
+// record the commitment with the session it signs in and hand out a copy of the secret nonces
+// the stored nonces are zeroed, the caller zeroes the copy once its signature share is produced
+func (p *FrostParticipant) takeNonces(signing_index int64, binding [32]byte) [2]*btcec.ModNScalar {
+	if p.nonce_history == nil {
+		p.nonce_history = make(map[[32]byte][32]byte)
+	}
+	p.nonce_history[p.nonceCommitmentHash(signing_index)] = binding
+
+	stored := p.nonces[signing_index]
+	nonces := [2]*btcec.ModNScalar{new(btcec.ModNScalar).Set(stored[0]), new(btcec.ModNScalar).Set(stored[1])}
+	zeroNonces(stored)
+
+	return nonces
+}
+
+func zeroNonces(nonces [2]*btcec.ModNScalar) {
+	for _, nonce := range nonces {
+		if nonce != nil {
+			nonce.Zero()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package testhelper
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+var ErrPowerTableMismatch = errors.New("attach power table: table does not match the participant group")
+
+// PowerTable holds i^j for i \in [1, n], j \in [0, t]
+// the powers only depend on (n, t), thus a single table serves every participant of the group
+//
+// the table is read - only once NewPowerTable returns, it can be shared between participants and goroutines
+// without locking, rows returned by Row must not be modified
+type PowerTable struct {
+	N         int64
+	Threshold int64
+
+	rows [][]*btcec.ModNScalar
+}
+
+func NewPowerTable(n, threshold int64) *PowerTable {
+	table, _ := newPowerTableCtx(context.Background(), n, threshold)
+
+	return table
+}
+
+// rows not yet computed when ctx is done are skipped, the table is then discarded
+func newPowerTableCtx(ctx context.Context, n, threshold int64) (*PowerTable, error) {
+	table := &PowerTable{
+		N:         n,
+		Threshold: threshold,
+		rows:      make([][]*btcec.ModNScalar, n),
+	}
+
+	var wg sync.WaitGroup
+	for posi := int64(1); posi <= n; posi++ {
+		wg.Add(1)
+		go func(posi int64) {
+			defer wg.Done()
+			if ctx.Err() != nil {
+				return
+			}
+			table.rows[posi-1] = powers(posi, threshold)
+		}(posi)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// i^j, j \in [0, t]
+func (t *PowerTable) Row(posi int64) []*btcec.ModNScalar {
+	return t.rows[posi-1]
+}
+
+func (t *PowerTable) matches(n, threshold int64) bool {
+	return t != nil && t.N == n && t.Threshold == threshold
+}
+
+// reference a table shared with other participants, DerivePowerMap then copies no scalar
+func (p *FrostParticipant) AttachPowerTable(table *PowerTable) error {
+	if !table.matches(p.N, p.Threshold) {
+		return ErrPowerTableMismatch
+	}
+	p.power_table = table
+
+	return nil
+}
+
+// the table used by DerivePowerMap, to be attached to other participants of the group
+func (p *FrostParticipant) PowerTable() *PowerTable {
+	return p.power_table
+}
@@ -0,0 +1,163 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+var (
+	ErrNotEnoughRecoveryShares = errors.New("complete share recovery: not enough recovery shares")
+	ErrRecoveredShareMismatch  = errors.New("complete share recovery: recovered share does not match public signing share")
+	ErrInvalidRecoveryHelpers  = errors.New("deal recovery masks: invalid helper set")
+	ErrMissingRecoveryMask     = errors.New("provide recovery share: missing mask for the helper")
+)
+
+// RecoveryMasks is the contribution of helper h to the recovery of the share of position Victim
+// Masks holds g_h(j) for every helper j, g_h is random of degree t with g_h(v) = 0
+// the masks are exchanged privately among the helpers, the victim never sees them
+type RecoveryMasks struct {
+	Helper int64
+	Victim int64
+	Masks  map[int64]*btcec.ModNScalar
+}
+
+// RecoveryRequest is broadcast by the victim v to the helpers H it asks for help
+type RecoveryRequest struct {
+	Victim  int64
+	Helpers []int64
+}
+
+// the victim starts the recovery of its own signing share, each helper answers with DealRecoveryMasks(Victim, Helpers)
+//
+// a single responder call per helper, returning a value for the victim alone, cannot work:
+// without masks dealt among the helpers first, the value of a helper reveals its own share s_j to the victim,
+// thus the recovery takes a round of masks among the helpers before ProvideRecoveryShare
+func (p *FrostParticipant) RequestShareRecovery(helpers []int64) (RecoveryRequest, error) {
+	if err := p.validateRecoveryHelpers(p.Position, helpers); err != nil {
+		return RecoveryRequest{}, err
+	}
+
+	sorted_helpers := append([]int64(nil), helpers...)
+	sort.Slice(sorted_helpers, func(i, j int) bool { return sorted_helpers[i] < sorted_helpers[j] })
+
+	return RecoveryRequest{
+		Victim:  p.Position,
+		Helpers: sorted_helpers,
+	}, nil
+}
+
+// a participant that lost its signing share s_v, but still has its position v, asks the helpers H for help
+// each helper h deals g_h(x) = (x - v) * k_h(x) over H, the victim only receives r_j = s_j + \sum_{h \in H} g_h(j)
+// a victim knowing the masks would recover every s_j = r_j - g(j), thus the masks of a single honest helper
+// must stay unknown to the victim
+func (p *FrostParticipant) DealRecoveryMasks(victim int64, helpers []int64) (RecoveryMasks, error) {
+	if err := p.validateRecoveryHelpers(victim, helpers); err != nil {
+		return RecoveryMasks{}, err
+	}
+	is_helper := false
+	for _, posi := range helpers {
+		is_helper = is_helper || posi == p.Position
+	}
+	if !is_helper {
+		return RecoveryMasks{}, fmt.Errorf("%w: %d is not a helper", ErrInvalidRecoveryHelpers, p.Position)
+	}
+
+	return RecoveryMasks{
+		Helper: p.Position,
+		Victim: victim,
+		Masks:  p.zeroMasks(victim, helpers),
+	}, nil
+}
+
+// more than t helpers in [1, n], the victim is not one of them
+func (p *FrostParticipant) validateRecoveryHelpers(victim int64, helpers []int64) error {
+	if err := p.validateIndex(victim); err != nil {
+		return err
+	}
+	if int64(len(helpers)) <= p.Threshold {
+		return fmt.Errorf("%w: %d helpers", ErrInvalidRecoveryHelpers, len(helpers))
+	}
+	for _, posi := range helpers {
+		if posi == victim {
+			return fmt.Errorf("%w: victim %d helps itself", ErrInvalidRecoveryHelpers, victim)
+		}
+		if err := p.validateIndex(posi); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// r_j = s_j + \sum_{h \in H} g_h(j), sent privately to the victim
+// (f + g)(v) = f(v) since g(v) = 0, while t + 1 values of the random f + g reveal nothing about s_j
+//
+// the masks of the helper itself must be included, thus s_j stays hidden even if all other helpers collude with the victim
+func (p *FrostParticipant) ProvideRecoveryShare(masks []RecoveryMasks) (*btcec.ModNScalar, error) {
+	share := new(btcec.ModNScalar).Set(p.GetSigningShares())
+	own_mask := false
+	for _, recovery := range masks {
+		mask, ok := recovery.Masks[p.Position]
+		if !ok || recovery.Victim != masks[0].Victim {
+			return nil, fmt.Errorf("%w: helper %d", ErrMissingRecoveryMask, recovery.Helper)
+		}
+		share.Add(mask)
+		own_mask = own_mask || recovery.Helper == p.Position
+	}
+	if !own_mask {
+		return nil, fmt.Errorf("%w: helper %d", ErrMissingRecoveryMask, p.Position)
+	}
+
+	return share, nil
+}
+
+// g(j) = (j - v) * k(j) for every member j, k is random of degree t - 1
+func (p *FrostParticipant) zeroMasks(v int64, members []int64) map[int64]*btcec.ModNScalar {
+	v_scalar := new(btcec.ModNScalar).SetInt(uint32(v))
+	k := p.suite.GeneratePolynomial(p.Threshold - 1)
+	masks := make(map[int64]*btcec.ModNScalar, len(members))
+	for _, posi := range members {
+		x := new(btcec.ModNScalar).SetInt(uint32(posi))
+		mask := new(btcec.ModNScalar).NegateVal(v_scalar).Add(x)
+		mask.Mul(p.suite.EvaluatePolynomial(k, x))
+		masks[posi] = mask
+	}
+
+	return masks
+}
+
+// s_v = \sum_{j \in H} \lambda_j(v) * r_j, H is the set of helpers
+// at least t + 1 recovery shares are needed
+// the recovered share is checked against the public signing share Y_v when known
+func (p *FrostParticipant) CompleteShareRecovery(recovery_shares map[int64]*btcec.ModNScalar) (*btcec.ModNScalar, error) {
+	if int64(len(recovery_shares)) <= p.Threshold {
+		return nil, ErrNotEnoughRecoveryShares
+	}
+
+	helpers := make([]int64, 0, len(recovery_shares))
+	for posi := range recovery_shares {
+		helpers = append(helpers, posi)
+	}
+	sort.Slice(helpers, func(i, j int) bool { return helpers[i] < helpers[j] })
+
+	signing_share := new(btcec.ModNScalar)
+	for _, posi := range helpers {
+		lambda := p.suite.CalculateLagrangeCoeffAt(posi, p.Position, helpers)
+		signing_share.Add(lambda.Mul(recovery_shares[posi]))
+	}
+
+	if public_share, ok := p.PublicSigningShares.Load(p.Position); ok {
+		Y := new(btcec.JacobianPoint)
+		btcec.ScalarBaseMultNonConst(signing_share, Y)
+		Y.ToAffine()
+		if !btcec.NewPublicKey(&Y.X, &Y.Y).IsEqual(public_share.(*btcec.PublicKey)) {
+			return nil, ErrRecoveredShareMismatch
+		}
+	}
+	p.StoreSigningShares(signing_share)
+
+	return signing_share, nil
+}
@@ -0,0 +1,299 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	ErrMissingRefreshCommitments = errors.New("apply refresh shares: missing refresh commitments of the dealer")
+	ErrInvalidRefreshShare       = errors.New("apply refresh shares: refresh share does not match the dealer commitments")
+	ErrInvalidRefreshDealers     = errors.New("refresh: refresh shares do not match the dealer set")
+	ErrInvalidReshareParameters  = errors.New("reshare: invalid threshold or participants")
+	ErrReshareTranscriptMismatch = errors.New("apply reshare: transcripts disagree on threshold or participants")
+)
+
+// proactive refresh re - randomizes the signing shares while the group secret stays the same
+// each participant i deals \delta_i(x) of degree t with \delta_i(0) = 0, then s_j' = s_j + \sum_{i} \delta_i(j)
+// shares leaked before the refresh cannot be combined with shares leaked after it
+//
+// returns the commitments B_ik = g^\delta_ik, k \in [1, t], sent to every other participant
+// B_i0 is the point at infinity, thus not sent
+func (p *FrostParticipant) GenerateRefreshPolynomial() []*btcec.PublicKey {
+	commitments := p.generateRefreshPolynomial(p.Threshold)
+	p.UpdateRefreshCommitments(p.Position, commitments)
+
+	return commitments
+}
+
+// \delta_i(x) of the given degree with \delta_i(0) = 0, and its commitments B_i1, ..., B_i{degree}
+func (p *FrostParticipant) generateRefreshPolynomial(degree int64) []*btcec.PublicKey {
+	p.refresh_polynomial = p.suite.GeneratePolynomial(degree)
+	p.refresh_polynomial[0] = new(btcec.ModNScalar)
+
+	commitments := make([]*btcec.PublicKey, degree)
+	for k := int64(1); k <= degree; k++ {
+		point := new(btcec.JacobianPoint)
+		p.suite.scalarBaseMult(p.refresh_polynomial[k], point)
+		point.ToAffine()
+		commitments[k-1] = btcec.NewPublicKey(&point.X, &point.Y)
+	}
+
+	return commitments
+}
+
+// store the refresh commitments B_i1, ..., B_it of dealer i
+func (p *FrostParticipant) UpdateRefreshCommitments(dealer int64, commitments []*btcec.PublicKey) {
+	if !assert.NoError(p.suite.T, p.validateIndex(dealer)) {
+		return
+	}
+	if !assert.Len(p.suite.T, commitments, int(p.Threshold), "refresh commitments of dealer %d", dealer) {
+		return
+	}
+	p.storeRefreshCommitments(dealer, commitments)
+}
+
+func (p *FrostParticipant) storeRefreshCommitments(dealer int64, commitments []*btcec.PublicKey) {
+	if p.refresh_commitments == nil {
+		p.refresh_commitments = make(map[int64][]*btcec.PublicKey)
+	}
+	p.refresh_commitments[dealer] = commitments
+}
+
+// \delta_i(j), the refresh share of participant j
+func (p *FrostParticipant) RefreshShare(posi int64) *btcec.ModNScalar {
+	if !assert.NoError(p.suite.T, p.validateIndex(posi)) {
+		return nil
+	}
+
+	return p.suite.EvaluatePolynomial(p.refresh_polynomial, new(btcec.ModNScalar).SetInt(uint32(posi)))
+}
+
+// verify every refresh share g^\delta_j(i) = \sum_{k=1}^{t} i^k * B_jk, then s_i' = s_i + \sum_{j \in D} \delta_j(i)
+// D is the dealer set agreed on by all participants, refresh_shares must hold a share of every dealer in D and no other
+// the same D is passed to RefreshPublicSigningShares, otherwise public and private shares diverge
+// the signing share is only updated when all shares verify
+func (p *FrostParticipant) ApplyRefreshShares(dealers []int64, refresh_shares map[int64]*btcec.ModNScalar) error {
+	return p.applyRefreshShares(p.Threshold, dealers, p.refresh_commitments, refresh_shares)
+}
+
+// shared by ApplyRefreshShares and ApplyReshare, the refresh commitments of every dealer in D have the given degree
+// g^\delta_j(i) = \sum_{k=1}^{degree} i^k * B_jk is evaluated at i with Horner's rule, B_j0 is the point at infinity
+func (p *FrostParticipant) applyRefreshShares(degree int64, dealers []int64, refresh_commitments map[int64][]*btcec.PublicKey, refresh_shares map[int64]*btcec.ModNScalar) error {
+	if err := p.validateRefreshDealers(dealers); err != nil {
+		return err
+	}
+	if len(refresh_shares) != len(dealers) {
+		return fmt.Errorf("%w: %d shares for %d dealers", ErrInvalidRefreshDealers, len(refresh_shares), len(dealers))
+	}
+	for _, dealer := range dealers {
+		share, ok := refresh_shares[dealer]
+		if !ok || share == nil {
+			return fmt.Errorf("%w: missing share of dealer %d", ErrInvalidRefreshDealers, dealer)
+		}
+		commitments, ok := refresh_commitments[dealer]
+		if !ok || int64(len(commitments)) != degree {
+			return fmt.Errorf("%w: dealer %d", ErrMissingRefreshCommitments, dealer)
+		}
+		points := make([]*btcec.JacobianPoint, degree+1)
+		points[0] = new(btcec.JacobianPoint)
+		for k, commitment := range commitments {
+			points[k+1] = new(btcec.JacobianPoint)
+			commitment.AsJacobian(points[k+1])
+		}
+		expected := hornerSmall(points, uint32(p.Position))
+
+		actual := new(btcec.JacobianPoint)
+		p.suite.scalarBaseMult(share, actual)
+		if !equalPoints(actual, expected) {
+			return fmt.Errorf("%w: dealer %d", ErrInvalidRefreshShare, dealer)
+		}
+	}
+
+	signing_share := new(btcec.ModNScalar).Set(p.signingShares)
+	for _, share := range refresh_shares {
+		signing_share.Add(share)
+	}
+	p.StoreSigningShares(signing_share)
+
+	return nil
+}
+
+// recompute the public signing shares of all participants from the refreshed state
+// the summed commitments Q_k of the Q map absorb the refresh commitments, Q_k' = Q_k + \sum_{i \in D} B_ik, k \in [1, t]
+// then the W map and every Y_j are derived again, Q_0 and thus the group public key are unchanged
+// D is the dealer set passed to ApplyRefreshShares, commitments of other dealers are ignored
+//
+// the refresh commitments are consumed, calling it again without a new refresh changes nothing
+// it also completes ApplyReshare, the power map is derived again when the threshold changed
+func (p *FrostParticipant) RefreshPublicSigningShares(dealers []int64) error {
+	if err := p.validateRefreshDealers(dealers); err != nil {
+		return err
+	}
+	B_k := make([]*btcec.JacobianPoint, p.Threshold+1)
+	for k := range B_k {
+		B_k[k] = new(btcec.JacobianPoint)
+	}
+	for _, dealer := range dealers {
+		commitments, ok := p.refresh_commitments[dealer]
+		if !ok || int64(len(commitments)) != p.Threshold {
+			return fmt.Errorf("%w: dealer %d", ErrMissingRefreshCommitments, dealer)
+		}
+		for k, commitment := range commitments {
+			point := new(btcec.JacobianPoint)
+			commitment.AsJacobian(point)
+			p.suite.addPoints(B_k[k+1], point, B_k[k+1])
+		}
+	}
+
+	// after a reshare to a higher threshold, Q_k' = \sum_{i} B_ik for k above the old degree
+	for posi := int64(1); posi <= p.N; posi++ {
+		Q_j_arr := p.GetQMapItem(posi)
+		refreshed := make([]*btcec.JacobianPoint, p.Threshold+1)
+		for k := range refreshed {
+			refreshed[k] = new(btcec.JacobianPoint)
+			if k < len(Q_j_arr) {
+				p.suite.addPoints(Q_j_arr[k], B_k[k], refreshed[k])
+			} else {
+				refreshed[k].Set(B_k[k])
+			}
+		}
+		p.StoreQMapItem(posi, refreshed)
+	}
+	p.refresh_commitments = nil
+	p.refresh_polynomial = nil
+
+	if !p.power_table.matches(p.N, p.Threshold) {
+		p.DerivePowerMap()
+	}
+	p.DeriveExternalWMap()
+	p.CalculateBatchPublicSigningShares(nil)
+
+	return nil
+}
+
+// D must be a non empty set of distinct positions
+func (p *FrostParticipant) validateRefreshDealers(dealers []int64) error {
+	if len(dealers) == 0 {
+		return fmt.Errorf("%w: empty dealer set", ErrInvalidRefreshDealers)
+	}
+	seen := make(map[int64]bool, len(dealers))
+	for _, dealer := range dealers {
+		if err := p.validateIndex(dealer); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidRefreshDealers, err)
+		}
+		if seen[dealer] {
+			return fmt.Errorf("%w: dealer %d appears twice", ErrInvalidRefreshDealers, dealer)
+		}
+		seen[dealer] = true
+	}
+
+	return nil
+}
+
+// ReshareTranscript is the contribution of a dealer to a reshare
+// the shares are private, \delta_i(j) is delivered to participant j only
+type ReshareTranscript struct {
+	Dealer       int64
+	Threshold    int64
+	Participants []int64
+	// B_ik = g^\delta_ik, k \in [1, t']
+	Commitments []*btcec.PublicKey
+	Shares      map[int64]*btcec.ModNScalar
+}
+
+// a proactive refresh that can also raise the threshold to t' >= t, the group public key stays the same
+// \delta_i(x) has degree t' and \delta_i(0) = 0, thus s_j' = f(j) + \sum_{i} \delta_i(j) lies on a polynomial of degree t'
+// with the same constant term, and t' + 1 participants are needed to sign afterwards
+//
+// the threshold cannot be lowered this way, the old shares f(j) stay on a polynomial of degree t
+// participants outside newParticipants receive no correction, their old shares no longer match the group
+func (p *FrostParticipant) Reshare(newThreshold int64, newParticipants []int64) (*ReshareTranscript, error) {
+	if newThreshold < p.Threshold || newThreshold >= int64(len(newParticipants)) {
+		return nil, fmt.Errorf("%w: threshold %d to %d with %d participants", ErrInvalidReshareParameters, p.Threshold, newThreshold, len(newParticipants))
+	}
+	seen := make(map[int64]bool, len(newParticipants))
+	for _, posi := range newParticipants {
+		if err := p.validateIndex(posi); err != nil || seen[posi] {
+			return nil, fmt.Errorf("%w: participant %d", ErrInvalidReshareParameters, posi)
+		}
+		seen[posi] = true
+	}
+
+	// the refresh polynomial of degree t', the refresh commitments of this participant are left untouched
+	transcript := &ReshareTranscript{
+		Dealer:       p.Position,
+		Threshold:    newThreshold,
+		Participants: append([]int64{}, newParticipants...),
+		Commitments:  p.generateRefreshPolynomial(newThreshold),
+		Shares:       make(map[int64]*btcec.ModNScalar, len(newParticipants)),
+	}
+	for _, posi := range newParticipants {
+		transcript.Shares[posi] = p.RefreshShare(posi)
+	}
+
+	return transcript, nil
+}
+
+// verify the correction share of every transcript, g^\delta_i(j) = \sum_{k=1}^{t'} j^k * B_ik, then s_j' = s_j + \sum_{i} \delta_i(j)
+// the dealers of the transcripts form the dealer set D of ApplyRefreshShares
+// the signing share and the threshold are only updated when all shares verify
+// RefreshPublicSigningShares(ReshareDealers(transcripts)) then updates the public signing shares of a participant holding the Q map
+func (p *FrostParticipant) ApplyReshare(transcripts []*ReshareTranscript) error {
+	if len(transcripts) == 0 {
+		return ErrReshareTranscriptMismatch
+	}
+	threshold := transcripts[0].Threshold
+	participants := transcripts[0].Participants
+	dealers := make([]int64, 0, len(transcripts))
+	commitments := make(map[int64][]*btcec.PublicKey, len(transcripts))
+	shares := make(map[int64]*btcec.ModNScalar, len(transcripts))
+	for _, transcript := range transcripts {
+		if transcript.Threshold != threshold || !equalPositions(transcript.Participants, participants) {
+			return fmt.Errorf("%w: dealer %d", ErrReshareTranscriptMismatch, transcript.Dealer)
+		}
+		share, ok := transcript.Shares[p.Position]
+		if !ok {
+			return fmt.Errorf("%w: dealer %d", ErrMissingRefreshCommitments, transcript.Dealer)
+		}
+		dealers = append(dealers, transcript.Dealer)
+		commitments[transcript.Dealer] = transcript.Commitments
+		shares[transcript.Dealer] = share
+	}
+	if err := p.applyRefreshShares(threshold, dealers, commitments, shares); err != nil {
+		return err
+	}
+
+	p.Threshold = threshold
+	for dealer, dealer_commitments := range commitments {
+		p.storeRefreshCommitments(dealer, dealer_commitments)
+	}
+
+	return nil
+}
+
+// the dealer set of a reshare, passed to RefreshPublicSigningShares after ApplyReshare
+func ReshareDealers(transcripts []*ReshareTranscript) []int64 {
+	dealers := make([]int64, len(transcripts))
+	for k, transcript := range transcripts {
+		dealers[k] = transcript.Dealer
+	}
+
+	return dealers
+}
+
+func equalPositions(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
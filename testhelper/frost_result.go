@@ -0,0 +1,51 @@
+package testhelper
+
+import (
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// DKGResult is the public output of a DKG as seen by a participant
+type DKGResult struct {
+	GroupPublicKey      *btcec.PublicKey
+	PublicSigningShares map[int64]*btcec.PublicKey
+}
+
+// errors when the public signing share of any of the n participants is missing
+func (p *FrostParticipant) DKGResult() (*DKGResult, error) {
+	shares, err := p.allPublicSigningShares()
+	if err != nil {
+		return nil, fmt.Errorf("dkg result: %w", err)
+	}
+
+	result := &DKGResult{
+		GroupPublicKey:      p.GroupPublicKey,
+		PublicSigningShares: make(map[int64]*btcec.PublicKey, p.N),
+	}
+	for i, share := range shares {
+		result.PublicSigningShares[int64(i+1)] = share
+	}
+
+	return result, nil
+}
+
+// two DKG runs are equivalent when they derive the same group public key
+// and the same public signing share for every participant index
+// runs are only reproducible from suites injected with the same seeded source, see SetTestRandSource
+func DKGOutputsEquivalent(a, b *DKGResult) bool {
+	if a.GroupPublicKey == nil || b.GroupPublicKey == nil || !a.GroupPublicKey.IsEqual(b.GroupPublicKey) {
+		return false
+	}
+	if len(a.PublicSigningShares) != len(b.PublicSigningShares) {
+		return false
+	}
+	for posi, share_a := range a.PublicSigningShares {
+		share_b, ok := b.PublicSigningShares[posi]
+		if !ok || share_a == nil || share_b == nil || !share_a.IsEqual(share_b) {
+			return false
+		}
+	}
+
+	return true
+}
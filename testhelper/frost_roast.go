@@ -0,0 +1,340 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var (
+	ErrRoastNotEnoughSigners = errors.New("roast: not enough signers left to reach the threshold")
+	ErrRoastTimeout          = errors.New("roast: no signing session completed in time")
+	ErrRoastSignerFailed     = errors.New("roast: signer failed to answer")
+	ErrRoastSessionNonce     = errors.New("roast: session nonce commitment does not match the nonces of the signers")
+)
+
+// RoastSigner is a signer as seen by the ROAST coordinator
+// an unresponsive signer simply never returns from Sign, the coordinator never waits on it
+type RoastSigner interface {
+	Position() int64
+	// fresh nonce commitment (D_i, E_i) for the next session over msg
+	Commit(msg [32]byte) (*NonceCommitment, error)
+	// signature share z_i of the session, followed by a fresh nonce commitment for the next session
+	Sign(session *RoastSession) (*btcec.ModNScalar, *NonceCommitment, error)
+}
+
+// RoastSession is a FROST signing session started by the coordinator over t + 1 responsive signers
+// R is announced by the coordinator, each signer recomputes \sum_{i \in S} R_i from Nonces and refuses any other R
+type RoastSession struct {
+	ID      int64
+	Message [32]byte
+	Signers []int64
+	Nonces  map[int64][2]*btcec.PublicKey
+	R       *btcec.JacobianPoint
+
+	nonce_commitments map[int64]*btcec.JacobianPoint
+	c                 *btcec.ModNScalar
+	lambdas           map[int64]*btcec.ModNScalar
+	shares            map[int64]*btcec.ModNScalar
+}
+
+// ROAST, robust asynchronous Schnorr threshold signatures (Ruffing et al. 2022), wrapped around FROST
+// the coordinator keeps the set of responsive signers, those that returned a fresh nonce commitment and are not in a running session
+// as soon as t + 1 signers are responsive, a new session is started with them
+// sessions stalled by unresponsive signers are never cancelled, their responsive signers come back with a new commitment and join later sessions
+// a signer sending an invalid share is marked malicious and never selected again
+//
+// since every unresponsive signer can stall at most one session, at most n - t sessions are started
+// before one of them consists of responsive honest signers only
+type RoastCoordinator struct {
+	suite *TestSuite
+	// holds the group public key and the public signing shares Y_i of every signer
+	Frost   *FrostParticipant
+	Timeout time.Duration
+	// number of sessions started by the last Sign
+	Sessions int64
+
+	malicious map[int64]bool
+}
+
+type roastResponse struct {
+	posi       int64
+	session_id int64
+	share      *btcec.ModNScalar
+	next       *NonceCommitment
+	err        error
+}
+
+func NewRoastCoordinator(suite *TestSuite, frost *FrostParticipant) *RoastCoordinator {
+	return &RoastCoordinator{
+		suite:     suite,
+		Frost:     frost,
+		Timeout:   5 * time.Minute,
+		malicious: make(map[int64]bool),
+	}
+}
+
+// run ROAST over the signer pool until a session of threshold + 1 signers completes
+// the returned signature is verified under the group public key
+func (c *RoastCoordinator) Sign(msg [32]byte, pool []RoastSigner, threshold int64) (*schnorr.Signature, error) {
+	signers := make(map[int64]RoastSigner, len(pool))
+	for _, signer := range pool {
+		signers[signer.Position()] = signer
+	}
+	if int64(len(signers)) <= threshold {
+		return nil, ErrRoastNotEnoughSigners
+	}
+	c.Sessions = 0
+	c.malicious = make(map[int64]bool)
+
+	// responses of abandoned sessions must not block their signers, thus the channel holds one answer per signer
+	responses := make(chan roastResponse, len(signers))
+	for _, signer := range signers {
+		go func(signer RoastSigner) {
+			next, err := signer.Commit(msg)
+			responses <- roastResponse{posi: signer.Position(), session_id: -1, next: next, err: err}
+		}(signer)
+	}
+
+	// responsive signers in order of their answer, with their latest nonce commitment
+	responsive := make([]int64, 0, len(signers))
+	latest := make(map[int64]*NonceCommitment, len(signers))
+	sessions := make(map[int64]*RoastSession)
+	timeout := time.After(c.Timeout)
+	for {
+		var response roastResponse
+		select {
+		case response = <-responses:
+		case <-timeout:
+			return nil, fmt.Errorf("%w: %d sessions started", ErrRoastTimeout, c.Sessions)
+		}
+
+		posi := response.posi
+		if response.err != nil {
+			c.Frost.logger.Printf("roast: signer %d: %v", posi, response.err)
+			c.malicious[posi] = true
+		} else if session, ok := sessions[response.session_id]; ok {
+			if !c.verifySignatureShare(session, posi, response.share) {
+				c.Frost.logger.Printf("roast: signer %d: invalid share in session %d", posi, session.ID)
+				c.malicious[posi] = true
+			} else {
+				session.shares[posi] = response.share
+				if len(session.shares) == len(session.Signers) {
+					return c.complete(session)
+				}
+			}
+		}
+		if c.malicious[posi] {
+			if int64(len(signers)-len(c.malicious)) <= threshold {
+				return nil, fmt.Errorf("%w: %d malicious signers", ErrRoastNotEnoughSigners, len(c.malicious))
+			}
+			continue
+		}
+		if response.next == nil || response.next.D == nil || response.next.E == nil {
+			c.malicious[posi] = true
+			continue
+		}
+		responsive = append(responsive, posi)
+		latest[posi] = response.next
+
+		if int64(len(responsive)) > threshold {
+			selected := responsive[:threshold+1]
+			responsive = append([]int64{}, responsive[threshold+1:]...)
+			session, err := c.startSession(msg, selected, latest)
+			if err != nil {
+				return nil, err
+			}
+			sessions[session.ID] = session
+			for _, posi := range session.Signers {
+				go func(signer RoastSigner) {
+					share, next, err := signer.Sign(session)
+					responses <- roastResponse{posi: signer.Position(), session_id: session.ID, share: share, next: next, err: err}
+				}(signers[posi])
+			}
+		}
+	}
+}
+
+// R_i = D_i + p_i * E_i, R = \sum_{i \in S} R_i, c = H(R, Y, m)
+func (c *RoastCoordinator) startSession(msg [32]byte, selected []int64, latest map[int64]*NonceCommitment) (*RoastSession, error) {
+	signer_set := make(map[int64]bool, len(selected))
+	for _, posi := range selected {
+		signer_set[posi] = true
+	}
+	session := &RoastSession{
+		ID:                c.Sessions,
+		Message:           msg,
+		Signers:           signerSet(signer_set),
+		Nonces:            make(map[int64][2]*btcec.PublicKey, len(selected)),
+		nonce_commitments: make(map[int64]*btcec.JacobianPoint, len(selected)),
+		shares:            make(map[int64]*btcec.ModNScalar, len(selected)),
+	}
+	c.Sessions++
+	for _, posi := range session.Signers {
+		session.Nonces[posi] = [2]*btcec.PublicKey{latest[posi].D, latest[posi].E}
+	}
+
+	nonce_commitments := make(map[int64]*btcec.PublicKey, len(session.Signers))
+	for _, posi := range session.Signers {
+		D_i := new(btcec.JacobianPoint)
+		session.Nonces[posi][0].AsJacobian(D_i)
+		E_i := new(btcec.JacobianPoint)
+		session.Nonces[posi][1].AsJacobian(E_i)
+
+		R_i := new(btcec.JacobianPoint)
+		c.suite.scalarMult(bindingFactor(posi, msg, session.Signers, session.Nonces), E_i, R_i)
+		c.suite.addPoints(D_i, R_i, R_i)
+		R_i.ToAffine()
+		session.nonce_commitments[posi] = R_i
+		nonce_commitments[posi] = btcec.NewPublicKey(&R_i.X, &R_i.Y)
+	}
+	R, err := AggregateNonceCommitments(nonce_commitments)
+	if err != nil {
+		return nil, err
+	}
+	session.R = R
+
+	session.c = bip340Challenge(R, c.Frost.GroupPublicKey, msg)
+	session.lambdas = c.suite.CalculateLagrangeCoeffs(session.Signers)
+
+	return session, nil
+}
+
+// g^z_i = R_i * Y_i^(\lambda_i * c), with the same negations as AggregateSignatureShares
+func (c *RoastCoordinator) verifySignatureShare(session *RoastSession, posi int64, share *btcec.ModNScalar) bool {
+	value, ok := c.Frost.PublicSigningShares.Load(posi)
+	if !ok || share == nil {
+		return false
+	}
+	R_i := new(btcec.JacobianPoint)
+	R_i.Set(session.nonce_commitments[posi])
+	if session.R.Y.IsOdd() {
+		R_i = negatePoint(R_i)
+	}
+	Y_i := new(btcec.JacobianPoint)
+	value.(*btcec.PublicKey).AsJacobian(Y_i)
+	if c.Frost.GroupPublicKey.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd {
+		Y_i = negatePoint(Y_i)
+	}
+	expected := new(btcec.JacobianPoint)
+	c.suite.scalarMult(new(btcec.ModNScalar).Mul2(session.lambdas[posi], session.c), Y_i, expected)
+	c.suite.addPoints(R_i, expected, expected)
+
+	actual := new(btcec.JacobianPoint)
+	c.suite.scalarBaseMult(share, actual)
+
+	return equalPoints(actual, expected)
+}
+
+// (R, z), z = \sum_{i \in S} z_i
+func (c *RoastCoordinator) complete(session *RoastSession) (*schnorr.Signature, error) {
+	z := new(btcec.ModNScalar)
+	for _, z_i := range session.shares {
+		z.Add(z_i)
+	}
+	sig := schnorr.NewSignature(&session.R.X, z)
+	if !sig.Verify(session.Message[:], c.Frost.GroupPublicKey) {
+		return nil, ErrInvalidAggregatedSignature
+	}
+
+	return sig, nil
+}
+
+// Malicious reports whether the signer sent an invalid share or failed to answer during the last Sign
+func (c *RoastCoordinator) Malicious(posi int64) bool {
+	return c.malicious[posi]
+}
+
+// FrostRoastSigner answers the ROAST coordinator with a FROST participant holding its signing share
+type FrostRoastSigner struct {
+	Frost *FrostParticipant
+
+	signing_index int64
+}
+
+func NewFrostRoastSigner(p *FrostParticipant) *FrostRoastSigner {
+	return &FrostRoastSigner{Frost: p, signing_index: -1}
+}
+
+func (s *FrostRoastSigner) Position() int64 {
+	return s.Frost.Position
+}
+
+// a signer is in at most one session at a time, thus a single pending nonce pair is enough
+func (s *FrostRoastSigner) Commit(msg [32]byte) (*NonceCommitment, error) {
+	signing_index, nonce_commitments := s.Frost.PreprocessNoncesForSighash(msg)
+	s.signing_index = signing_index
+
+	return &NonceCommitment{D: nonce_commitments[0], E: nonce_commitments[1]}, nil
+}
+
+// z_i = d_i + e_i * p_i + \lambda_i * s_i * c over the R of the session
+func (s *FrostRoastSigner) Sign(session *RoastSession) (*btcec.ModNScalar, *NonceCommitment, error) {
+	p := s.Frost
+	if s.signing_index < 0 {
+		return nil, nil, fmt.Errorf("%w: %d has no pending nonce", ErrRoastSignerFailed, p.Position)
+	}
+	nonces, ok := session.Nonces[p.Position]
+	if !ok || !nonces[0].IsEqual(p.NonceCommitments[s.signing_index][0]) || !nonces[1].IsEqual(p.NonceCommitments[s.signing_index][1]) {
+		return nil, nil, fmt.Errorf("%w: %d is not signing with its pending nonce", ErrRoastSignerFailed, p.Position)
+	}
+
+	// a coordinator choosing R would choose c, thus R is derived from the nonces of the signers
+	for _, posi := range session.Signers {
+		if nonces, ok := session.Nonces[posi]; !ok || nonces[0] == nil || nonces[1] == nil {
+			return nil, nil, fmt.Errorf("signer %d: %w", posi, ErrMissingNonceCommitment)
+		}
+	}
+	R, err := sessionNonceCommitment(p.suite, session.Signers, session.Message, session.Nonces)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.R == nil || !equalPoints(R, session.R) {
+		return nil, nil, fmt.Errorf("%w: session %d", ErrRoastSessionNonce, session.ID)
+	}
+	p.sessions_mu.Lock()
+	p.AggrNonceCommitment[s.signing_index] = R
+	p.sessions_mu.Unlock()
+	partial_sig := p.PartialSign(p.Position, s.signing_index, session.Signers, session.Message, session.Nonces, p.GetSigningShares())
+	if partial_sig == nil {
+		return nil, nil, fmt.Errorf("%w: %d", ErrRoastSignerFailed, p.Position)
+	}
+	z_i := new(btcec.ModNScalar)
+	z_i.SetByteSlice(partial_sig.Serialize()[32:64])
+
+	next, err := s.Commit(session.Message)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return z_i, next, nil
+}
+
+// R = \sum_{i \in S} D_i + p_i * E_i, as CalculatePublicNonceCommitments but with a single multi scalar multiplication
+// the R_i are not needed by a signer, thus the 2 |S| terms are summed at once
+func sessionNonceCommitment(suite *TestSuite, signers []int64, msg [32]byte, public_nonces map[int64][2]*btcec.PublicKey) (*btcec.JacobianPoint, error) {
+	scalars := make([]*btcec.ModNScalar, 0, 2*len(signers))
+	points := make([]*btcec.JacobianPoint, 0, 2*len(signers))
+	for _, posi := range signers {
+		D_i := new(btcec.JacobianPoint)
+		public_nonces[posi][0].AsJacobian(D_i)
+		E_i := new(btcec.JacobianPoint)
+		public_nonces[posi][1].AsJacobian(E_i)
+		scalars = append(scalars, new(btcec.ModNScalar).SetInt(1), bindingFactor(posi, msg, signers, public_nonces))
+		points = append(points, D_i, E_i)
+	}
+	R := new(btcec.JacobianPoint)
+	if err := suite.multiScalarMul(scalars, points, R); err != nil {
+		return nil, err
+	}
+	if (R.X.IsZero() && R.Y.IsZero()) || R.Z.IsZero() {
+		return nil, ErrInvalidNonce
+	}
+	R.ToAffine()
+
+	return R, nil
+}
@@ -0,0 +1,94 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+var (
+	ErrSecretReleased        = errors.New("secure scalar: secret already released")
+	ErrReconstructedKeyWrong = errors.New("reconstruct secret: secret does not match the group public key")
+)
+
+// SecureScalar holds a secret scalar, e.g. the reconstructed group secret, in memory locked against swapping
+// Release zeroizes the scalar and unlocks its memory, the secret must not be copied out of Scalar
+// locking is best effort, it fails without privileges above RLIMIT_MEMLOCK
+type SecureScalar struct {
+	scalar *btcec.ModNScalar
+	locked bool
+}
+
+func newSecureScalar() *SecureScalar {
+	secure := &SecureScalar{scalar: new(btcec.ModNScalar)}
+	secure.locked = syscall.Mlock(secure.bytes()) == nil
+
+	return secure
+}
+
+// the secret, nil once released
+func (s *SecureScalar) Scalar() *btcec.ModNScalar {
+	return s.scalar
+}
+
+// whether the memory of the scalar is locked
+func (s *SecureScalar) Locked() bool {
+	return s.locked
+}
+
+// zeroize the scalar in place, then unlock its memory
+// the pointer returned by Scalar keeps reading zero afterward
+func (s *SecureScalar) Release() error {
+	if s.scalar == nil {
+		return ErrSecretReleased
+	}
+	s.scalar.Zero()
+	var err error
+	if s.locked {
+		err = syscall.Munlock(s.bytes())
+		s.locked = false
+	}
+	s.scalar = nil
+
+	return err
+}
+
+// the memory backing the scalar
+func (s *SecureScalar) bytes() []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(s.scalar)), unsafe.Sizeof(*s.scalar))
+}
+
+// s = \sum_{i \in S} \lambda_i * s_i over at least threshold + 1 signing shares, for recovery or migration
+// s * G is checked against the group public key, the secret is only ever written into the secure buffer
+func (p *FrostParticipant) ReconstructSecret(signing_shares map[int64]*btcec.ModNScalar) (*SecureScalar, error) {
+	if int64(len(signing_shares)) <= p.Threshold {
+		return nil, fmt.Errorf("reconstruct secret: %w", ErrThresholdNotMet)
+	}
+	positions := make(map[int64]bool, len(signing_shares))
+	for posi := range signing_shares {
+		positions[posi] = true
+	}
+	set := signerSet(positions)
+	lambdas := p.suite.CalculateLagrangeCoeffs(set)
+
+	secret := newSecureScalar()
+	term := new(btcec.ModNScalar)
+	for _, posi := range set {
+		term.Mul2(lambdas[posi], signing_shares[posi])
+		secret.scalar.Add(term)
+	}
+	term.Zero()
+
+	Y := new(btcec.JacobianPoint)
+	p.suite.scalarBaseMult(secret.scalar, Y)
+	Y.ToAffine()
+	if p.GroupPublicKey == nil || !btcec.NewPublicKey(&Y.X, &Y.Y).IsEqual(p.GroupPublicKey) {
+		secret.Release()
+		return nil, ErrReconstructedKeyWrong
+	}
+
+	return secret, nil
+}
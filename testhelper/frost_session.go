@@ -0,0 +1,130 @@
+package testhelper
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+var (
+	TagFROSTSessionFingerprint = []byte("FROST/session-fingerprint")
+
+	ErrTooManySessions      = errors.New("begin signing session: too many active sessions")
+	ErrSessionAlreadyActive = errors.New("begin signing session: session already active")
+)
+
+// SessionState is the progress of a signing session, as seen by the signer
+type SessionState string
+
+const (
+	// no nonce pair generated yet for the signing index
+	SessionPreprocessing SessionState = "preprocessing"
+	// the nonce commitments are published, the partial signature is not produced yet
+	SessionAwaitingCommitments SessionState = "awaiting-commitments"
+	// a partial signature was produced with the nonce pair
+	SessionSigned SessionState = "signed"
+)
+
+// SessionInfo describes an active signing session, ID is its signing index
+type SessionInfo struct {
+	ID    int64
+	State SessionState
+	Age   time.Duration
+}
+
+// limit the number of signing sessions a signer keeps open at the same time
+// each session holds secret nonces, thus unbounded sessions can exhaust signer resources
+//
+// n <= 0 means no limit
+func (p *FrostParticipant) SetMaxConcurrentSessions(n int) {
+	p.sessions_mu.Lock()
+	defer p.sessions_mu.Unlock()
+
+	p.max_concurrent_sessions = n
+}
+
+// open a signing session for the signing index
+func (p *FrostParticipant) BeginSigningSession(signing_index int64) error {
+	p.sessions_mu.Lock()
+	defer p.sessions_mu.Unlock()
+
+	if _, ok := p.active_sessions[signing_index]; ok {
+		return ErrSessionAlreadyActive
+	}
+	if p.max_concurrent_sessions > 0 && len(p.active_sessions) >= p.max_concurrent_sessions {
+		return ErrTooManySessions
+	}
+	p.active_sessions[signing_index] = time.Now()
+
+	return nil
+}
+
+// close the signing session for the signing index, freeing a slot for a new session
+func (p *FrostParticipant) EndSigningSession(signing_index int64) {
+	p.sessions_mu.Lock()
+	defer p.sessions_mu.Unlock()
+
+	delete(p.active_sessions, signing_index)
+}
+
+// active signing sessions ordered by signing index, for operator introspection
+// no secret nonce is exposed
+func (p *FrostParticipant) ListSessions() []SessionInfo {
+	p.sessions_mu.RLock()
+	defer p.sessions_mu.RUnlock()
+
+	now := time.Now()
+	sessions := make([]SessionInfo, 0, len(p.active_sessions))
+	for signing_index, started := range p.active_sessions {
+		sessions = append(sessions, SessionInfo{
+			ID:    signing_index,
+			State: p.sessionState(signing_index),
+			Age:   now.Sub(started),
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].ID < sessions[j].ID })
+
+	return sessions
+}
+
+func (p *FrostParticipant) sessionState(signing_index int64) SessionState {
+	if signing_index < 0 || signing_index >= int64(len(p.nonces)) || signing_index >= int64(len(p.NonceCommitments)) {
+		return SessionPreprocessing
+	}
+	// a used commitment never signs again, whether it signed under this index or was replayed from another
+	if _, ok := p.nonce_history[p.nonceCommitmentHash(signing_index)]; ok {
+		return SessionSigned
+	}
+
+	return SessionAwaitingCommitments
+}
+
+// short fingerprint of a signing session for correlating logs across machines
+// fingerprint = H(Y || session id || m || sorted signers)[:8] in hex
+// all signers in the same session produce the same fingerprint, it reveals nothing secret
+func (p *FrostParticipant) SessionFingerprint(sessionID [32]byte, msg [32]byte, signers map[int64]bool) string {
+	signer_set := make([]int64, 0, len(signers))
+	for posi, ok := range signers {
+		if ok {
+			signer_set = append(signer_set, posi)
+		}
+	}
+	sort.Slice(signer_set, func(i, j int) bool { return signer_set[i] < signer_set[j] })
+
+	fingerprint_data := make([]byte, 0)
+	if p.GroupPublicKey != nil {
+		fingerprint_data = append(fingerprint_data, schnorr.SerializePubKey(p.GroupPublicKey)...)
+	}
+	fingerprint_data = append(fingerprint_data, sessionID[:]...)
+	fingerprint_data = append(fingerprint_data, msg[:]...)
+	for _, posi := range signer_set {
+		fingerprint_data = append(fingerprint_data, Int64ToBytes(posi)...)
+	}
+	fingerprint_hash := chainhash.TaggedHash(TagFROSTSessionFingerprint, fingerprint_data)
+
+	return hex.EncodeToString(fingerprint_hash[:8])
+}
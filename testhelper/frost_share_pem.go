@@ -0,0 +1,120 @@
+package testhelper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"strconv"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	SharePEMType = "FROST SIGNING SHARE"
+
+	sharePEMKDF        = "PBKDF2-HMAC-SHA256"
+	sharePEMIterations = 100000
+	// an imported block may not weaken the key derivation below the export default,
+	// nor make the import spin on an attacker chosen count
+	sharePEMMinIterations = sharePEMIterations
+	sharePEMMaxIterations = 10000000
+)
+
+var (
+	ErrMissingSigningShare = errors.New("export share pem: signing share not stored")
+	ErrInvalidSharePEM     = errors.New("import share pem: invalid pem block")
+	ErrWrongPassphrase     = errors.New("import share pem: wrong passphrase or corrupted block")
+)
+
+// PEM block of type FROST SIGNING SHARE, the body is AES-256-GCM(position || s_i)
+// the key is derived from the passphrase with PBKDF2-HMAC-SHA256, salt and nonce are stored in the headers
+// salt and nonce are always read from crypto/rand, never from a seeded test source
+func (p *FrostParticipant) ExportSharePEM(passphrase []byte) ([]byte, error) {
+	if p.signingShares == nil {
+		return nil, ErrMissingSigningShare
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := sharePEMCipher(passphrase, salt, sharePEMIterations)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	share_bytes := p.signingShares.Bytes()
+	plaintext := append(Int64ToBytes(p.Position), share_bytes[:]...)
+	block := &pem.Block{
+		Type: SharePEMType,
+		Headers: map[string]string{
+			"KDF":        sharePEMKDF,
+			"Iterations": strconv.Itoa(sharePEMIterations),
+			"Salt":       hex.EncodeToString(salt),
+			"Nonce":      hex.EncodeToString(nonce),
+		},
+		Bytes: aead.Seal(nil, nonce, plaintext, []byte(SharePEMType)),
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// decrypt a block produced by ExportSharePEM, returning the position and the signing share
+func ImportSharePEM(data, passphrase []byte) (int64, *btcec.ModNScalar, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != SharePEMType || block.Headers["KDF"] != sharePEMKDF {
+		return 0, nil, ErrInvalidSharePEM
+	}
+	iterations, err := strconv.Atoi(block.Headers["Iterations"])
+	if err != nil || iterations < sharePEMMinIterations || iterations > sharePEMMaxIterations {
+		return 0, nil, ErrInvalidSharePEM
+	}
+	salt, err := hex.DecodeString(block.Headers["Salt"])
+	if err != nil {
+		return 0, nil, ErrInvalidSharePEM
+	}
+	nonce, err := hex.DecodeString(block.Headers["Nonce"])
+	if err != nil {
+		return 0, nil, ErrInvalidSharePEM
+	}
+
+	aead, err := sharePEMCipher(passphrase, salt, iterations)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return 0, nil, ErrInvalidSharePEM
+	}
+	plaintext, err := aead.Open(nil, nonce, block.Bytes, []byte(SharePEMType))
+	if err != nil {
+		return 0, nil, ErrWrongPassphrase
+	}
+	if len(plaintext) != 8+32 {
+		return 0, nil, ErrInvalidSharePEM
+	}
+
+	share := new(btcec.ModNScalar)
+	if overflow := share.SetByteSlice(plaintext[8:]); overflow {
+		return 0, nil, ErrInvalidSharePEM
+	}
+
+	return BytesToInt64(plaintext[:8]), share, nil
+}
+
+func sharePEMCipher(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2.Key(passphrase, salt, iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
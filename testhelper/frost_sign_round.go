@@ -0,0 +1,133 @@
+package testhelper
+
+import (
+	"fmt"
+	"sort"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/stretchr/testify/assert"
+)
+
+// SignRound is a single two round FROST signing session of a participant over one message
+// round 1: GenerateSigningNonces, then the (D_i, E_i) of every signer is broadcast
+// round 2: ComputeSignatureShare, the aggregator sums the shares with Aggregate
+//
+// the result is a BIP340 signature under the group public key Y
+type SignRound struct {
+	Frost *FrostParticipant
+
+	signing_index int64
+	message_hash  [32]byte
+	// (D_j, E_j) of every signer, keyed by position
+	commitments map[int64][2]*btcec.PublicKey
+}
+
+func (p *FrostParticipant) NewSignRound() *SignRound {
+	return &SignRound{
+		Frost:         p,
+		signing_index: -1,
+		commitments:   make(map[int64][2]*btcec.PublicKey),
+	}
+}
+
+// (D_i, E_i) = (d_i * G, e_i * G), the nonce pair is bound to msg
+func (r *SignRound) GenerateSigningNonces(msg [32]byte) (D, E *btcec.PublicKey) {
+	signing_index, nonce_commitments := r.Frost.PreprocessNoncesForSighash(msg)
+	r.signing_index = signing_index
+	r.message_hash = msg
+	r.commitments[r.Frost.Position] = nonce_commitments
+
+	return nonce_commitments[0], nonce_commitments[1]
+}
+
+// store the nonce commitments broadcast by another signer
+func (r *SignRound) ReceiveCommitments(posi int64, D, E *btcec.PublicKey) {
+	r.commitments[posi] = [2]*btcec.PublicKey{D, E}
+}
+
+// p_i = H(i, m, B), B = {D_1, E_1, ..., D_t, E_t} is the commitment list of the signers
+func (r *SignRound) ComputeBindingFactor(posi int64, signers map[int64]bool) *btcec.ModNScalar {
+	return bindingFactor(posi, r.message_hash, signerSet(signers), r.commitments)
+}
+
+// z_i = d_i + e_i * p_i + \lambda_i * s_i * c, c = H(R, Y, m)
+// BIP340 requires even Y coordinates for R and Y
+// d_i and e_i are negated when R has odd Y coordinate, s_i is negated when Y has odd Y coordinate
+func (r *SignRound) ComputeSignatureShare(msg [32]byte, signers map[int64]bool) *btcec.ModNScalar {
+	assert.True(r.suite().T, r.signing_index >= 0, "compute signature share: signing nonces not generated")
+	assert.Equal(r.suite().T, r.message_hash, msg, "compute signature share: nonces are bound to a different message")
+	honest := signerSet(signers)
+	for _, posi := range honest {
+		_, ok := r.commitments[posi]
+		assert.True(r.suite().T, ok, "compute signature share: missing commitments of signer %d", posi)
+	}
+
+	_, err := r.Frost.CalculatePublicNonceCommitments(r.signing_index, honest, msg, r.commitments)
+	if !assert.NoError(r.suite().T, err) {
+		return nil
+	}
+	partial_sig := r.Frost.PartialSign(r.Frost.Position, r.signing_index, honest, msg, r.commitments, r.Frost.GetSigningShares())
+	if partial_sig == nil {
+		return nil
+	}
+
+	z_i := new(btcec.ModNScalar)
+	z_i.SetByteSlice(partial_sig.Serialize()[32:64])
+
+	return z_i
+}
+
+// (R, z), z = \sum_{i \in S} z_i
+// ComputeSignatureShare must be called before to derive R
+func (r *SignRound) Aggregate(shares map[int64]*btcec.ModNScalar) (*schnorr.Signature, error) {
+	R, ok := r.Frost.aggrNonceCommitment(r.signing_index)
+	if !ok || R == nil {
+		return nil, fmt.Errorf("%w: sign round aggregate, signing index %d", ErrMissingAggrNonce, r.signing_index)
+	}
+
+	z := new(btcec.ModNScalar)
+	for _, z_i := range shares {
+		z.Add(z_i)
+	}
+
+	return schnorr.NewSignature(&R.X, z), nil
+}
+
+func (r *SignRound) suite() *TestSuite {
+	return r.Frost.suite
+}
+
+// p_i = H(i, m, B), B = {D_1, E_1, ..., D_t, E_t} ordered as honest
+func bindingFactor(i int64, message_hash [32]byte, honest []int64, public_nonces map[int64][2]*btcec.PublicKey) *btcec.ModNScalar {
+	p_i_data := make([]byte, 0)
+	p_i_data = append(p_i_data, byte(i))
+	p_i_data = append(p_i_data, message_hash[:]...)
+	for _, j := range honest {
+		D := new(btcec.JacobianPoint)
+		public_nonces[j][0].AsJacobian(D)
+		E := new(btcec.JacobianPoint)
+		public_nonces[j][1].AsJacobian(E)
+		p_i_data = append(p_i_data, D.X.Bytes()[:]...)
+		p_i_data = append(p_i_data, E.X.Bytes()[:]...)
+	}
+	p_i := chainhash.HashB(p_i_data)
+	p_i_scalar := new(btcec.ModNScalar)
+	p_i_scalar.SetByteSlice(p_i)
+
+	return p_i_scalar
+}
+
+// sorted positions of the signers
+func signerSet(signers map[int64]bool) []int64 {
+	set := make([]int64, 0, len(signers))
+	for posi, ok := range signers {
+		if ok {
+			set = append(set, posi)
+		}
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i] < set[j] })
+
+	return set
+}
@@ -0,0 +1,179 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var (
+	ErrMissingNonceCommitment     = errors.New("aggregate signature shares: missing nonce commitment")
+	ErrMissingPublicSigningShare  = errors.New("aggregate signature shares: missing public signing share")
+	ErrInvalidSignatureShare      = errors.New("aggregate signature shares: invalid signature share")
+	ErrInvalidAggregatedSignature = errors.New("verify aggregated signature: signature does not verify under the group public key")
+)
+
+// NonceCommitment is the public nonce pair (D_i, E_i) published by a signer before signing
+type NonceCommitment struct {
+	D *btcec.PublicKey
+	E *btcec.PublicKey
+}
+
+// ErrNonceReuse is returned when a signer presents the same nonce commitment in two distinct sessions
+// a session is the message with the signer set and the commitment list, a change of any of them changes the binding factors,
+// the two signature shares would reveal its signing share, thus the session is rejected before any share is requested
+type ErrNonceReuse struct {
+	Position int64
+}
+
+func (e *ErrNonceReuse) Error() string {
+	return fmt.Sprintf("frost aggregator: signer %d reused a nonce commitment in another session", e.Position)
+}
+
+func (e *ErrNonceReuse) Unwrap() error {
+	return ErrNonceReused
+}
+
+// record the nonce commitments of a session over msg, keyed by signer position and H(D || E)
+// the session binding is H(m || signers || commitments), the signers are the positions of nonces
+// a commitment already recorded under another binding is rejected with ErrNonceReuse, nothing is recorded then
+// only the identical session is accepted again, e.g. a retried request for the same shares
+func (a *FrostAggregator) TrackNonceCommitments(msg [32]byte, nonces map[int64]*NonceCommitment) error {
+	positions := make(map[int64]bool, len(nonces))
+	public_nonces := make(map[int64][2]*btcec.PublicKey, len(nonces))
+	for posi, nonce := range nonces {
+		if nonce == nil || nonce.D == nil || nonce.E == nil {
+			return fmt.Errorf("signer %d: %w", posi, ErrMissingNonceCommitment)
+		}
+		positions[posi] = true
+		public_nonces[posi] = [2]*btcec.PublicKey{nonce.D, nonce.E}
+	}
+	signers := signerSet(positions)
+	binding := nonceSessionBinding(msg, signers, public_nonces)
+
+	keys := make(map[int64][32]byte, len(nonces))
+	for _, posi := range signers {
+		keys[posi] = nonceCommitmentKey(nonces[posi].D, nonces[posi].E)
+		if seen, ok := a.nonce_sessions[posi][keys[posi]]; ok && seen != binding {
+			return &ErrNonceReuse{Position: posi}
+		}
+	}
+
+	for posi, key := range keys {
+		if a.nonce_sessions[posi] == nil {
+			a.nonce_sessions[posi] = make(map[[32]byte][32]byte)
+		}
+		a.nonce_sessions[posi][key] = binding
+	}
+
+	return nil
+}
+
+// coordinator side aggregation of the signature shares z_i of the signer set S
+// R_i = D_i + p_i * E_i, R = \sum_{i \in S} R_i, c = H(R, Y, m)
+//
+// nonce commitments reused in another session are rejected first, see TrackNonceCommitments
+// each share is verified before aggregation, g^z_i = R_i * Y_i^(\lambda_i * c)
+// R_i is negated when R has odd Y coordinate, Y_i is negated when Y has odd Y coordinate
+// the error of an invalid share names the position of its signer
+func (a *FrostAggregator) AggregateSignatureShares(msg [32]byte, shares map[int64]*btcec.ModNScalar, nonces map[int64]*NonceCommitment) (*schnorr.Signature, error) {
+	if int64(len(shares)) <= a.Frost.Threshold {
+		return nil, ErrThresholdNotMet
+	}
+
+	// the session is the signers of shares, nonces of other positions are not part of it
+	signers := make(map[int64]bool, len(shares))
+	signer_nonces := make(map[int64]*NonceCommitment, len(shares))
+	public_nonces := make(map[int64][2]*btcec.PublicKey, len(shares))
+	for posi := range shares {
+		nonce, ok := nonces[posi]
+		if !ok || nonce == nil || nonce.D == nil || nonce.E == nil {
+			return nil, fmt.Errorf("signer %d: %w", posi, ErrMissingNonceCommitment)
+		}
+		signers[posi] = true
+		signer_nonces[posi] = nonce
+		public_nonces[posi] = [2]*btcec.PublicKey{nonce.D, nonce.E}
+	}
+	if err := a.TrackNonceCommitments(msg, signer_nonces); err != nil {
+		return nil, err
+	}
+	honest := signerSet(signers)
+
+	// R_i = D_i + p_i * E_i
+	nonce_commitments := make(map[int64]*btcec.PublicKey, len(honest))
+	for _, posi := range honest {
+		D_i := new(btcec.JacobianPoint)
+		public_nonces[posi][0].AsJacobian(D_i)
+		E_i := new(btcec.JacobianPoint)
+		public_nonces[posi][1].AsJacobian(E_i)
+
+		R_i := new(btcec.JacobianPoint)
+		a.suite.scalarMult(bindingFactor(posi, msg, honest, public_nonces), E_i, R_i)
+		a.suite.addPoints(D_i, R_i, R_i)
+		R_i.ToAffine()
+		nonce_commitments[posi] = btcec.NewPublicKey(&R_i.X, &R_i.Y)
+	}
+	R, err := AggregateNonceCommitments(nonce_commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	// c = H(R, Y, m)
+	c := bip340Challenge(R, a.Frost.GroupPublicKey, msg)
+
+	odd_group_key := a.Frost.GroupPublicKey.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd
+	lambdas := a.suite.CalculateLagrangeCoeffs(honest)
+	z := new(btcec.ModNScalar)
+	for _, posi := range honest {
+		value, ok := a.Frost.PublicSigningShares.Load(posi)
+		if !ok {
+			return nil, fmt.Errorf("signer %d: %w", posi, ErrMissingPublicSigningShare)
+		}
+		Y_i_pub := value.(*btcec.PublicKey)
+
+		// R_i * Y_i^(\lambda_i * c)
+		R_i := new(btcec.JacobianPoint)
+		nonce_commitments[posi].AsJacobian(R_i)
+		if R.Y.IsOdd() {
+			R_i = negatePoint(R_i)
+		}
+		Y_i := new(btcec.JacobianPoint)
+		Y_i_pub.AsJacobian(Y_i)
+		if odd_group_key {
+			Y_i = negatePoint(Y_i)
+		}
+		expected := new(btcec.JacobianPoint)
+		a.suite.scalarMult(new(btcec.ModNScalar).Mul2(lambdas[posi], c), Y_i, expected)
+		a.suite.addPoints(R_i, expected, expected)
+		expected.ToAffine()
+
+		// g^z_i
+		actual := new(btcec.JacobianPoint)
+		a.suite.scalarBaseMult(shares[posi], actual)
+		actual.ToAffine()
+
+		if !actual.X.Equals(&expected.X) || !actual.Y.Equals(&expected.Y) {
+			return nil, fmt.Errorf("signer %d: %w", posi, ErrInvalidSignatureShare)
+		}
+		z.Add(shares[posi])
+	}
+
+	sig := schnorr.NewSignature(&R.X, z)
+	if err := a.VerifyAggregatedSignature(msg, sig); err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// BIP340 verification of the aggregated signature under the group public key
+func (a *FrostAggregator) VerifyAggregatedSignature(msg [32]byte, sig *schnorr.Signature) error {
+	if sig == nil || a.Frost.GroupPublicKey == nil || !sig.Verify(msg[:], a.Frost.GroupPublicKey) {
+		return ErrInvalidAggregatedSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,401 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// version of the saved participant state, bumped on any layout change
+const FrostStateVersion uint16 = 1
+
+var (
+	frostStateMagic = []byte("FROSTDKG")
+
+	ErrInvalidStateMagic       = errors.New("load frost state: not a frost participant state")
+	ErrUnsupportedStateVersion = errors.New("load frost state: unsupported version")
+	ErrMalformedState          = errors.New("load frost state: malformed state")
+)
+
+// save the DKG state of the participant so that a restarted process can resume the DKG
+//
+// magic || version || n || threshold || position
+// || secret polynomial || secret shares || received shares || signing share
+// || polynomial commitments || proven keys || Q map || W map || public signing shares || group public key
+//
+// integers are big endian, lists are prefixed by a 4 bytes count, maps are sorted by position
+// scalars are 32 bytes, points are 33 bytes compressed, the point at infinity is 33 zero bytes
+// optional values are prefixed by a presence byte
+// the power map is cheap to derive again, thus not saved
+//
+// the state contains the secret polynomial and shares, the writer must be protected accordingly
+func (p *FrostParticipant) SaveState(w io.Writer) error {
+	data := make([]byte, 0)
+	data = append(data, frostStateMagic...)
+	data = binary.BigEndian.AppendUint16(data, FrostStateVersion)
+	data = append(data, Int64ToBytes(p.N)...)
+	data = append(data, Int64ToBytes(p.Threshold)...)
+	data = append(data, Int64ToBytes(p.Position)...)
+
+	data = appendScalars(data, p.secretPolynomial)
+	data = appendScalars(data, p.secretShares)
+	received_dealers := presentPositions(p.N, func(posi int64) bool {
+		_, ok := p.received_shares[posi]
+		return ok
+	})
+	data = binary.BigEndian.AppendUint32(data, uint32(len(received_dealers)))
+	for _, dealer := range received_dealers {
+		data = append(data, Int64ToBytes(dealer)...)
+		data = appendScalar(data, p.received_shares[dealer])
+	}
+	if p.signingShares == nil {
+		data = append(data, 0)
+	} else {
+		data = append(data, 1)
+		data = appendScalar(data, p.signingShares)
+	}
+
+	// each dealer commitments in the MarshalPolynomialCommitments encoding, prefixed by its length
+	all_commitments := p.snapshotPolynomialCommitments()
+	dealers := presentPositions(p.N, func(posi int64) bool {
+		_, ok := all_commitments[posi]
+		return ok
+	})
+	data = binary.BigEndian.AppendUint32(data, uint32(len(dealers)))
+	for _, dealer := range dealers {
+		commitments := marshalCommitments(dealer, all_commitments[dealer])
+		data = binary.BigEndian.AppendUint32(data, uint32(len(commitments)))
+		data = append(data, commitments...)
+	}
+
+	data = appendKeyMap(data, p.N, &p.proven_keys)
+	data = appendPointMap(data, p.N, &p.q_map)
+	data = appendPointMap(data, p.N, &p.w_map)
+	data = appendKeyMap(data, p.N, &p.PublicSigningShares)
+
+	if p.GroupPublicKey == nil {
+		data = append(data, 0)
+	} else {
+		data = append(data, 1)
+		data = append(data, p.GroupPublicKey.SerializeCompressed()...)
+	}
+
+	_, err := w.Write(data)
+	return err
+}
+
+// restore a participant saved by SaveState, the DKG continues from where it was saved
+// nonces and signing sessions are not part of the DKG state, they start empty
+func (s *TestSuite) LoadState(r io.Reader) (*FrostParticipant, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(frostStateMagic)+2 || !bytes.Equal(data[:len(frostStateMagic)], frostStateMagic) {
+		return nil, ErrInvalidStateMagic
+	}
+	data = data[len(frostStateMagic):]
+	if version := binary.BigEndian.Uint16(data[:2]); version != FrostStateVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedStateVersion, version)
+	}
+
+	reader := &stateReader{data: data[2:]}
+	n := reader.int64()
+	threshold := reader.int64()
+	posi := reader.int64()
+	if reader.err != nil || n <= 0 || threshold < 0 || threshold >= n || posi < 1 || posi > n {
+		return nil, fmt.Errorf("%w: invalid n %d, threshold %d or position %d", ErrMalformedState, n, threshold, posi)
+	}
+	p := newEmptyFrostParticipant(s, s.Logger, n, threshold, posi)
+
+	p.secretPolynomial = reader.scalars()
+	if reader.err == nil && int64(len(p.secretPolynomial)) != threshold+1 {
+		return nil, fmt.Errorf("%w: %d polynomial coefficients, expected %d", ErrMalformedState, len(p.secretPolynomial), threshold+1)
+	}
+	secret_shares := reader.scalars()
+	if len(secret_shares) > 0 {
+		if int64(len(secret_shares)) != n {
+			return nil, fmt.Errorf("%w: %d secret shares, expected %d", ErrMalformedState, len(secret_shares), n)
+		}
+		p.secretShares = secret_shares
+	}
+	for k := reader.count(8 + 32); k > 0; k-- {
+		dealer := reader.position(n)
+		share := reader.scalar()
+		if reader.err == nil {
+			p.ReceiveSecretShare(dealer, share)
+		}
+	}
+	if reader.flag() {
+		p.signingShares = reader.scalar()
+	}
+
+	for k := reader.count(4); k > 0 && reader.err == nil; k-- {
+		commitments_bytes := reader.next(int(reader.uint32()))
+		if reader.err != nil {
+			break
+		}
+		dealer, commitments, err := p.parseCommitments(commitments_bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedState, err)
+		}
+		p.PolynomialCommitments[dealer] = commitments
+	}
+	if _, ok := p.PolynomialCommitments[posi]; !ok && reader.err == nil {
+		return nil, fmt.Errorf("%w: own commitments are missing", ErrMalformedState)
+	}
+
+	for k := reader.count(8 + btcec.PubKeyBytesLenCompressed); k > 0; k-- {
+		dealer := reader.position(n)
+		key := reader.publicKey()
+		if reader.err == nil {
+			p.proven_keys.Store(dealer, key)
+		}
+	}
+
+	reader.pointMap(&p.q_map, n, threshold)
+	reader.pointMap(&p.w_map, n, threshold)
+
+	for k := reader.count(8 + btcec.PubKeyBytesLenCompressed); k > 0; k-- {
+		position := reader.position(n)
+		key := reader.publicKey()
+		if reader.err == nil {
+			p.PublicSigningShares.Store(position, key)
+		}
+	}
+
+	if reader.flag() {
+		p.GroupPublicKey = reader.publicKey()
+	}
+
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	if len(reader.data) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrMalformedState, len(reader.data))
+	}
+
+	return p, nil
+}
+
+func appendScalar(data []byte, scalar *btcec.ModNScalar) []byte {
+	scalar_bytes := scalar.Bytes()
+	return append(data, scalar_bytes[:]...)
+}
+
+func appendScalars(data []byte, scalars []*btcec.ModNScalar) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(len(scalars)))
+	for _, scalar := range scalars {
+		data = appendScalar(data, scalar)
+	}
+
+	return data
+}
+
+// position || P for each position holding a public key
+func appendKeyMap(data []byte, n int64, key_map *sync.Map) []byte {
+	positions := presentPositions(n, func(posi int64) bool {
+		_, ok := key_map.Load(posi)
+		return ok
+	})
+	data = binary.BigEndian.AppendUint32(data, uint32(len(positions)))
+	for _, posi := range positions {
+		key, _ := key_map.Load(posi)
+		data = append(data, Int64ToBytes(posi)...)
+		data = append(data, key.(*btcec.PublicKey).SerializeCompressed()...)
+	}
+
+	return data
+}
+
+// position || k || P_0 || ... || P_{k-1} for each position holding points
+func appendPointMap(data []byte, n int64, point_map *sync.Map) []byte {
+	positions := presentPositions(n, func(posi int64) bool {
+		_, ok := point_map.Load(posi)
+		return ok
+	})
+	data = binary.BigEndian.AppendUint32(data, uint32(len(positions)))
+	for _, posi := range positions {
+		value, _ := point_map.Load(posi)
+		points := value.([]*btcec.JacobianPoint)
+		data = append(data, Int64ToBytes(posi)...)
+		data = binary.BigEndian.AppendUint32(data, uint32(len(points)))
+		for _, point := range points {
+			affine := new(btcec.JacobianPoint)
+			affine.Set(point)
+			if (affine.X.IsZero() && affine.Y.IsZero()) || affine.Z.IsZero() {
+				data = append(data, make([]byte, btcec.PubKeyBytesLenCompressed)...)
+				continue
+			}
+			affine.ToAffine()
+			data = append(data, btcec.NewPublicKey(&affine.X, &affine.Y).SerializeCompressed()...)
+		}
+	}
+
+	return data
+}
+
+// positions in [1, n] for which has is true, in ascending order
+func presentPositions(n int64, has func(posi int64) bool) []int64 {
+	positions := make([]int64, 0)
+	for posi := int64(1); posi <= n; posi++ {
+		if has(posi) {
+			positions = append(positions, posi)
+		}
+	}
+
+	return positions
+}
+
+// sequential reader of a saved state, the first failure is kept and later reads return zero values
+type stateReader struct {
+	data []byte
+	err  error
+}
+
+func (r *stateReader) next(size int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if size < 0 || len(r.data) < size {
+		r.err = fmt.Errorf("%w: unexpected end of state", ErrMalformedState)
+		return nil
+	}
+	value := r.data[:size]
+	r.data = r.data[size:]
+
+	return value
+}
+
+func (r *stateReader) int64() int64 {
+	value := r.next(8)
+	if value == nil {
+		return 0
+	}
+
+	return BytesToInt64(value)
+}
+
+func (r *stateReader) uint32() uint32 {
+	value := r.next(4)
+	if value == nil {
+		return 0
+	}
+
+	return binary.BigEndian.Uint32(value)
+}
+
+// a list count, refused when the remaining state cannot hold count items of item_size bytes
+func (r *stateReader) count(item_size int) int {
+	count := int(r.uint32())
+	if r.err == nil && count > len(r.data)/item_size {
+		r.err = fmt.Errorf("%w: %d items exceed the state length", ErrMalformedState, count)
+	}
+	if r.err != nil {
+		return 0
+	}
+
+	return count
+}
+
+func (r *stateReader) flag() bool {
+	value := r.next(1)
+	if value == nil {
+		return false
+	}
+	if value[0] > 1 {
+		r.err = fmt.Errorf("%w: invalid presence byte %d", ErrMalformedState, value[0])
+		return false
+	}
+
+	return value[0] == 1
+}
+
+func (r *stateReader) position(n int64) int64 {
+	posi := r.int64()
+	if r.err == nil && (posi < 1 || posi > n) {
+		r.err = fmt.Errorf("%w: position %d out of range", ErrMalformedState, posi)
+	}
+
+	return posi
+}
+
+func (r *stateReader) scalar() *btcec.ModNScalar {
+	value := r.next(32)
+	if value == nil {
+		return nil
+	}
+	scalar := new(btcec.ModNScalar)
+	if scalar.SetByteSlice(value) {
+		r.err = fmt.Errorf("%w: scalar overflows the group order", ErrMalformedState)
+		return nil
+	}
+
+	return scalar
+}
+
+func (r *stateReader) scalars() []*btcec.ModNScalar {
+	count := r.count(32)
+	scalars := make([]*btcec.ModNScalar, count)
+	for i := range scalars {
+		scalars[i] = r.scalar()
+	}
+
+	return scalars
+}
+
+func (r *stateReader) publicKey() *btcec.PublicKey {
+	value := r.next(btcec.PubKeyBytesLenCompressed)
+	if value == nil {
+		return nil
+	}
+	key, err := btcec.ParsePubKey(value)
+	if err != nil {
+		r.err = fmt.Errorf("%w: %v", ErrMalformedState, err)
+		return nil
+	}
+
+	return key
+}
+
+func (r *stateReader) jacobianPoint() *btcec.JacobianPoint {
+	value := r.next(btcec.PubKeyBytesLenCompressed)
+	if value == nil {
+		return nil
+	}
+	point := new(btcec.JacobianPoint)
+	if bytes.Equal(value, make([]byte, btcec.PubKeyBytesLenCompressed)) {
+		return point
+	}
+	key, err := btcec.ParsePubKey(value)
+	if err != nil {
+		r.err = fmt.Errorf("%w: %v", ErrMalformedState, err)
+		return nil
+	}
+	key.AsJacobian(point)
+
+	return point
+}
+
+// Q and W maps hold threshold + 1 points for each position
+func (r *stateReader) pointMap(point_map *sync.Map, n, threshold int64) {
+	for k := r.count(8 + 4); k > 0 && r.err == nil; k-- {
+		posi := r.position(n)
+		count := r.count(btcec.PubKeyBytesLenCompressed)
+		if r.err == nil && int64(count) != threshold+1 {
+			r.err = fmt.Errorf("%w: %d points for position %d, expected %d", ErrMalformedState, count, posi, threshold+1)
+		}
+		points := make([]*btcec.JacobianPoint, count)
+		for j := range points {
+			points[j] = r.jacobianPoint()
+		}
+		if r.err == nil {
+			point_map.Store(posi, points)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package testhelper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var (
+	ErrUnknownSigner     = errors.New("taproot key path sign: unknown signer")
+	ErrInvalidTaprootSig = errors.New("taproot key path sign: aggregated signature does not verify")
+	ErrUnknownOutputKey  = errors.New("sign taproot inputs: output is not controlled by the group")
+	ErrInvalidMerkleRoot = errors.New("taproot key: merkle root must be 32 bytes")
+	ErrMissingGroupKey   = errors.New("group checksig script: group public key not calculated")
+
+	// H = lift_x(SHA256(G)) from BIP341, nobody knows its discrete logarithm
+	taprootNUMSKeyHex = "50929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0"
+)
+
+// the BIP341 NUMS point, used as internal key to disable key path spending
+func NUMSInternalKey() *btcec.PublicKey {
+	key_bytes, _ := hex.DecodeString(taprootNUMSKeyHex)
+	key, _ := schnorr.ParsePubKey(key_bytes)
+
+	return key
+}
+
+// <Y> OP_CHECKSIG, a tapscript leaf spendable by a threshold signature of the group
+func (p *FrostParticipant) GroupCheckSigScript() ([]byte, error) {
+	if p.GroupPublicKey == nil {
+		return nil, ErrMissingGroupKey
+	}
+
+	return txscript.NewScriptBuilder().
+		AddData(schnorr.SerializePubKey(p.GroupPublicKey)).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+}
+
+// Q = H + H_TapTweak(H || merkle_root) * G, H is the NUMS internal key
+// the tree commits the group scripts, e.g. GroupCheckSigScript
+// since the discrete logarithm of H is unknown, only script path spends requiring the threshold work
+func (p *FrostParticipant) ScriptOnlyTaprootKey(merkleRoot []byte) (*btcec.PublicKey, error) {
+	if len(merkleRoot) != 32 {
+		return nil, ErrInvalidMerkleRoot
+	}
+
+	return txscript.ComputeTaprootOutputKey(NUMSInternalKey(), merkleRoot), nil
+}
+
+// Q = P + t * G, t = H_TapTweak(P || merkle_root), P is the group public key lifted to even Y coordinate
+// an empty merkle root gives the BIP86 output key, spendable by key path only
+func (p *FrostParticipant) TaprootOutputKey(merkleRoot []byte) (*btcec.PublicKey, error) {
+	if err := p.checkTaprootInputs(merkleRoot); err != nil {
+		return nil, err
+	}
+
+	return txscript.ComputeTaprootOutputKey(p.GroupPublicKey, merkleRoot), nil
+}
+
+// the tweak t of TaprootOutputKey and the parity of Q
+// key path signers add t to their signing share of the even P, the parity goes into the control block of script path spends
+func (p *FrostParticipant) TaprootTweak(merkleRoot []byte) (t *btcec.ModNScalar, odd bool, err error) {
+	output_key, err := p.TaprootOutputKey(merkleRoot)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return taprootTweak(p.GroupPublicKey, merkleRoot), output_key.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd, nil
+}
+
+func (p *FrostParticipant) checkTaprootInputs(merkleRoot []byte) error {
+	if p.GroupPublicKey == nil {
+		return ErrMissingGroupKey
+	}
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return ErrInvalidMerkleRoot
+	}
+
+	return nil
+}
+
+// Q = P + t * G, t = H_TapTweak(P || script_root), P is the x - only group public key
+// an empty script root is the BIP86 tweak without script path
+func taprootTweak(group_key *btcec.PublicKey, script_root []byte) *btcec.ModNScalar {
+	tweak_hash := chainhash.TaggedHash(chainhash.TagTapTweak, schnorr.SerializePubKey(group_key), script_root)
+	t := new(btcec.ModNScalar)
+	t.SetByteSlice(tweak_hash[:])
+
+	return t
+}
+
+// threshold sign the message under the taproot output key Q of the group
+func (s *TestSuite) frostSignTaprootKeyPath(participants []*FrostParticipant, signers map[int64]bool, message [32]byte, script_root []byte) (*schnorr.Signature, error) {
+	if len(participants) == 0 {
+		return nil, ErrThresholdNotMet
+	}
+
+	return s.frostSign(participants, signers, message, taprootTweak(participants[0].GroupPublicKey, script_root))
+}
+
+// threshold sign the sighash under the taproot output key Q = P + t * G, t = H_TapTweak(P || merkle_root)
+// unlike frostSignTaprootKeyPath, signers keep their plain shares and the coordinator folds the tweak into the aggregate
+// an empty merkle root signs for the BIP86 output key
+func (s *TestSuite) SignTaprootKeyPath(participants []*FrostParticipant, sigHash [32]byte, merkleRoot []byte, signers map[int64]bool) (*schnorr.Signature, error) {
+	if len(participants) == 0 {
+		return nil, ErrThresholdNotMet
+	}
+	t, _, err := participants[0].TaprootTweak(merkleRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.frostSignTweaked(participants, signers, sigHash, t, true)
+}
+
+// threshold sign the message under the group public key P, or under Q = P + t * G when a tweak t is given
+// the tweak is added to every signing share, \sum_{S} \lambda_i * (s_i + t) = s + t since \sum_{S} \lambda_i = 1
+// s_i is negated first when P has odd Y coordinate, PartialSign then handles the parity of Q
+//
+// each signer preprocesses a fresh nonce pair bound to the message
+func (s *TestSuite) frostSign(participants []*FrostParticipant, signers map[int64]bool, message [32]byte, t *btcec.ModNScalar) (*schnorr.Signature, error) {
+	return s.frostSignTweaked(participants, signers, message, t, false)
+}
+
+// with fold_tweak, the tweak is not added to the signing shares but to the aggregate
+// z = \sum_{S} z_i + c * t, the shares sign for the even P under c = H(R, Q, m), thus both terms are negated by PartialSign
+// and by the coordinator alike when Q has odd Y coordinate
+func (s *TestSuite) frostSignTweaked(participants []*FrostParticipant, signers map[int64]bool, message [32]byte, t *btcec.ModNScalar, fold_tweak bool) (*schnorr.Signature, error) {
+	by_position := make(map[int64]*FrostParticipant)
+	for _, participant := range participants {
+		by_position[participant.Position] = participant
+	}
+	honest := make([]int64, 0, len(signers))
+	for posi, ok := range signers {
+		if !ok {
+			continue
+		}
+		if _, found := by_position[posi]; !found {
+			return nil, ErrUnknownSigner
+		}
+		honest = append(honest, posi)
+	}
+	sort.Slice(honest, func(i, j int) bool { return honest[i] < honest[j] })
+	// refuse before any nonce is spent
+	if len(honest) == 0 || int64(len(honest)) <= by_position[honest[0]].Threshold {
+		return nil, ErrThresholdNotMet
+	}
+
+	group_key := by_position[honest[0]].GroupPublicKey
+	output_key := group_key
+	if t != nil {
+		// Q = P + t * G with P lifted to even Y coordinate
+		P := new(btcec.JacobianPoint)
+		group_key.AsJacobian(P)
+		if group_key.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd {
+			P = negatePoint(P)
+		}
+		Q := new(btcec.JacobianPoint)
+		btcec.ScalarBaseMultNonConst(t, Q)
+		btcec.AddNonConst(P, Q, Q)
+		Q.ToAffine()
+		output_key = btcec.NewPublicKey(&Q.X, &Q.Y)
+	}
+
+	signing_indices := make(map[int64]int64)
+	public_nonces := make(map[int64][2]*btcec.PublicKey)
+	for _, posi := range honest {
+		signing_indices[posi], public_nonces[posi] = by_position[posi].PreprocessNoncesForSighash(message)
+	}
+	for _, posi := range honest {
+		if _, err := by_position[posi].CalculatePublicNonceCommitments(signing_indices[posi], honest, message, public_nonces); err != nil {
+			return nil, err
+		}
+	}
+
+	partial_sigs := make(map[int64]*schnorr.Signature)
+	for _, posi := range honest {
+		participant := by_position[posi]
+
+		s_i := new(btcec.ModNScalar).Set(participant.GetSigningShares())
+		if t == nil {
+			partial_sigs[posi] = participant.PartialSign(posi, signing_indices[posi], honest, message, public_nonces, s_i)
+			continue
+		}
+		if group_key.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd {
+			s_i.Negate()
+		}
+		if !fold_tweak {
+			s_i.Add(t)
+		}
+
+		// sign under the output key, the group public key is restored afterwards
+		participant.GroupPublicKey = output_key
+		partial_sigs[posi] = participant.PartialSign(posi, signing_indices[posi], honest, message, public_nonces, s_i)
+		participant.GroupPublicKey = group_key
+	}
+
+	aggregator := NewFrostAggregator(s, by_position[honest[0]])
+	sig, err := aggregator.AggregateStrict(signing_indices[honest[0]], partial_sigs)
+	if err != nil {
+		return nil, err
+	}
+	if t != nil && fold_tweak {
+		sig = foldTaprootTweak(sig, output_key, message, t)
+	}
+	if !sig.Verify(message[:], output_key) {
+		return nil, ErrInvalidTaprootSig
+	}
+
+	return sig, nil
+}
+
+// (R, z + c * t), c = H(R, Q, m), t is negated when Q has odd Y coordinate
+func foldTaprootTweak(sig *schnorr.Signature, output_key *btcec.PublicKey, message [32]byte, t *btcec.ModNScalar) *schnorr.Signature {
+	sig_bytes := sig.Serialize()
+	commitment_hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, sig_bytes[0:32], schnorr.SerializePubKey(output_key), message[:])
+	c := new(btcec.ModNScalar)
+	c.SetByteSlice(commitment_hash[:])
+
+	tweak := new(btcec.ModNScalar).Mul2(c, t)
+	if output_key.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd {
+		tweak.Negate()
+	}
+	z := new(btcec.ModNScalar)
+	z.SetByteSlice(sig_bytes[32:64])
+	z.Add(tweak)
+	R_x := new(btcec.FieldVal)
+	R_x.SetByteSlice(sig_bytes[0:32])
+
+	return schnorr.NewSignature(R_x, z)
+}
+
+// sign every input of the transaction with a threshold signature of the group, populating all witnesses
+// prevOuts[i] is the output spent by input i, paying either to the group public key or to its BIP86 output key
+// each input has its own sighash, thus its own fresh nonces
+func (s *TestSuite) SignTaprootAllInputs(tx *wire.MsgTx, prevOuts []*wire.TxOut, participants []*FrostParticipant, signers map[int64]bool) error {
+	if len(prevOuts) != len(tx.TxIn) || len(participants) == 0 {
+		return ErrUnknownOutputKey
+	}
+
+	prev_out_map := make(map[wire.OutPoint]*wire.TxOut)
+	for i, txIn := range tx.TxIn {
+		prev_out_map[txIn.PreviousOutPoint] = prevOuts[i]
+	}
+	fetcher := txscript.NewMultiPrevOutFetcher(prev_out_map)
+	sig_hashes := txscript.NewTxSigHashes(tx, fetcher)
+
+	group_key := participants[0].GroupPublicKey
+	group_script, err := txscript.PayToTaprootScript(group_key)
+	if err != nil {
+		return err
+	}
+	bip86_script, err := txscript.PayToTaprootScript(txscript.ComputeTaprootKeyNoScript(group_key))
+	if err != nil {
+		return err
+	}
+
+	witnesses := make([]wire.TxWitness, len(tx.TxIn))
+	for i := range tx.TxIn {
+		sighash, err := txscript.CalcTaprootSignatureHash(sig_hashes, txscript.SigHashDefault, tx, i, fetcher)
+		if err != nil {
+			return err
+		}
+
+		var sig *schnorr.Signature
+		switch {
+		case bytes.Equal(prevOuts[i].PkScript, group_script):
+			sig, err = s.frostSign(participants, signers, ([32]byte)(sighash), nil)
+		case bytes.Equal(prevOuts[i].PkScript, bip86_script):
+			sig, err = s.frostSignTaprootKeyPath(participants, signers, ([32]byte)(sighash), []byte{})
+		default:
+			return fmt.Errorf("input %d: %w", i, ErrUnknownOutputKey)
+		}
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+		witnesses[i] = wire.TxWitness{sig.Serialize()}
+	}
+
+	// witnesses are only populated when all inputs are signed
+	for i, txIn := range tx.TxIn {
+		txIn.Witness = witnesses[i]
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package testhelper
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+var (
+	ErrMissingSecretProof        = errors.New("record transcript: missing secret proof of a dealer")
+	ErrInvalidTranscript         = errors.New("verify transcript: invalid transcript")
+	ErrTranscriptGroupKey        = errors.New("verify transcript: group public key does not match the commitments")
+	ErrInvalidTranscriptEncoding = errors.New("unmarshal transcript: invalid encoding")
+)
+
+// Transcript is the public record of a DKG, an auditor replays VerifySecretProofs over it without private data
+// Commitments holds A_i0, ..., A_it and SecretProofs the proof of knowledge of a_i0 of every qualified dealer i
+// Y = \sum_{i} A_i0 is checked against GroupPublicKey
+type Transcript struct {
+	ContextHash    [32]byte
+	N              int64
+	Threshold      int64
+	Commitments    map[int64][]*btcec.PublicKey
+	SecretProofs   map[int64]*schnorr.Signature
+	GroupPublicKey *btcec.PublicKey
+}
+
+// json form, points are hex of 33 bytes compressed, the proof (R_x, s) is hex of 32 bytes each
+type transcriptJSON struct {
+	ContextHash    string                 `json:"context_hash"`
+	N              int64                  `json:"n"`
+	Threshold      int64                  `json:"threshold"`
+	Dealers        []transcriptDealerJSON `json:"dealers"`
+	GroupPublicKey string                 `json:"group_public_key"`
+}
+
+type transcriptDealerJSON struct {
+	Position    int64    `json:"position"`
+	Commitments []string `json:"commitments"`
+	ProofR      string   `json:"proof_r"`
+	ProofS      string   `json:"proof_s"`
+}
+
+// record the DKG as seen by the participant, secret_proofs are the proofs received from the dealers
+// CalculateGroupPublicKey must be called before
+func (p *FrostParticipant) RecordTranscript(context_hash [32]byte, secret_proofs map[int64]*schnorr.Signature) (*Transcript, error) {
+	if p.GroupPublicKey == nil {
+		return nil, fmt.Errorf("record transcript: %w", ErrMissingGroupKey)
+	}
+	transcript := &Transcript{
+		ContextHash:    context_hash,
+		N:              p.N,
+		Threshold:      p.Threshold,
+		Commitments:    make(map[int64][]*btcec.PublicKey),
+		SecretProofs:   make(map[int64]*schnorr.Signature),
+		GroupPublicKey: p.GroupPublicKey,
+	}
+	for _, dealer := range p.QualifiedSet() {
+		proof, ok := secret_proofs[dealer]
+		if !ok || proof == nil {
+			return nil, fmt.Errorf("%w: %d", ErrMissingSecretProof, dealer)
+		}
+		commitments, _ := p.GetPolynomialCommitments(dealer)
+		transcript.Commitments[dealer] = commitments
+		transcript.SecretProofs[dealer] = proof
+	}
+
+	return transcript, nil
+}
+
+// every dealer must commit to a polynomial of degree t and prove knowledge of a_i0
+// the proofs are replayed by a participant holding no share, thus anyone can audit the DKG
+func VerifyTranscript(suite *TestSuite, transcript *Transcript) error {
+	if transcript.N < 1 || transcript.Threshold < 1 || transcript.Threshold >= transcript.N {
+		return fmt.Errorf("%w: n = %d, threshold = %d", ErrInvalidTranscript, transcript.N, transcript.Threshold)
+	}
+	if transcript.GroupPublicKey == nil || len(transcript.Commitments) == 0 {
+		return ErrInvalidTranscript
+	}
+
+	verifier := newEmptyFrostParticipant(suite, nil, transcript.N, transcript.Threshold, 0)
+	Y := new(btcec.JacobianPoint)
+	for _, dealer := range transcript.dealers() {
+		if dealer < 1 || dealer > transcript.N {
+			return fmt.Errorf("%w: dealer %d: %w", ErrInvalidTranscript, dealer, ErrIndexOutOfRange)
+		}
+		commitments := transcript.Commitments[dealer]
+		if int64(len(commitments)) != transcript.Threshold+1 {
+			return fmt.Errorf("%w: dealer %d: %d commitments", ErrInvalidTranscript, dealer, len(commitments))
+		}
+		proof, ok := transcript.SecretProofs[dealer]
+		if !ok || proof == nil {
+			return fmt.Errorf("%w: dealer %d: %w", ErrInvalidTranscript, dealer, ErrMissingSecretProof)
+		}
+		if err := verifier.VerifySecretProofs(transcript.ContextHash, proof, dealer, commitments[0]); err != nil {
+			return err
+		}
+		A_0 := new(btcec.JacobianPoint)
+		commitments[0].AsJacobian(A_0)
+		suite.addPoints(Y, A_0, Y)
+	}
+
+	expected := new(btcec.JacobianPoint)
+	transcript.GroupPublicKey.AsJacobian(expected)
+	if !equalPoints(Y, expected) {
+		return ErrTranscriptGroupKey
+	}
+
+	return nil
+}
+
+// qualified dealers in ascending order
+func (t *Transcript) dealers() []int64 {
+	dealers := make([]int64, 0, len(t.Commitments))
+	for dealer := range t.Commitments {
+		dealers = append(dealers, dealer)
+	}
+	sort.Slice(dealers, func(i, j int) bool { return dealers[i] < dealers[j] })
+
+	return dealers
+}
+
+func (t *Transcript) MarshalJSON() ([]byte, error) {
+	if t.GroupPublicKey == nil {
+		return nil, fmt.Errorf("marshal transcript: %w", ErrMissingGroupKey)
+	}
+	encoded := transcriptJSON{
+		ContextHash:    hex.EncodeToString(t.ContextHash[:]),
+		N:              t.N,
+		Threshold:      t.Threshold,
+		Dealers:        make([]transcriptDealerJSON, 0, len(t.Commitments)),
+		GroupPublicKey: hex.EncodeToString(t.GroupPublicKey.SerializeCompressed()),
+	}
+	for _, dealer := range t.dealers() {
+		proof, ok := t.SecretProofs[dealer]
+		if !ok || proof == nil {
+			return nil, fmt.Errorf("marshal transcript: %w: %d", ErrMissingSecretProof, dealer)
+		}
+		proof_bytes := proof.Serialize()
+		dealer_json := transcriptDealerJSON{
+			Position:    dealer,
+			Commitments: make([]string, len(t.Commitments[dealer])),
+			ProofR:      hex.EncodeToString(proof_bytes[0:32]),
+			ProofS:      hex.EncodeToString(proof_bytes[32:64]),
+		}
+		for k, commitment := range t.Commitments[dealer] {
+			dealer_json.Commitments[k] = hex.EncodeToString(commitment.SerializeCompressed())
+		}
+		encoded.Dealers = append(encoded.Dealers, dealer_json)
+	}
+
+	return json.Marshal(encoded)
+}
+
+// points and proofs are parsed, thus off - curve points and out of range scalars are rejected here
+// the proofs themselves are only checked by VerifyTranscript
+func (t *Transcript) UnmarshalJSON(data []byte) error {
+	var encoded transcriptJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTranscriptEncoding, err)
+	}
+	parsePoint := func(value string) (*btcec.PublicKey, error) {
+		point_bytes, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTranscriptEncoding, err)
+		}
+		point, err := btcec.ParsePubKey(point_bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTranscriptEncoding, err)
+		}
+		return point, nil
+	}
+
+	context_hash, err := hex.DecodeString(encoded.ContextHash)
+	if err != nil || len(context_hash) != 32 {
+		return fmt.Errorf("%w: context hash", ErrInvalidTranscriptEncoding)
+	}
+	decoded := Transcript{
+		N:            encoded.N,
+		Threshold:    encoded.Threshold,
+		Commitments:  make(map[int64][]*btcec.PublicKey, len(encoded.Dealers)),
+		SecretProofs: make(map[int64]*schnorr.Signature, len(encoded.Dealers)),
+	}
+	copy(decoded.ContextHash[:], context_hash)
+	if decoded.GroupPublicKey, err = parsePoint(encoded.GroupPublicKey); err != nil {
+		return err
+	}
+	for _, dealer := range encoded.Dealers {
+		if _, ok := decoded.Commitments[dealer.Position]; ok {
+			return fmt.Errorf("%w: dealer %d appears twice", ErrInvalidTranscriptEncoding, dealer.Position)
+		}
+		commitments := make([]*btcec.PublicKey, len(dealer.Commitments))
+		for k, commitment := range dealer.Commitments {
+			if commitments[k], err = parsePoint(commitment); err != nil {
+				return fmt.Errorf("dealer %d: %w", dealer.Position, err)
+			}
+		}
+		R_x, err_r := hex.DecodeString(dealer.ProofR)
+		s, err_s := hex.DecodeString(dealer.ProofS)
+		if err_r != nil || err_s != nil || len(R_x) != 32 || len(s) != 32 {
+			return fmt.Errorf("%w: dealer %d: secret proof", ErrInvalidTranscriptEncoding, dealer.Position)
+		}
+		proof, err := schnorr.ParseSignature(append(R_x, s...))
+		if err != nil {
+			return fmt.Errorf("%w: dealer %d: %v", ErrInvalidTranscriptEncoding, dealer.Position, err)
+		}
+		decoded.Commitments[dealer.Position] = commitments
+		decoded.SecretProofs[dealer.Position] = proof
+	}
+	*t = decoded
+
+	return nil
+}
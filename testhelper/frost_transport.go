@@ -0,0 +1,299 @@
+package testhelper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+)
+
+var (
+	ErrTransportTimeout    = errors.New("transport: receive timed out")
+	ErrUnknownRecipient    = errors.New("transport: unknown recipient")
+	ErrUnexpectedDKGMsg    = errors.New("dkg over transport: unexpected message")
+	ErrInvalidDKGMsgShare  = errors.New("dkg over transport: malformed secret share")
+	ErrInvalidDKGMsgSender = errors.New("dkg over transport: message from unknown sender")
+)
+
+type DKGMessageType int
+
+const (
+	// round 1, polynomial commitments and secret proof of a dealer
+	DKGMessageCommitments DKGMessageType = iota + 1
+	// round 2, secret share f_i(j) from dealer i to participant j
+	DKGMessageSecretShare
+	// round 3, position of a dealer whose share failed verification, only sent by accusers
+	DKGMessageComplaint
+	// round 4, position of the accuser and the share f_i(j) revealed by the accused dealer
+	DKGMessageJustification
+)
+
+// communication rounds of the DKG, each round waits for the messages of the previous one
+// the happy path has 2 rounds, DKGMessageCommitments then DKGMessageSecretShare
+// resolving complaints adds 2 rounds, accusers broadcast DKGMessageComplaint then accused dealers broadcast DKGMessageJustification
+func DKGRoundCount(withComplaints bool) int {
+	rounds := 2
+	if withComplaints {
+		rounds += 2
+	}
+
+	return rounds
+}
+
+// DKGMessage is a DKG protocol message between participants
+// To is 0 for messages to every participant, otherwise the position of the only recipient
+type DKGMessage struct {
+	Type DKGMessageType
+	From int64
+	To   int64
+
+	// MarshalPolynomialCommitments encoding for DKGMessageCommitments, 32 bytes share for DKGMessageSecretShare
+	Payload []byte
+	// serialized secret proof for DKGMessageCommitments
+	Proof []byte
+}
+
+// Transport carries DKG messages of a participant to the others, e.g. through a coordinator
+// participants never index each other directly
+type Transport interface {
+	// deliver msg from the sender to every other participant, or only to msg.To when set
+	Broadcast(from int64, msg DKGMessage) error
+	// next message addressed to the participant
+	Receive() (DKGMessage, error)
+}
+
+// ChannelTransport is an in - memory Transport endpoint of a single participant
+// endpoints of the same group share the inboxes of all participants
+type ChannelTransport struct {
+	Position int64
+	Timeout  time.Duration
+
+	inboxes map[int64]*transportInbox
+}
+
+// unbounded queue of messages, thus senders never block whatever the number of rounds, complaints or retries
+// ready holds a token while the queue is not empty
+type transportInbox struct {
+	mu    sync.Mutex
+	queue []DKGMessage
+	ready chan struct{}
+}
+
+func (inbox *transportInbox) push(msg DKGMessage) {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+
+	inbox.queue = append(inbox.queue, msg)
+	select {
+	case inbox.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (inbox *transportInbox) pop() (DKGMessage, bool) {
+	inbox.mu.Lock()
+	defer inbox.mu.Unlock()
+
+	if len(inbox.queue) == 0 {
+		return DKGMessage{}, false
+	}
+	msg := inbox.queue[0]
+	inbox.queue = inbox.queue[1:]
+	if len(inbox.queue) > 0 {
+		select {
+		case inbox.ready <- struct{}{}:
+		default:
+		}
+	}
+
+	return msg, true
+}
+
+// endpoints for positions 1 to n
+func NewChannelTransports(n int64) []*ChannelTransport {
+	inboxes := make(map[int64]*transportInbox, n)
+	for posi := int64(1); posi <= n; posi++ {
+		inboxes[posi] = &transportInbox{ready: make(chan struct{}, 1)}
+	}
+
+	transports := make([]*ChannelTransport, n)
+	for posi := int64(1); posi <= n; posi++ {
+		transports[posi-1] = &ChannelTransport{
+			Position: posi,
+			Timeout:  30 * time.Second,
+			inboxes:  inboxes,
+		}
+	}
+
+	return transports
+}
+
+func (t *ChannelTransport) Broadcast(from int64, msg DKGMessage) error {
+	msg.From = from
+	if msg.To != 0 {
+		inbox, ok := t.inboxes[msg.To]
+		if !ok {
+			return ErrUnknownRecipient
+		}
+		inbox.push(msg)
+		return nil
+	}
+
+	for posi, inbox := range t.inboxes {
+		if posi != from {
+			inbox.push(msg)
+		}
+	}
+
+	return nil
+}
+
+func (t *ChannelTransport) Receive() (DKGMessage, error) {
+	inbox := t.inboxes[t.Position]
+	timeout := time.After(t.Timeout)
+	for {
+		if msg, ok := inbox.pop(); ok {
+			return msg, nil
+		}
+		select {
+		case <-inbox.ready:
+		case <-timeout:
+			return DKGMessage{}, ErrTransportTimeout
+		}
+	}
+}
+
+// run both DKG rounds with the other N - 1 participants over the transport
+// round 1 broadcasts commitments and the secret proof, round 2 sends each secret share to its recipient
+// shares received before the end of round 1 are held until all commitments are verified
+// a repeated message of a dealer, e.g. a retry, is ignored when identical and refused otherwise
+//
+// on success the participant holds its signing share, all public signing shares and the group public key
+func (p *FrostParticipant) RunDKG(transport Transport, context_hash [32]byte) error {
+	commitments, err := p.MarshalPolynomialCommitments()
+	if err != nil {
+		return err
+	}
+	err = transport.Broadcast(p.Position, DKGMessage{
+		Type:    DKGMessageCommitments,
+		Payload: commitments,
+		Proof:   p.CalculateSecretProofs(context_hash).Serialize(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// round 1
+	early_shares := make([]DKGMessage, 0)
+	received_commitments := make(map[int64][]byte, p.N-1)
+	for received := int64(0); received < p.N-1; {
+		msg, err := transport.Receive()
+		if err != nil {
+			return err
+		}
+		if err := p.validateIndex(msg.From); err != nil || msg.From == p.Position {
+			return fmt.Errorf("%w: %d", ErrInvalidDKGMsgSender, msg.From)
+		}
+
+		switch msg.Type {
+		case DKGMessageSecretShare:
+			early_shares = append(early_shares, msg)
+		case DKGMessageCommitments:
+			// the payload names its dealer, it is checked before the commitments are stored under it
+			if len(msg.Payload) < 8 || BytesToInt64(msg.Payload[:8]) != msg.From {
+				return fmt.Errorf("%w: dealer %d sent commitments of another dealer", ErrUnexpectedDKGMsg, msg.From)
+			}
+			if previous, ok := received_commitments[msg.From]; ok {
+				if !bytes.Equal(previous, msg.Payload) {
+					return fmt.Errorf("%w: dealer %d sent two commitment lists", ErrUnexpectedDKGMsg, msg.From)
+				}
+				continue
+			}
+			if err := p.UnmarshalPolynomialCommitments(msg.Payload); err != nil {
+				return fmt.Errorf("dealer %d: %w", msg.From, err)
+			}
+			proof, err := schnorr.ParseSignature(msg.Proof)
+			if err != nil {
+				return fmt.Errorf("%w: dealer %d: %v", ErrInvalidSecretProof, msg.From, err)
+			}
+			commitments, _ := p.GetPolynomialCommitments(msg.From)
+			if err := p.VerifySecretProofs(context_hash, proof, msg.From, commitments[0]); err != nil {
+				return err
+			}
+			received_commitments[msg.From] = msg.Payload
+			received++
+		default:
+			return fmt.Errorf("%w: type %d from %d", ErrUnexpectedDKGMsg, msg.Type, msg.From)
+		}
+	}
+
+	// round 2
+	p.CalculateSecretShares()
+	for posi := int64(1); posi <= p.N; posi++ {
+		if posi == p.Position {
+			continue
+		}
+		share := p.GetSecretShares(posi).Bytes()
+		err := transport.Broadcast(p.Position, DKGMessage{
+			Type:    DKGMessageSecretShare,
+			To:      posi,
+			Payload: share[:],
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	secret_shares := map[int64]*btcec.ModNScalar{
+		p.Position: p.GetSecretShares(p.Position),
+	}
+	for int64(len(secret_shares)) < p.N {
+		var msg DKGMessage
+		if len(early_shares) > 0 {
+			msg, early_shares = early_shares[0], early_shares[1:]
+		} else {
+			msg, err = transport.Receive()
+			if err != nil {
+				return err
+			}
+		}
+		if err := p.validateIndex(msg.From); err != nil || msg.From == p.Position {
+			return fmt.Errorf("%w: %d", ErrInvalidDKGMsgSender, msg.From)
+		}
+		if msg.Type == DKGMessageCommitments && bytes.Equal(received_commitments[msg.From], msg.Payload) {
+			continue
+		}
+		if msg.Type != DKGMessageSecretShare {
+			return fmt.Errorf("%w: type %d from %d", ErrUnexpectedDKGMsg, msg.Type, msg.From)
+		}
+		share := new(btcec.ModNScalar)
+		if len(msg.Payload) != 32 || share.SetByteSlice(msg.Payload) {
+			return fmt.Errorf("%w: dealer %d", ErrInvalidDKGMsgShare, msg.From)
+		}
+		if previous, ok := secret_shares[msg.From]; ok {
+			if !previous.Equals(share) {
+				return fmt.Errorf("%w: dealer %d sent two shares", ErrUnexpectedDKGMsg, msg.From)
+			}
+			continue
+		}
+		secret_shares[msg.From] = share
+	}
+
+	// verify shares, then derive signing share, public signing shares and group public key
+	p.DerivePowerMap()
+	if _, err := p.VerifyBatchPublicSecretShares(secret_shares, uint32(p.Position)); err != nil {
+		return err
+	}
+	signing_shares := p.CalculateSigningShares(secret_shares)
+	p.CalculateInternalPublicSigningShares(signing_shares, p.Position)
+	p.DeriveExternalQMap()
+	p.DeriveExternalWMap()
+	p.CalculateBatchPublicSigningShares(map[int64]bool{p.Position: true})
+	p.CalculateGroupPublicKey()
+
+	return nil
+}
@@ -17,7 +17,7 @@ func (s *TestSuite) GeneratePolynomial(degree int64) []*btcec.ModNScalar {
 	// the value a_0 is the secret, others should be able to retrieve the secret
 	for i := int64(0); i <= degree; i++ {
 		var coeff btcec.ModNScalar
-		int_secp256k1_rand, err := rand.Int(rand.Reader, btcec.S256().N)
+		int_secp256k1_rand, err := rand.Int(suiteRandReader{s}, btcec.S256().N)
 		assert.Nil(s.T, err)
 		coeff.SetByteSlice(int_secp256k1_rand.Bytes())
 		polynomial[i] = &coeff
@@ -48,6 +48,25 @@ func (s *TestSuite) EvaluatePolynomial(polynomial []*btcec.ModNScalar, x *btcec.
 	return result
 }
 
+// test oracle for signing shares, independent of the DKG protocol code
+// s_i = \sum_{j} f_j(i), f_j is the secret polynomial of dealer j
+// each polynomial is evaluated term by term rather than with Horner's Method
+func SigningShareFromPolynomials(dealer_polys map[int64][]*btcec.ModNScalar, index int64) *btcec.ModNScalar {
+	x := new(btcec.ModNScalar).SetInt(uint32(index))
+	signing_share := new(btcec.ModNScalar)
+	for _, polynomial := range dealer_polys {
+		x_power := new(btcec.ModNScalar).SetInt(1)
+		for _, coeff := range polynomial {
+			// a_k*x^k
+			term := new(btcec.ModNScalar).Mul2(coeff, x_power)
+			signing_share.Add(term)
+			x_power.Mul(x)
+		}
+	}
+
+	return signing_share
+}
+
 // calculate the Lagrange coefficient at i over a set
 // requires exact position, all values start with 1
 func (s *TestSuite) CalculateLagrangeCoeff(i int64, set []int64) *btcec.ModNScalar {
@@ -66,6 +85,95 @@ func (s *TestSuite) CalculateLagrangeCoeff(i int64, set []int64) *btcec.ModNScal
 	return mul_j
 }
 
+// calculate the Lagrange coefficients of all positions over a set with a single modular inversion
+// \lambda_i = \prod_{j != i} j / \prod_{j != i} (j - i)
+// numerators use prefix and suffix products, denominators are inverted with BatchInverse
+//
+// equals CalculateLagrangeCoeff for each i, which costs |set| - 1 inversions per coefficient
+func (s *TestSuite) CalculateLagrangeCoeffs(set []int64) map[int64]*btcec.ModNScalar {
+	n := len(set)
+	// prefix[k] = \prod_{l < k} set[l], suffix[k] = \prod_{l >= k} set[l]
+	prefix := make([]*btcec.ModNScalar, n+1)
+	suffix := make([]*btcec.ModNScalar, n+1)
+	prefix[0] = new(btcec.ModNScalar).SetInt(1)
+	suffix[n] = new(btcec.ModNScalar).SetInt(1)
+	for k := 0; k < n; k++ {
+		prefix[k+1] = new(btcec.ModNScalar).Mul2(prefix[k], new(btcec.ModNScalar).SetInt(uint32(set[k])))
+	}
+	for k := n - 1; k >= 0; k-- {
+		suffix[k] = new(btcec.ModNScalar).Mul2(suffix[k+1], new(btcec.ModNScalar).SetInt(uint32(set[k])))
+	}
+
+	denominators := make([]*btcec.ModNScalar, n)
+	for k, i := range set {
+		x_i := new(btcec.ModNScalar).SetInt(uint32(i))
+		denominator := new(btcec.ModNScalar).SetInt(1)
+		for _, j := range set {
+			if j != i {
+				// j - i
+				term := new(btcec.ModNScalar).NegateVal(x_i).Add(new(btcec.ModNScalar).SetInt(uint32(j)))
+				denominator.Mul(term)
+			}
+		}
+		denominators[k] = denominator
+	}
+	inverses := BatchInverse(denominators)
+
+	coefficients := make(map[int64]*btcec.ModNScalar, n)
+	for k, i := range set {
+		coefficients[i] = new(btcec.ModNScalar).Mul2(prefix[k], suffix[k+1]).Mul(inverses[k])
+	}
+
+	return coefficients
+}
+
+// Montgomery's trick, inverts all values with one inversion and 3 * (n - 1) multiplications
+// a_k = v_1 * ... * v_k, v_k^-1 = a_{k-1} * a_k^-1 and a_{k-1}^-1 = a_k^-1 * v_k
+//
+// values must be non zero
+func BatchInverse(values []*btcec.ModNScalar) []*btcec.ModNScalar {
+	n := len(values)
+	inverses := make([]*btcec.ModNScalar, n)
+	if n == 0 {
+		return inverses
+	}
+
+	// accumulated products a_k
+	products := make([]*btcec.ModNScalar, n)
+	products[0] = new(btcec.ModNScalar).Set(values[0])
+	for k := 1; k < n; k++ {
+		products[k] = new(btcec.ModNScalar).Mul2(products[k-1], values[k])
+	}
+
+	inverse := new(btcec.ModNScalar).InverseValNonConst(products[n-1])
+	for k := n - 1; k > 0; k-- {
+		inverses[k] = new(btcec.ModNScalar).Mul2(inverse, products[k-1])
+		inverse.Mul(values[k])
+	}
+	inverses[0] = inverse
+
+	return inverses
+}
+
+// calculate the Lagrange coefficient at i over a set, evaluated at x instead of 0
+// \lambda_i(x) = \prod_{j != i} (x - j) / (i - j)
+func (s *TestSuite) CalculateLagrangeCoeffAt(i, x int64, set []int64) *btcec.ModNScalar {
+	mul_j := new(btcec.ModNScalar).SetInt(1)
+	x_scalar := new(btcec.ModNScalar).SetInt(uint32(x))
+	x_i := new(btcec.ModNScalar).SetInt(uint32(i))
+	for _, j := range set {
+		if j != i {
+			x_j := new(btcec.ModNScalar).SetInt(uint32(j))
+			numerator := new(btcec.ModNScalar).NegateVal(x_j).Add(x_scalar)
+			denominator := new(btcec.ModNScalar).NegateVal(x_j).Add(x_i)
+			mul_j.Mul(numerator)
+			mul_j.Mul(denominator.InverseNonConst())
+		}
+	}
+
+	return mul_j
+}
+
 func Int64ToBytes(num int64) []byte {
 	bytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(bytes, uint64(num))
@@ -75,3 +183,21 @@ func Int64ToBytes(num int64) []byte {
 func BytesToInt64(bytes []byte) int64 {
 	return int64(binary.BigEndian.Uint64(bytes))
 }
+
+// -P = (x, -y)
+func negatePoint(point *btcec.JacobianPoint) *btcec.JacobianPoint {
+	neg := new(btcec.JacobianPoint)
+	neg.Set(point)
+	neg.ToAffine()
+	neg.Y.Negate(1).Normalize()
+
+	return neg
+}
+
+func serializePoint(point *btcec.JacobianPoint) []byte {
+	affine := new(btcec.JacobianPoint)
+	affine.Set(point)
+	affine.ToAffine()
+
+	return btcec.NewPublicKey(&affine.X, &affine.Y).SerializeCompressed()
+}
@@ -0,0 +1,128 @@
+package testhelper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ErrNonCanonicalPoint     = errors.New("parse point: non canonical encoding")
+	ErrMalformedCommitments  = errors.New("unmarshal polynomial commitments: malformed encoding")
+	ErrMissingOwnCommitments = errors.New("marshal polynomial commitments: own commitments not generated")
+)
+
+// serialize a protocol message and record its size for bandwidth planning
+// totals per message type are emitted by FlushBenchmarkThreadSafeReport
+func (s *TestSuite) MarshalMessage(msg proto.Message) ([]byte, error) {
+	msg_bytes, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	s.RecordMessageSize(string(msg.ProtoReflect().Descriptor().Name()), len(msg_bytes))
+
+	return msg_bytes, nil
+}
+
+func (s *TestSuite) RecordMessageSize(msg_type string, size int) {
+	value, _ := s.MessageSizeReport.LoadOrStore(msg_type, new(atomic.Int64))
+	value.(*atomic.Int64).Add(int64(size))
+}
+
+// total serialized bytes recorded for the message type since the last flush
+func (s *TestSuite) MessageBytes(msg_type string) int64 {
+	value, ok := s.MessageSizeReport.Load(msg_type)
+	if !ok {
+		return 0
+	}
+
+	return value.(*atomic.Int64).Load()
+}
+
+// parse a point received from an untrusted source
+// with StrictDecode, only the canonical SEC1 compressed encoding 0x02 / 0x03 || X is accepted,
+// otherwise uncompressed and hybrid encodings are accepted as well
+func (s *TestSuite) ParsePoint(data []byte) (*btcec.PublicKey, error) {
+	if s.StrictDecode {
+		if len(data) != btcec.PubKeyBytesLenCompressed {
+			return nil, ErrNonCanonicalPoint
+		}
+		if data[0] != secp.PubKeyFormatCompressedEven && data[0] != secp.PubKeyFormatCompressedOdd {
+			return nil, ErrNonCanonicalPoint
+		}
+	}
+
+	return btcec.ParsePubKey(data)
+}
+
+// position || k || A_0 || ... || A_{k-1}, position is 8 bytes, k is 4 bytes, A_j is a 33 bytes compressed point
+// the commitments of the participant as a dealer, sent to every other participant
+func (p *FrostParticipant) MarshalPolynomialCommitments() ([]byte, error) {
+	commitments, ok := p.GetPolynomialCommitments(p.Position)
+	if !ok || len(commitments) == 0 {
+		return nil, ErrMissingOwnCommitments
+	}
+
+	return marshalCommitments(p.Position, commitments), nil
+}
+
+func marshalCommitments(dealer int64, commitments []*btcec.PublicKey) []byte {
+	data := make([]byte, 0, 8+4+len(commitments)*btcec.PubKeyBytesLenCompressed)
+	data = append(data, Int64ToBytes(dealer)...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(commitments)))
+	for _, commitment := range commitments {
+		data = append(data, commitment.SerializeCompressed()...)
+	}
+
+	return data
+}
+
+// parse the commitments of a dealer and store them under the dealer position
+// the dealer polynomial must have degree Threshold, every point must be a compressed point on the curve
+func (p *FrostParticipant) UnmarshalPolynomialCommitments(data []byte) error {
+	dealer, commitments, err := p.parseCommitments(data)
+	if err != nil {
+		return err
+	}
+	p.UpdatePolynomialCommitments(dealer, commitments)
+
+	return nil
+}
+
+func (p *FrostParticipant) parseCommitments(data []byte) (int64, []*btcec.PublicKey, error) {
+	if len(data) < 8+4 {
+		return 0, nil, ErrMalformedCommitments
+	}
+	dealer := BytesToInt64(data[:8])
+	if err := p.validateIndex(dealer); err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrMalformedCommitments, err)
+	}
+	count := int64(binary.BigEndian.Uint32(data[8:12]))
+	if count != p.Threshold+1 {
+		return 0, nil, fmt.Errorf("%w: %d coefficients, expected %d", ErrMalformedCommitments, count, p.Threshold+1)
+	}
+	points := data[12:]
+	if int64(len(points)) != count*btcec.PubKeyBytesLenCompressed {
+		return 0, nil, fmt.Errorf("%w: %d bytes of points for %d coefficients", ErrMalformedCommitments, len(points), count)
+	}
+
+	commitments := make([]*btcec.PublicKey, count)
+	for j := int64(0); j < count; j++ {
+		point := points[j*btcec.PubKeyBytesLenCompressed : (j+1)*btcec.PubKeyBytesLenCompressed]
+		if point[0] != secp.PubKeyFormatCompressedEven && point[0] != secp.PubKeyFormatCompressedOdd {
+			return 0, nil, fmt.Errorf("%w: coefficient %d", ErrMalformedCommitments, j)
+		}
+		commitment, err := btcec.ParsePubKey(point)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%w: coefficient %d: %v", ErrMalformedCommitments, j, err)
+		}
+		commitments[j] = commitment
+	}
+
+	return dealer, commitments, nil
+}
@@ -0,0 +1,126 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// below this many terms, separate scalar multiplications are cheaper than the bucket setup
+const msmNaiveThreshold = 4
+
+var ErrMSMLengthMismatch = errors.New("multi scalar mul: scalar and point counts differ")
+
+// \sum_{i} k_i * P_i with the Pippenger bucket method
+// scalars are cut into windows of c bits, in each window every point is added once into the bucket of its window value,
+// then buckets are summed with weights 1 .. 2^c - 1 through a running sum
+// cost is about (256 / c) * (n + 2^(c + 1)) additions and 256 doublings, against n full scalar multiplications
+// a term without scalar or without point is an error, it is never dropped silently
+func MultiScalarMul(scalars []*btcec.ModNScalar, points []*btcec.PublicKey) (*btcec.JacobianPoint, error) {
+	jacobian_points := make([]*btcec.JacobianPoint, len(points))
+	for i, point := range points {
+		jacobian_points[i] = new(btcec.JacobianPoint)
+		point.AsJacobian(jacobian_points[i])
+	}
+
+	return multiScalarMulJacobian(scalars, jacobian_points)
+}
+
+func multiScalarMulJacobian(scalars []*btcec.ModNScalar, points []*btcec.JacobianPoint) (*btcec.JacobianPoint, error) {
+	if len(scalars) != len(points) {
+		return nil, fmt.Errorf("%w: %d scalars, %d points", ErrMSMLengthMismatch, len(scalars), len(points))
+	}
+	result := new(btcec.JacobianPoint)
+	n := len(points)
+	if n < msmNaiveThreshold {
+		for i := 0; i < n; i++ {
+			term := new(btcec.JacobianPoint)
+			btcec.ScalarMultNonConst(scalars[i], points[i], term)
+			btcec.AddNonConst(result, term, result)
+		}
+		return result, nil
+	}
+
+	// c ~ log2(n) - 2 balances bucket accumulation against bucket summation
+	c := bits.Len(uint(n)) - 2
+	if c < 2 {
+		c = 2
+	}
+	scalar_bytes := make([][32]byte, n)
+	for i := 0; i < n; i++ {
+		scalar_bytes[i] = scalars[i].Bytes()
+	}
+
+	windows := (256 + c - 1) / c
+	buckets := make([]btcec.JacobianPoint, 1<<c)
+	for w := windows - 1; w >= 0; w-- {
+		for i := 0; i < c; i++ {
+			btcec.DoubleNonConst(result, result)
+		}
+
+		for v := range buckets {
+			buckets[v] = btcec.JacobianPoint{}
+		}
+		for i := 0; i < n; i++ {
+			if v := windowValue(&scalar_bytes[i], w*c, c); v != 0 {
+				btcec.AddNonConst(&buckets[v], points[i], &buckets[v])
+			}
+		}
+
+		// \sum_{v} v * B_v = \sum_{v} (B_{2^c - 1} + ... + B_v)
+		running := new(btcec.JacobianPoint)
+		window_sum := new(btcec.JacobianPoint)
+		for v := len(buckets) - 1; v > 0; v-- {
+			btcec.AddNonConst(running, &buckets[v], running)
+			btcec.AddNonConst(window_sum, running, window_sum)
+		}
+		btcec.AddNonConst(result, window_sum, result)
+	}
+
+	return result, nil
+}
+
+// bits [start, start + c) of a big endian 256 bits scalar, bit 0 is the least significant
+func windowValue(scalar *[32]byte, start, c int) int {
+	value := 0
+	for k := 0; k < c; k++ {
+		bit := start + k
+		if bit >= 256 {
+			break
+		}
+		if scalar[31-bit/8]>>(bit%8)&1 == 1 {
+			value |= 1 << k
+		}
+	}
+
+	return value
+}
+
+// \sum_{k} x^k * P_k with Horner's rule in the exponent, for a small x such as a participant position
+// each step multiplies by x with log2(x) doublings, instead of a 256 bits scalar multiplication per term
+// for positions up to 1000, it is several times faster than MultiScalarMul over the powers of x
+func hornerSmall(points []*btcec.JacobianPoint, x uint32) *btcec.JacobianPoint {
+	result := new(btcec.JacobianPoint)
+	for k := len(points) - 1; k >= 0; k-- {
+		mulSmall(x, result, result)
+		btcec.AddNonConst(result, points[k], result)
+	}
+
+	return result
+}
+
+// x * P with double and add over the bits of x
+func mulSmall(x uint32, point, result *btcec.JacobianPoint) {
+	base := new(btcec.JacobianPoint)
+	base.Set(point)
+	product := new(btcec.JacobianPoint)
+	for bit := bits.Len32(x) - 1; bit >= 0; bit-- {
+		btcec.DoubleNonConst(product, product)
+		if x>>bit&1 == 1 {
+			btcec.AddNonConst(product, base, product)
+		}
+	}
+	result.Set(product)
+}
@@ -0,0 +1,58 @@
+package testhelper
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SimNetwork injects an artificial delay for each delivered message
+// to model network latency of real deployments on a single machine
+//
+// deliveries are sequential, thus the injected network time is Delay * messages
+type SimNetwork struct {
+	Delay time.Duration
+
+	messages     atomic.Int64
+	network_time atomic.Int64
+}
+
+func NewSimNetwork(delay time.Duration) *SimNetwork {
+	return &SimNetwork{
+		Delay: delay,
+	}
+}
+
+// deliver count messages, blocking for the injected delay of each
+func (n *SimNetwork) Deliver(count int64) {
+	if n == nil || count <= 0 {
+		return
+	}
+
+	injected := n.Delay * time.Duration(count)
+	time.Sleep(injected)
+	n.messages.Add(count)
+	n.network_time.Add(int64(injected))
+}
+
+func (n *SimNetwork) Messages() int64 {
+	if n == nil {
+		return 0
+	}
+	return n.messages.Load()
+}
+
+// total injected delay, the wall clock spent in Deliver is at least this long
+func (n *SimNetwork) NetworkTime() time.Duration {
+	if n == nil {
+		return 0
+	}
+	return time.Duration(n.network_time.Load())
+}
+
+func (n *SimNetwork) Reset() {
+	if n == nil {
+		return
+	}
+	n.messages.Store(0)
+	n.network_time.Store(0)
+}
@@ -0,0 +1,93 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"sync/atomic"
+)
+
+var (
+	ErrNonNumericMetric = errors.New("write prometheus: non numeric metric value")
+
+	invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+)
+
+// emit recorded benchmark metrics in Prometheus text exposition format
+// benchmark reports are gauges, e.g. ms/derive-external-q-w-map becomes ms_derive_external_q_w_map
+// message sizes are a counter message_bytes_total labeled by message type
+func (s *TestSuite) WritePrometheus(w io.Writer) error {
+	reports := make(map[string]float64)
+	var err error
+	s.BenchmarkThreadSafeReport.Range(func(key, value interface{}) bool {
+		var sample float64
+		switch v := value.(type) {
+		case int:
+			sample = float64(v)
+		case int64:
+			sample = float64(v)
+		case uint64:
+			sample = float64(v)
+		case float32:
+			sample = float64(v)
+		case float64:
+			sample = v
+		default:
+			err = fmt.Errorf("%w: %v", ErrNonNumericMetric, key)
+			return false
+		}
+		reports[prometheusMetricName(fmt.Sprint(key))] = sample
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(reports))
+	for name := range reports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "# HELP %s benchmark report %s\n# TYPE %s gauge\n%s %v\n", name, name, name, name, reports[name]); err != nil {
+			return err
+		}
+	}
+
+	message_bytes := make(map[string]int64)
+	s.MessageSizeReport.Range(func(key, value interface{}) bool {
+		message_bytes[key.(string)] = value.(*atomic.Int64).Load()
+		return true
+	})
+	if len(message_bytes) == 0 {
+		return nil
+	}
+
+	msg_types := make([]string, 0, len(message_bytes))
+	for msg_type := range message_bytes {
+		msg_types = append(msg_types, msg_type)
+	}
+	sort.Strings(msg_types)
+	if _, err := fmt.Fprint(w, "# HELP message_bytes_total total serialized bytes per message type\n# TYPE message_bytes_total counter\n"); err != nil {
+		return err
+	}
+	for _, msg_type := range msg_types {
+		if _, err := fmt.Fprintf(w, "message_bytes_total{type=%q} %d\n", msg_type, message_bytes[msg_type]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// metric names must match [a-zA-Z_:][a-zA-Z0-9_:]*
+func prometheusMetricName(key string) string {
+	name := invalidMetricNameChars.ReplaceAllString(key, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+
+	return name
+}
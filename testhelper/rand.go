@@ -0,0 +1,89 @@
+package testhelper
+
+import (
+	crand "crypto/rand"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+// randomness of test helpers is crypto/rand
+// a test may inject a seeded source into its own suite to reproduce values, see SetTestRandSource
+// the source belongs to the suite, thus other suites keep crypto/rand
+
+// seeded math/rand generator, only constructed under go test
+// math/rand sources are not safe for concurrent use, thus reads are serialized
+type testRandSource struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// deterministic source of randomness from the seed, for reproducible tests only
+// panics outside of go test, as values drawn from it, e.g. secret coefficients, are predictable
+func NewTestRandSource(seed int64) io.Reader {
+	if !testing.Testing() {
+		panic("testhelper: seeded randomness outside of go test")
+	}
+
+	return &testRandSource{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (r *testRandSource) Read(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.rng.Read(b)
+}
+
+// draw the suite randomness from source instead of crypto/rand, nil restores crypto/rand
+// must be called before the suite is shared with participants running concurrently
+func (s *TestSuite) SetTestRandSource(source io.Reader) {
+	if source != nil && !testing.Testing() {
+		panic("testhelper: injected randomness outside of go test")
+	}
+	s.rand_source = source
+}
+
+// read from the suite source of randomness
+func (s *TestSuite) readRand(b []byte) {
+	source := s.rand_source
+	if source == nil {
+		source = crand.Reader
+	}
+	if _, err := io.ReadFull(source, b); err != nil {
+		panic(err)
+	}
+}
+
+// io.Reader over the suite source of randomness
+type suiteRandReader struct {
+	suite *TestSuite
+}
+
+func (r suiteRandReader) Read(b []byte) (int, error) {
+	r.suite.readRand(b)
+	return len(b), nil
+}
+
+// a random 32 bytes message for signing tests
+func (s *TestSuite) RandomMessage() [32]byte {
+	var message [32]byte
+	s.readRand(message[:])
+
+	return message
+}
+
+// a uniform non - zero scalar from the suite source of randomness, out of range values are redrawn
+func (s *TestSuite) randomScalar() *btcec.ModNScalar {
+	var scalar_bytes [32]byte
+	scalar := new(btcec.ModNScalar)
+	for {
+		s.readRand(scalar_bytes[:])
+		if overflow := scalar.SetByteSlice(scalar_bytes[:]); !overflow && !scalar.IsZero() {
+			return scalar
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package testhelper
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+)
+
+// ReportSnapshot is a copy of the benchmark reports of a TestSuite at some point of a run
+// Metrics are the values of BenchmarkThreadSafeReport, MessageBytes the totals of MessageSizeReport
+type ReportSnapshot struct {
+	Metrics      map[interface{}]interface{}
+	MessageBytes map[string]int64
+}
+
+// checkpoint the reports, e.g. before a phase or a sub benchmark
+func (s *TestSuite) SnapshotReport() ReportSnapshot {
+	snapshot := ReportSnapshot{
+		Metrics:      make(map[interface{}]interface{}),
+		MessageBytes: make(map[string]int64),
+	}
+	s.BenchmarkThreadSafeReport.Range(func(key, value interface{}) bool {
+		snapshot.Metrics[key] = value
+		return true
+	})
+	s.MessageSizeReport.Range(func(key, value interface{}) bool {
+		snapshot.MessageBytes[key.(string)] = value.(*atomic.Int64).Load()
+		return true
+	})
+
+	return snapshot
+}
+
+// roll the reports back to the snapshot, whatever was logged since is dropped
+func (s *TestSuite) RestoreReport(snapshot ReportSnapshot) {
+	s.BenchmarkThreadSafeReport.Range(func(key, _ interface{}) bool {
+		s.BenchmarkThreadSafeReport.Delete(key)
+		return true
+	})
+	s.MessageSizeReport.Range(func(key, _ interface{}) bool {
+		s.MessageSizeReport.Delete(key)
+		return true
+	})
+
+	for key, value := range snapshot.Metrics {
+		s.BenchmarkThreadSafeReport.Store(key, value)
+	}
+	for msg_type, size := range snapshot.MessageBytes {
+		total := new(atomic.Int64)
+		total.Store(size)
+		s.MessageSizeReport.Store(msg_type, total)
+	}
+}
+
+// the contribution of the phase between both snapshots
+// metrics logged or changed in later, and the message bytes recorded since r
+func (r ReportSnapshot) Diff(later ReportSnapshot) ReportSnapshot {
+	diff := ReportSnapshot{
+		Metrics:      make(map[interface{}]interface{}),
+		MessageBytes: make(map[string]int64),
+	}
+	for key, value := range later.Metrics {
+		if earlier, ok := r.Metrics[key]; !ok || !reflect.DeepEqual(earlier, value) {
+			diff.Metrics[key] = value
+		}
+	}
+	for msg_type, size := range later.MessageBytes {
+		if delta := size - r.MessageBytes[msg_type]; delta != 0 {
+			diff.MessageBytes[msg_type] = delta
+		}
+	}
+
+	return diff
+}
+
+// time fn only, setup before and after the section stays out of the report
+// the duration is logged as ms/<name>, sections of the same name add up, e.g. a section inside a loop
+// the benchmark timer is left running, pause it around setup that b.N iterations must not measure
+func (s *TestSuite) TimedSection(name string, fn func()) {
+	start := time.Now()
+	fn()
+	elapsed := float64(time.Since(start).Microseconds()) / 1000
+
+	key := "ms/" + name
+	for {
+		previous, loaded := s.BenchmarkThreadSafeReport.LoadOrStore(key, elapsed)
+		if !loaded {
+			return
+		}
+		total, ok := previous.(float64)
+		if !ok {
+			s.BenchmarkThreadSafeReport.Store(key, elapsed)
+			return
+		}
+		if s.BenchmarkThreadSafeReport.CompareAndSwap(key, previous, total+elapsed) {
+			return
+		}
+	}
+}
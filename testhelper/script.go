@@ -1,6 +1,11 @@
 package testhelper
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
 	"github.com/btcsuite/btcd/blockchain"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -9,6 +14,10 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var (
+	ErrMissingWitness = errors.New("serialize signed tx: missing witness")
+)
+
 // validate script creates a funding transaction and a spending transaction
 // the funding transaction will send funds to the test script
 // the spending transaction will spend the funds from the funding transaction with test witness
@@ -97,3 +106,20 @@ func (s *TestSuite) NewMockFirstTx(pkScript []byte, value int64) *wire.MsgTx {
 
 	return tx
 }
+
+// serialize a signed transaction to hex, ready for broadcast
+// all inputs are expected to spend segwit outputs, thus must have their witnesses populated
+func (s *TestSuite) SerializeSignedTx(tx *wire.MsgTx) (string, error) {
+	for i, txIn := range tx.TxIn {
+		if len(txIn.Witness) == 0 {
+			return "", fmt.Errorf("input %d: %w", i, ErrMissingWitness)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf.Bytes()), nil
+}
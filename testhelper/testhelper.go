@@ -2,8 +2,11 @@ package testhelper
 
 import (
 	"encoding/hex"
+	"fmt"
+	"io"
 	"log"
 	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/btcsuite/btcd/blockchain"
@@ -25,6 +28,14 @@ type TestSuite struct {
 
 	Logger                    *log.Logger
 	BenchmarkThreadSafeReport sync.Map
+	// total serialized bytes per message type
+	MessageSizeReport sync.Map
+	// reject non canonical point encodings in ParsePoint
+	StrictDecode bool
+	// curve operations of Frost participants, see CurveOpCounts
+	curve_ops curveOpCounter
+	// seeded source injected by a test, crypto/rand when nil
+	rand_source io.Reader
 
 	// this is for bitcoin live network
 	ChainClient       *rpcclient.Client
@@ -113,8 +124,13 @@ func (s *TestSuite) FlushBenchmarkThreadSafeReport() {
 		s.Logger.Println(key, value)
 		return true
 	})
+	s.MessageSizeReport.Range(func(key, value interface{}) bool {
+		s.Logger.Println(fmt.Sprintf("bytes/%s", key), value.(*atomic.Int64).Load())
+		return true
+	})
 
 	s.BenchmarkThreadSafeReport = sync.Map{}
+	s.MessageSizeReport = sync.Map{}
 }
 
 func (s *TestSuite) LogBenchmarkThreadSafeReport(key, value interface{}, isLater bool) {
@@ -140,11 +156,10 @@ func (s *TestSuite) GenerateSeed() []byte {
 	return seed
 }
 
+// crypto/rand unless a test injected a seeded source, see SetTestRandSource
 func (s *TestSuite) Generate32BSeed() [hdkeychain.RecommendedSeedLen]byte {
 	var res [hdkeychain.RecommendedSeedLen]byte
-	seed, err := hdkeychain.GenerateSeed(hdkeychain.RecommendedSeedLen)
-	assert.Nil(s.T, err)
-	copy(res[:], seed)
+	s.readRand(res[:])
 	return res
 }
 
@@ -1,6 +1,8 @@
 package testhelper
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/rand"
 	"sync"
@@ -13,11 +15,20 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+var (
+	ErrWeightedCoefficientMismatch = errors.New("aggregate weighted partial signatures: weighted lagrange coefficients do not sum to 1")
+	ErrWeightTotalMismatch         = errors.New("derive weighted range of keys: weights do not sum to the number of keys")
+	ErrZeroWeight                  = errors.New("derive weighted range of keys: participant without keys")
+	ErrInvalidWeightPosition       = errors.New("derive weighted range of keys: participant positions must be 1 to n_p")
+)
+
 type WstsParticipant struct {
 	suite *TestSuite
 
 	secret_shares  sync.Map
 	signing_shares sync.Map
+	// weighted signing rounds keyed by WeightedSessionID
+	signing_rounds sync.Map
 
 	N_p   int64
 	Keys  map[int64]map[int64]bool
@@ -39,6 +50,12 @@ func (wsts *WstsParticipant) LoadKeyRange(keys map[int64]map[int64]bool) {
 	wsts.Keys = keys
 }
 
+// number of secret shares this participant must store after DKG
+// for each of its own keys, one share f_m(key) from each of the n_p dealers
+func (wsts *WstsParticipant) ExpectedShareCount(n_p int64) int64 {
+	return int64(len(wsts.Keys[wsts.Frost.Position])) * n_p
+}
+
 func (wsts *WstsParticipant) StoreSigningShares(key int64, signing_share *btcec.ModNScalar) {
 	wsts.signing_shares.Store(key, signing_share)
 }
@@ -102,6 +119,72 @@ func (wsts *WstsParticipant) CalculateSigningShares() {
 	wg.Wait()
 }
 
+// honest keys of all signers
+func (wsts *WstsParticipant) signerKeys(signers map[int64]bool) []int64 {
+	honest_keys := make([]int64, 0)
+	for index, ok := range signers {
+		if !ok {
+			continue
+		}
+		for key := range wsts.Keys[index] {
+			honest_keys = append(honest_keys, key)
+		}
+	}
+
+	return honest_keys
+}
+
+// weight of this participant contribution over the signers
+// \lambda_i = \sum_{K_i} \lambda_{ik}, \lambda_{ik} is the Lagrange coefficient of key k over the honest keys of all signers
+// a participant owning more keys carries more weight, the weights of all signers sum to 1
+func (wsts *WstsParticipant) WeightedLagrangeCoefficient(signers map[int64]bool) *btcec.ModNScalar {
+	return wsts.weightedLagrangeCoefficient(wsts.Frost.Position, wsts.signerKeys(signers))
+}
+
+func (wsts *WstsParticipant) weightedLagrangeCoefficient(posi int64, honest_keys []int64) *btcec.ModNScalar {
+	lambda := new(btcec.ModNScalar)
+	for key := range wsts.Keys[posi] {
+		lambda.Add(wsts.suite.CalculateLagrangeCoeff(key, honest_keys))
+	}
+
+	return lambda
+}
+
+// z = \sum_{i \in S} z_i, S is the set of signers
+// the weighted coefficients of the signers must sum to 1, otherwise key ranges are inconsistent
+// and the signature can never verify under the group public key
+func (wsts *WstsParticipant) AggregateWeightedPartialSignatures(signing_index int64, partial_sigs map[int64]*schnorr.Signature) (*schnorr.Signature, error) {
+	signers := make(map[int64]bool)
+	for posi := range partial_sigs {
+		signers[posi] = true
+	}
+	honest_keys := wsts.signerKeys(signers)
+	if int64(len(honest_keys)) <= wsts.Frost.Threshold {
+		return nil, ErrThresholdNotMet
+	}
+
+	total_weight := new(btcec.ModNScalar)
+	for posi := range signers {
+		total_weight.Add(wsts.weightedLagrangeCoefficient(posi, honest_keys))
+	}
+	if !total_weight.Equals(new(btcec.ModNScalar).SetInt(1)) {
+		return nil, ErrWeightedCoefficientMismatch
+	}
+
+	R, ok := wsts.Frost.AggrNonceCommitment[signing_index]
+	if !assert.True(wsts.suite.T, ok, "aggregate weighted partial signatures: missing aggregated nonce commitment") {
+		return nil, ErrInvalidNonce
+	}
+	z := new(btcec.ModNScalar)
+	for _, p_sig := range partial_sigs {
+		z_i := new(btcec.ModNScalar)
+		z_i.SetByteSlice(p_sig.Serialize()[32:64])
+		z.Add(z_i)
+	}
+
+	return schnorr.NewSignature(&R.X, z), nil
+}
+
 // construct z_i = d_i + e_i * p_i + \sum_{K_i} \lambda_{ik} * s_{ik} * c, K_i is the threshold set of honest keys of participant i
 // \lambda_i is the Lagrange coefficient for the participant i over the honest participants
 // s_i is the long-term secret share of participant i
@@ -119,13 +202,7 @@ func (wsts *WstsParticipant) WeightedPartialSign(signing_index int64, honest_par
 	}
 
 	// calculate c
-	commitment_data := make([]byte, 0)
-	commitment_data = append(commitment_data, wsts.Frost.AggrNonceCommitment[signing_index].X.Bytes()[:]...)
-	commitment_data = append(commitment_data, schnorr.SerializePubKey(wsts.Frost.GroupPublicKey)...)
-	commitment_data = append(commitment_data, message_hash[:]...)
-	commitment_hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, commitment_data)
-	c := new(btcec.ModNScalar)
-	c.SetByteSlice(commitment_hash[:])
+	c := bip340Challenge(wsts.Frost.AggrNonceCommitment[signing_index], wsts.Frost.GroupPublicKey, message_hash)
 
 	// calculate p_i
 	p_i_data := make([]byte, 0)
@@ -222,13 +299,7 @@ func (wsts *WstsParticipant) WeightedPartialVerification(sig *schnorr.Signature,
 	z.SetByteSlice(z_bytes)
 
 	// calculate c = H(R, Y, m)
-	commitment_data := make([]byte, 0)
-	commitment_data = append(commitment_data, wsts.Frost.AggrNonceCommitment[signing_index].X.Bytes()[:]...)
-	commitment_data = append(commitment_data, schnorr.SerializePubKey(wsts.Frost.GroupPublicKey)...)
-	commitment_data = append(commitment_data, message_hash[:]...)
-	commitment_hash := chainhash.TaggedHash(chainhash.TagBIP0340Challenge, commitment_data)
-	c := new(btcec.ModNScalar)
-	c.SetByteSlice(commitment_hash[:])
+	c := bip340Challenge(wsts.Frost.AggrNonceCommitment[signing_index], wsts.Frost.GroupPublicKey, message_hash)
 
 	c.Negate()
 
@@ -353,3 +424,55 @@ func (s *TestSuite) DeriveRangeOfKeys(keys []int64) map[int64][2]int64 {
 
 	return range_keys
 }
+
+// explicit weights, participant i owns weights[i] keys, e.g. 300 keys for one participant and 50 for another
+// ranges [start, end) follow the order of positions, keys are numbered from 1 to n_keys
+func (s *TestSuite) DeriveWeightedRangeOfKeys(n_keys int64, weights map[int64]int64) (map[int64][2]int64, error) {
+	n_p := int64(len(weights))
+	total := int64(0)
+	for posi := int64(1); posi <= n_p; posi++ {
+		weight, ok := weights[posi]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %d", ErrInvalidWeightPosition, posi)
+		}
+		if weight <= 0 {
+			return nil, fmt.Errorf("%w: %d", ErrZeroWeight, posi)
+		}
+		total += weight
+	}
+	if total != n_keys {
+		return nil, fmt.Errorf("%w: %d, expected %d", ErrWeightTotalMismatch, total, n_keys)
+	}
+
+	keys := make([]int64, n_p)
+	for posi := int64(1); posi <= n_p; posi++ {
+		keys[posi-1] = weights[posi]
+	}
+
+	return s.DeriveRangeOfKeys(keys), nil
+}
+
+// same shares of keys as DeriveRangeOfKeys, but keys are permuted reproducibly by the seed
+// ownership is non - contiguous, participant i owns keys[i - 1] keys
+// the result is ready for LoadKeyRange
+func (s *TestSuite) DeriveRangeOfKeysSeeded(keys []int64, seed int64) map[int64]map[int64]bool {
+	n_keys := int64(0)
+	for _, count := range keys {
+		n_keys += count
+	}
+
+	randsource := rand.New(rand.NewSource(seed))
+	permutation := randsource.Perm(int(n_keys))
+
+	range_keys := make(map[int64]map[int64]bool)
+	next := 0
+	for i := range keys {
+		range_keys[int64(i)+1] = make(map[int64]bool)
+		for j := int64(0); j < keys[i]; j++ {
+			range_keys[int64(i)+1][int64(permutation[next])+1] = true
+			next++
+		}
+	}
+
+	return range_keys
+}
@@ -0,0 +1,271 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	secp "github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+var (
+	TagWSTSSession = []byte("WSTS/session")
+
+	ErrMissingWeightedRound     = errors.New("weighted signing: no nonce commitments for the session")
+	ErrNotWeightedSigner        = errors.New("weighted signing: participant is not in the signer set")
+	ErrInvalidWeightedShare     = errors.New("aggregate weighted signature shares: invalid signature share")
+	ErrMissingWeightedPublicKey = errors.New("aggregate weighted signature shares: missing public signing share of a key")
+)
+
+// state of a weighted signing round, one per session
+// the nonce pair of the participant is bound to the message, R is derived once all signers committed
+type weightedSigningRound struct {
+	signing_index int64
+	signers       []int64
+	nonces        map[int64]*NonceCommitment
+	R             *btcec.JacobianPoint
+}
+
+// a session is the message with its signer set, H(m || S)
+// thus two rounds over the same message with different signers do not share state
+func WeightedSessionID(msg [32]byte, signers map[int64]bool) [32]byte {
+	set := signerSet(signers)
+	data := make([]byte, 0, 32+8*len(set))
+	data = append(data, msg[:]...)
+	for _, posi := range set {
+		data = append(data, Int64ToBytes(posi)...)
+	}
+
+	return *chainhash.TaggedHash(TagWSTSSession, data)
+}
+
+// first round, the participant commits to a fresh nonce pair (D_i, E_i) bound to msg and the signers
+// one nonce pair is used for all keys of the participant, thus a participant signs once whatever its weight
+func (wsts *WstsParticipant) CommitWeightedNonces(msg [32]byte, signers map[int64]bool) *NonceCommitment {
+	signing_index, nonce_commitments := wsts.Frost.PreprocessNoncesForSighash(msg)
+	wsts.signing_rounds.Store(WeightedSessionID(msg, signers), &weightedSigningRound{signing_index: signing_index, signers: signerSet(signers)})
+
+	return &NonceCommitment{D: nonce_commitments[0], E: nonce_commitments[1]}
+}
+
+// nonce commitments of all signers of msg, keyed by participant position
+// the signers of the session are the positions of the commitments
+// R = \prod_{i \in S} D_i * E_i^p_i, S is the set of signers
+func (wsts *WstsParticipant) ReceiveWeightedNonces(msg [32]byte, nonces map[int64]*NonceCommitment) error {
+	public_nonces, honest, err := weightedPublicNonces(nonces)
+	if err != nil {
+		return err
+	}
+	session_id := WeightedSessionID(msg, positionSet(honest))
+	value, ok := wsts.signing_rounds.Load(session_id)
+	if !ok {
+		return ErrMissingWeightedRound
+	}
+	round := value.(*weightedSigningRound)
+	if !equalSignerSets(round.signers, honest) {
+		return fmt.Errorf("%w: signer set does not match the nonce commitments", ErrMissingNonceCommitment)
+	}
+	if _, err := wsts.Frost.CalculatePublicNonceCommitments(round.signing_index, honest, msg, public_nonces); err != nil {
+		return err
+	}
+	R, _ := wsts.Frost.aggrNonceCommitment(round.signing_index)
+	wsts.signing_rounds.Store(session_id, &weightedSigningRound{signing_index: round.signing_index, signers: round.signers, nonces: nonces, R: R})
+
+	return nil
+}
+
+// z_i = d_i + e_i * p_i + c * \sum_{K_i} \lambda_k * s_k, K_i is the key range of participant i
+// \lambda_k is the Lagrange coefficient of key k over the keys of all signers, c = H(R, Y, m)
+// a participant holding 50 keys adds 50 terms, thus contributes as 50 FROST signers with a single nonce
+//
+// d_i, e_i are negated when R has an odd Y coordinate, s_k when the group public key has
+func (wsts *WstsParticipant) CalculateWeightedSignatureShare(msg [32]byte, signers map[int64]bool) (*btcec.ModNScalar, error) {
+	if !signers[wsts.Frost.Position] {
+		return nil, fmt.Errorf("%w: %d", ErrNotWeightedSigner, wsts.Frost.Position)
+	}
+	honest_keys := wsts.signerKeys(signers)
+	if int64(len(honest_keys)) <= wsts.Frost.Threshold {
+		return nil, ErrThresholdNotMet
+	}
+	value, ok := wsts.signing_rounds.Load(WeightedSessionID(msg, signers))
+	if !ok || value.(*weightedSigningRound).R == nil {
+		return nil, ErrMissingWeightedRound
+	}
+	round := value.(*weightedSigningRound)
+	public_nonces, honest, err := weightedPublicNonces(round.nonces)
+	if err != nil {
+		return nil, err
+	}
+	if !equalSignerSets(honest, signerSet(signers)) {
+		return nil, fmt.Errorf("%w: signer set does not match the nonce commitments", ErrMissingNonceCommitment)
+	}
+
+	// the nonce pair signs a single time, as in PartialSign
+	p := wsts.Frost
+	p.sessions_mu.Lock()
+	err = p.checkNonceBinding(round.signing_index, msg)
+	if err == nil {
+		err = p.checkNonceFreshness(round.signing_index)
+	}
+	var nonces [2]*btcec.ModNScalar
+	if err == nil {
+		nonces = p.takeNonces(round.signing_index, nonceSessionBinding(msg, honest, public_nonces))
+	}
+	p.sessions_mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer zeroNonces(nonces)
+
+	// d_i + e_i * p_i
+	z_i := new(btcec.ModNScalar).Mul2(nonces[1], bindingFactor(p.Position, msg, honest, public_nonces))
+	z_i.Add(nonces[0])
+	if round.R.Y.IsOdd() {
+		z_i.Negate()
+	}
+
+	// \sum_{K_i} \lambda_k * s_k
+	lambdas := wsts.suite.CalculateLagrangeCoeffs(honest_keys)
+	weighted_share := new(btcec.ModNScalar)
+	for key := range wsts.Keys[p.Position] {
+		term := new(btcec.ModNScalar).Mul2(lambdas[key], wsts.GetSigningShares(key))
+		weighted_share.Add(term)
+	}
+	if p.GroupPublicKey.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd {
+		weighted_share.Negate()
+	}
+
+	c := bip340Challenge(round.R, p.GroupPublicKey, msg)
+	z_i.Add(weighted_share.Mul(c))
+
+	return z_i, nil
+}
+
+// z = \sum_{i \in S} z_i, each z_i is checked before aggregation
+// g^z_i = R_i + c * \sum_{K_i} \lambda_k * Y_k, with the same parity adjustments as the signer
+// the weighted coefficients of the signers must sum to 1, otherwise key ranges are inconsistent
+func (wsts *WstsParticipant) AggregateWeightedSignatureShares(msg [32]byte, shares map[int64]*btcec.ModNScalar) (*schnorr.Signature, error) {
+	signers := make(map[int64]bool, len(shares))
+	for posi := range shares {
+		signers[posi] = true
+	}
+	honest_keys := wsts.signerKeys(signers)
+	if int64(len(honest_keys)) <= wsts.Frost.Threshold {
+		return nil, ErrThresholdNotMet
+	}
+	value, ok := wsts.signing_rounds.Load(WeightedSessionID(msg, signers))
+	if !ok || value.(*weightedSigningRound).R == nil {
+		return nil, ErrMissingWeightedRound
+	}
+	round := value.(*weightedSigningRound)
+	public_nonces, honest, err := weightedPublicNonces(round.nonces)
+	if err != nil {
+		return nil, err
+	}
+	if !equalSignerSets(honest, signerSet(signers)) {
+		return nil, fmt.Errorf("%w: signer set does not match the nonce commitments", ErrMissingNonceCommitment)
+	}
+
+	lambdas := wsts.suite.CalculateLagrangeCoeffs(honest_keys)
+	total_weight := new(btcec.ModNScalar)
+	for _, lambda := range lambdas {
+		total_weight.Add(lambda)
+	}
+	if !total_weight.Equals(new(btcec.ModNScalar).SetInt(1)) {
+		return nil, ErrWeightedCoefficientMismatch
+	}
+
+	c := bip340Challenge(round.R, wsts.Frost.GroupPublicKey, msg)
+	odd_group_key := wsts.Frost.GroupPublicKey.SerializeCompressed()[0] == secp.PubKeyFormatCompressedOdd
+	z := new(btcec.ModNScalar)
+	for _, posi := range signerSet(signers) {
+		// R_i = D_i + p_i * E_i
+		D_i := new(btcec.JacobianPoint)
+		public_nonces[posi][0].AsJacobian(D_i)
+		E_i := new(btcec.JacobianPoint)
+		public_nonces[posi][1].AsJacobian(E_i)
+		R_i := new(btcec.JacobianPoint)
+		wsts.suite.scalarMult(bindingFactor(posi, msg, honest, public_nonces), E_i, R_i)
+		wsts.suite.addPoints(D_i, R_i, R_i)
+		if round.R.Y.IsOdd() {
+			R_i = negatePoint(R_i)
+		}
+
+		// c * \sum_{K_i} \lambda_k * Y_k
+		scalars := make([]*btcec.ModNScalar, 0, len(wsts.Keys[posi]))
+		points := make([]*btcec.JacobianPoint, 0, len(wsts.Keys[posi]))
+		for key := range wsts.Keys[posi] {
+			value, ok := wsts.Frost.PublicSigningShares.Load(key)
+			if !ok {
+				return nil, fmt.Errorf("signer %d, key %d: %w", posi, key, ErrMissingWeightedPublicKey)
+			}
+			Y_k := new(btcec.JacobianPoint)
+			value.(*btcec.PublicKey).AsJacobian(Y_k)
+			if odd_group_key {
+				Y_k = negatePoint(Y_k)
+			}
+			scalars = append(scalars, new(btcec.ModNScalar).Mul2(lambdas[key], c))
+			points = append(points, Y_k)
+		}
+		expected := new(btcec.JacobianPoint)
+		if err := wsts.suite.multiScalarMul(scalars, points, expected); err != nil {
+			return nil, fmt.Errorf("signer %d: %w", posi, err)
+		}
+		wsts.suite.addPoints(R_i, expected, expected)
+
+		// g^z_i
+		actual := new(btcec.JacobianPoint)
+		wsts.suite.scalarBaseMult(shares[posi], actual)
+		if !equalPoints(actual, expected) {
+			return nil, fmt.Errorf("signer %d: %w", posi, ErrInvalidWeightedShare)
+		}
+		z.Add(shares[posi])
+	}
+
+	sig := schnorr.NewSignature(&round.R.X, z)
+	if !sig.Verify(msg[:], wsts.Frost.GroupPublicKey) {
+		return nil, ErrInvalidAggregatedSignature
+	}
+
+	return sig, nil
+}
+
+// nonce commitments as public nonces, with the sorted positions of their signers
+func weightedPublicNonces(nonces map[int64]*NonceCommitment) (map[int64][2]*btcec.PublicKey, []int64, error) {
+	positions := make(map[int64]bool, len(nonces))
+	public_nonces := make(map[int64][2]*btcec.PublicKey, len(nonces))
+	for posi, nonce := range nonces {
+		if nonce == nil || nonce.D == nil || nonce.E == nil {
+			return nil, nil, fmt.Errorf("signer %d: %w", posi, ErrMissingNonceCommitment)
+		}
+		positions[posi] = true
+		public_nonces[posi] = [2]*btcec.PublicKey{nonce.D, nonce.E}
+	}
+
+	return public_nonces, signerSet(positions), nil
+}
+
+func positionSet(positions []int64) map[int64]bool {
+	set := make(map[int64]bool, len(positions))
+	for _, posi := range positions {
+		set[posi] = true
+	}
+
+	return set
+}
+
+// both sets are sorted, as returned by signerSet
+func equalSignerSets(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
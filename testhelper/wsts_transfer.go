@@ -0,0 +1,101 @@
+package testhelper
+
+import (
+	"errors"
+	"fmt"
+
+	btcec "github.com/btcsuite/btcd/btcec/v2"
+)
+
+var (
+	ErrKeyNotOwned              = errors.New("transfer keys: key is not owned by the sender")
+	ErrInvalidTransferRecipient = errors.New("transfer keys: invalid recipient")
+	ErrInvalidTransferredShare  = errors.New("accept keys: signing share does not match the public signing share")
+)
+
+// TransferShares moves keys of the range of participant From to participant To
+// Shares holds s_k of every transferred key k, sent privately to the new owner
+type TransferShares struct {
+	From   int64
+	To     int64
+	Keys   []int64
+	Shares map[int64]*btcec.ModNScalar
+}
+
+// reassign keys of the sender to another participant, the signing shares s_k move unchanged
+// thus the group public key and the public signing shares Y_k stay the same, no DKG is needed
+//
+// the sender forgets s_k, a sender keeping a copy can still sign for k until the shares are refreshed,
+// as for a revoked participant
+func (wsts *WstsParticipant) TransferKeys(from, to int64, keys []int64) (TransferShares, error) {
+	if from != wsts.Frost.Position {
+		return TransferShares{}, fmt.Errorf("%w: participant %d transfers keys of %d", ErrKeyNotOwned, wsts.Frost.Position, from)
+	}
+	if to < 1 || to > wsts.N_p || to == from {
+		return TransferShares{}, fmt.Errorf("%w: %d", ErrInvalidTransferRecipient, to)
+	}
+	transfer := TransferShares{
+		From:   from,
+		To:     to,
+		Keys:   keys,
+		Shares: make(map[int64]*btcec.ModNScalar, len(keys)),
+	}
+	for _, key := range keys {
+		value, ok := wsts.signing_shares.Load(key)
+		if !wsts.Keys[from][key] || !ok {
+			return TransferShares{}, fmt.Errorf("%w: %d", ErrKeyNotOwned, key)
+		}
+		transfer.Shares[key] = value.(*btcec.ModNScalar)
+	}
+
+	for _, key := range keys {
+		wsts.signing_shares.Delete(key)
+		wsts.secret_shares.Delete(key)
+	}
+	wsts.ApplyKeyTransfer(from, to, keys)
+
+	return transfer, nil
+}
+
+// the new owner checks s_k * G = Y_k for every transferred key before taking ownership
+func (wsts *WstsParticipant) AcceptKeys(transfer TransferShares) error {
+	if transfer.To != wsts.Frost.Position {
+		return fmt.Errorf("%w: %d", ErrInvalidTransferRecipient, transfer.To)
+	}
+	for _, key := range transfer.Keys {
+		share, ok := transfer.Shares[key]
+		if !ok || share == nil {
+			return fmt.Errorf("%w: key %d", ErrInvalidTransferredShare, key)
+		}
+		value, ok := wsts.Frost.PublicSigningShares.Load(key)
+		if !ok {
+			return fmt.Errorf("key %d: %w", key, ErrMissingPublicSigningShare)
+		}
+		Y_k := new(btcec.JacobianPoint)
+		value.(*btcec.PublicKey).AsJacobian(Y_k)
+		actual := new(btcec.JacobianPoint)
+		wsts.suite.scalarBaseMult(share, actual)
+		if !equalPoints(actual, Y_k) {
+			return fmt.Errorf("%w: key %d", ErrInvalidTransferredShare, key)
+		}
+	}
+
+	for _, key := range transfer.Keys {
+		wsts.StoreSigningShares(key, transfer.Shares[key])
+	}
+	wsts.ApplyKeyTransfer(transfer.From, transfer.To, transfer.Keys)
+
+	return nil
+}
+
+// move keys from the range of participant from to the range of participant to
+// every participant applies the transfer, the Lagrange coefficients of later signing sessions follow the new ranges
+func (wsts *WstsParticipant) ApplyKeyTransfer(from, to int64, keys []int64) {
+	if wsts.Keys[to] == nil {
+		wsts.Keys[to] = make(map[int64]bool)
+	}
+	for _, key := range keys {
+		delete(wsts.Keys[from], key)
+		wsts.Keys[to][key] = true
+	}
+}
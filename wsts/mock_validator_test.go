@@ -123,9 +123,9 @@ func (v *MockValidator) ReceiveMessageOnChainLoop() {
 				// assert secret proofs
 				secretProofs, err := schnorr.ParseSignature(msg.SecretProofs)
 				assert.NoError(v.suite.T, err)
-				secretCommitments, err := btcec.ParsePubKey(msg.PolynomialCommitments[0])
+				secretCommitments, err := v.suite.ParsePoint(msg.PolynomialCommitments[0])
 				assert.NoError(v.suite.T, err)
-				v.frost.VerifySecretProofs(CONTEXT_HASH, secretProofs, msg.Source, secretCommitments)
+				assert.NoError(v.suite.T, v.frost.VerifySecretProofs(CONTEXT_HASH, secretProofs, msg.Source, secretCommitments))
 				// store polynomial commitments
 				v.storePolyCommitments(msg.Source, msg.PolynomialCommitments)
 			case MSG_UPDATE_NONCE_COMMITMENTS:
@@ -253,7 +253,7 @@ func (v *MockValidator) SendVPToAll() {
 		Source: v.position,
 		Vp:     vp_bytes,
 	}
-	msgBytes, err := proto.Marshal(&msg)
+	msgBytes, err := v.suite.MarshalMessage(&msg)
 	assert.NoError(v.suite.T, err)
 
 	for _, otherVal := range v.otherVals {
@@ -279,7 +279,7 @@ func (v *MockValidator) DeriveAndSendProofs() {
 		SecretProofs:          secret.Serialize(),
 		PolynomialCommitments: polynomialCommitmentsBytes,
 	}
-	msgBytes, err := proto.Marshal(&msg)
+	msgBytes, err := v.suite.MarshalMessage(&msg)
 	assert.NoError(v.suite.T, err)
 
 	for _, otherVal := range v.otherVals {
@@ -335,7 +335,7 @@ func (v *MockValidator) DeriveAndSendSecretShares() {
 			Source:       v.position,
 			SecretShares: secretShares,
 		}
-		secretShareMsgBytes, err := proto.Marshal(&secretShareMsg)
+		secretShareMsgBytes, err := v.suite.MarshalMessage(&secretShareMsg)
 		assert.NoError(v.suite.T, err)
 
 		v.otherVals[i].SendMessageOffChain(append([]byte{MSG_SECRET_SHARES}, secretShareMsgBytes...))
@@ -347,7 +347,7 @@ func (v *MockValidator) DeriveAndSendSecretShares() {
 		Source:       v.position,
 		SecretShares: make([]*SecretShares, 0),
 	}
-	secretShareMsgBytes, err := proto.Marshal(&secretShareMsg)
+	secretShareMsgBytes, err := v.suite.MarshalMessage(&secretShareMsg)
 	assert.NoError(v.suite.T, err)
 	v.SendMessageOffChain(append([]byte{MSG_SECRET_SHARES}, secretShareMsgBytes...))
 }
@@ -380,7 +380,7 @@ func (v *MockValidator) DeriveAndSendNonces() {
 			NonceCommitments: nonceCommitmentsArr,
 		}
 
-		msgBytes, err := proto.Marshal(&msg)
+		msgBytes, err := v.suite.MarshalMessage(&msg)
 		assert.NoError(v.suite.T, err)
 
 		otherVal.SendMessageOnChain(append([]byte{MSG_UPDATE_NONCE_COMMITMENTS}, msgBytes...))
@@ -417,7 +417,10 @@ func (v *MockValidator) DeriveTxAndSign() error {
 		public_nonces[i] = nonceCommitments
 	}
 
-	public_nonce_commitments := v.frost.CalculatePublicNonceCommitments(signing_index, honest, sigHash, public_nonces)
+	public_nonce_commitments, err := v.frost.CalculatePublicNonceCommitments(signing_index, honest, sigHash, public_nonces)
+	if err != nil {
+		return err
+	}
 
 	// derive signature adaptors
 	key_range := v.protocolStorage.GetKeyRange(strconv.FormatInt(v.position, 10))
@@ -445,7 +448,7 @@ func (v *MockValidator) DeriveTxAndSign() error {
 			AdaptSig: adapt_sig.Serialize(),
 		}
 
-		msgBytes, err := proto.Marshal(&msg)
+		msgBytes, err := v.suite.MarshalMessage(&msg)
 		assert.NoError(v.suite.T, err)
 
 		otherVal.SendMessageOnChain(append([]byte{MSG_UPDATE_ADAPT_SIG}, msgBytes...))
@@ -542,7 +545,8 @@ func (v *MockValidator) verifySharesAndCalculateLongTermKey() {
 				all_secret_shares[j] = v.localStorage.GetSecretShares(j, i)
 			}
 
-			v.frost.VerifyBatchPublicSecretShares(all_secret_shares, uint32(i))
+			_, err := v.frost.VerifyBatchPublicSecretShares(all_secret_shares, uint32(i))
+			assert.NoError(v.suite.T, err)
 
 			longTermShares := new(btcec.ModNScalar)
 			longTermShares.SetInt(0)
@@ -732,7 +736,7 @@ func (v *MockValidator) storePolyCommitments(posi int64, commitments [][]byte) {
 	var err error
 	poly_commitments := make([]*btcec.PublicKey, v.frost.Threshold+1)
 	for i := int64(0); i <= v.frost.Threshold; i++ {
-		poly_commitments[i], err = btcec.ParsePubKey(commitments[i])
+		poly_commitments[i], err = v.suite.ParsePoint(commitments[i])
 		assert.NoError(v.suite.T, err)
 	}
 	v.frost.UpdatePolynomialCommitments(posi, poly_commitments)
@@ -798,13 +802,13 @@ func (v *MockValidator) getNonceCommitments(posi, signing_index int64) ([2]*btce
 		return [2]*btcec.PublicKey{}, err
 	}
 
-	D, err := btcec.ParsePubKey(commitment.D)
+	D, err := v.suite.ParsePoint(commitment.D)
 	if err != nil {
 		v.logger.Printf("error parsing D for signing index: %d, posi: %d\n", signing_index, posi)
 		return [2]*btcec.PublicKey{}, err
 	}
 
-	E, err := btcec.ParsePubKey(commitment.E)
+	E, err := v.suite.ParsePoint(commitment.E)
 	if err != nil {
 		v.logger.Printf("error parsing E for signing index: %d, posi: %d\n", signing_index, posi)
 		return [2]*btcec.PublicKey{}, err
@@ -825,7 +829,7 @@ func (v *MockValidator) getPublicNonceCommitments(signing_index int64) map[int64
 	substore_key := PUBLIC_NONCE_COMMITMENTS_STORE_KEY + strconv.FormatInt(signing_index, 10)
 	commitments := make(map[int64]*btcec.PublicKey)
 	for posi, commitment_bytes := range v.protocolStorage.store[substore_key] {
-		pubkey, err := btcec.ParsePubKey(commitment_bytes)
+		pubkey, err := v.suite.ParsePoint(commitment_bytes)
 		assert.NoError(v.suite.T, err)
 		posi_int, err := strconv.ParseInt(posi, 10, 64)
 		assert.NoError(v.suite.T, err)